@@ -2,25 +2,66 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/database/mongodb"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/database/redisdb"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/fee_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/image_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/auction_controller"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/bid_controller"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/category_controller"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/exchange_rate_controller"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/notification_controller"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/order_controller"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/user_controller"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/wallet_controller"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/controller/watchlist_controller"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/middleware"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/cache"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/auction"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/bid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/category"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/exchange_rate"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/idempotency"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/migration"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/notification"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/offer"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/order"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/user"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/wallet"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/watchlist"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/notifier"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/payment"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/ratelimit"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/storage"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/auction_usecase"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/bid_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/category_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/exchange_rate_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/notification_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/settlement_usecase"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/user_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/wallet_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/watchlist_usecase"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func main() {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	if err := godotenv.Load("cmd/auction/.env"); err != nil {
 		log.Fatal("Error trying to load env variables")
@@ -33,38 +74,202 @@ func main() {
 		return
 	}
 
+	if err := migration.Run(ctx, databaseConnection); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	redisConnection, err := redisdb.NewRedisConnection(ctx)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	idempotencyRepository := idempotency.NewIdempotencyRepository(databaseConnection)
+	if err := idempotencyRepository.EnsureIndexes(ctx); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+	idempotencyMiddleware := middleware.Idempotency(idempotencyRepository, idempotency.GetIdempotencyKeyTTL())
+
+	feeSchedule, feeErr := settlement_usecase.LoadFeeSchedule(settlement_usecase.GetFeeScheduleFile())
+	if feeErr != nil {
+		log.Fatal(feeErr.Error())
+		return
+	}
+
+	blobStorage, storageErr := storage.NewBlobStorageFromEnv()
+	if storageErr != nil {
+		log.Fatal(storageErr.Error())
+		return
+	}
+
+	paymentProvider, paymentErr := payment.NewPaymentProviderFromEnv()
+	if paymentErr != nil {
+		log.Fatal(paymentErr.Error())
+		return
+	}
+
 	router := gin.Default()
+	if localStorage, ok := blobStorage.(*storage.LocalBlobStorage); ok && strings.HasPrefix(localStorage.BaseURL(), "/") {
+		router.Static(localStorage.BaseURL(), localStorage.Dir())
+	}
+
+	userController, bidController, auctionsController, notificationController, watchlistController, walletController, orderController, categoryController, bidUseCase :=
+		initDependencies(ctx, databaseConnection, redisConnection, feeSchedule, blobStorage, paymentProvider)
 
-	userController, bidController, auctionsController := initDependencies(ctx, databaseConnection)
+	exchangeRateUseCase := exchange_rate_usecase.NewExchangeRateUseCase(
+		exchange_rate.NewExchangeRateRepository(databaseConnection))
+	if err := exchangeRateUseCase.LoadRateTableFile(ctx, exchange_rate_usecase.GetExchangeRatesFile()); err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+	exchangeRateController := exchange_rate_controller.NewExchangeRateController(exchangeRateUseCase)
+	adminMiddleware := middleware.AdminToken(middleware.GetAdminToken())
 
 	router.GET("/auction", auctionsController.FindAuctions)
+	router.GET("/auction/search", auctionsController.SearchAuctions)
 	router.GET("/auction/:auctionId", auctionsController.FindAuctionById)
-	router.POST("/auction", auctionsController.CreateAuction)
+	router.POST("/auction", idempotencyMiddleware, auctionsController.CreateAuction)
 	router.GET("/auction/winner/:auctionId", auctionsController.FindWinningBidByAuctionId)
-	router.POST("/bid", bidController.CreateBid)
+	router.POST("/admin/auction/:auctionId/images", adminMiddleware, auctionsController.AddAuctionImages)
+	router.DELETE("/admin/auction/:auctionId/images/:imageId", adminMiddleware, auctionsController.RemoveAuctionImage)
+	router.POST("/bid", idempotencyMiddleware, bidController.CreateBid)
 	router.GET("/bid/:auctionId", bidController.FindBidByAuctionId)
+	router.GET("/metrics/bid-batch", bidController.FindBatchMetrics)
 	router.GET("/user/:userId", userController.FindUserById)
+	router.GET("/user/:userId/notification-preferences", notificationController.FindPreferenceByUserId)
+	router.PUT("/user/:userId/notification-preferences", notificationController.UpdatePreference)
+	router.GET("/user/:userId/watchlist", watchlistController.FindWatchedAuctions)
+	router.POST("/user/:userId/watchlist", watchlistController.AddWatchedAuction)
+	router.DELETE("/user/:userId/watchlist/:auctionId", watchlistController.RemoveWatchedAuction)
+	router.GET("/user/:userId/wallet", walletController.FindWallets)
+	router.POST("/admin/user/:userId/wallet/deposits", adminMiddleware, idempotencyMiddleware, walletController.Deposit)
+	router.PUT("/admin/user/:userId/wallet/credit-limit", adminMiddleware, walletController.UpdateCreditLimit)
+	router.GET("/user/:userId/orders", orderController.FindOrdersByUserId)
+	router.GET("/order/:orderId", orderController.FindOrderById)
+	router.POST("/admin/order/:orderId/payment/confirm", adminMiddleware, orderController.ConfirmPayment)
+	router.POST("/auction/:auctionId/second-chance-offers", orderController.CreateSecondChanceOffer)
+	router.GET("/user/:userId/offers", orderController.FindOffersByUserId)
+	router.POST("/offer/:offerId/accept", orderController.AcceptOffer)
+	router.POST("/offer/:offerId/decline", orderController.DeclineOffer)
+	router.GET("/exchange-rates", exchangeRateController.FindRateTable)
+	router.PUT("/admin/exchange-rates", adminMiddleware, exchangeRateController.UpdateRateTable)
+	router.GET("/categories", categoryController.FindCategoryTree)
+	router.GET("/categories/:categoryId", categoryController.FindCategoryById)
+	router.POST("/admin/categories", adminMiddleware, categoryController.CreateCategory)
+	router.PUT("/admin/categories/:categoryId", adminMiddleware, categoryController.UpdateCategory)
+	router.DELETE("/admin/categories/:categoryId", adminMiddleware, categoryController.DeleteCategory)
+
+	server := &http.Server{
+		Addr:    ":8080",
+		Handler: router,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+
+	// Stops accepting requests first, so no bid is acknowledged after the batch was drained
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), getShutdownTimeout())
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("Error trying to shut the server down:", err.Error())
+	}
+
+	if err := bidUseCase.Close(shutdownCtx); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+func getShutdownTimeout() time.Duration {
+	shutdownTimeout := os.Getenv("SHUTDOWN_TIMEOUT")
+	duration, err := time.ParseDuration(shutdownTimeout)
+	if err != nil || duration <= 0 {
+		return 30 * time.Second
+	}
 
-	router.Run(":8080")
+	return duration
 }
 
-func initDependencies(ctx context.Context, database *mongo.Database) (
+func initDependencies(
+	ctx context.Context,
+	database *mongo.Database,
+	redisClient *redis.Client,
+	feeSchedule *fee_entity.Schedule,
+	blobStorage image_entity.BlobStorageInterface,
+	paymentProvider order_entity.PaymentProviderInterface) (
 	userController *user_controller.UserController,
 	bidController *bid_controller.BidController,
-	auctionController *auction_controller.AuctionController) {
+	auctionController *auction_controller.AuctionController,
+	notificationController *notification_controller.NotificationController,
+	watchlistController *watchlist_controller.WatchlistController,
+	walletController *wallet_controller.WalletController,
+	orderController *order_controller.OrderController,
+	categoryController *category_controller.CategoryController,
+	bidUseCase bid_usecase.BidUseCaseInterface) {
 
 	auctionRepository := auction.NewAuctionRepository(database)
+	auctionRepository.StateCache = cache.NewAuctionStateCache(redisClient)
 	bidRepository := bid.NewBidRepository(database, auctionRepository)
 	userRepository := user.NewUserRepository(database)
+	preferenceRepository := notification.NewPreferenceRepository(database)
+	watchlistRepository := watchlist.NewWatchlistRepository(database)
+	exchangeRateRepository := exchange_rate.NewExchangeRateRepository(database)
+	walletRepository := wallet.NewWalletRepository(database)
+	auctionRepository.WalletRepository = walletRepository
+	bidRepository.WalletRepository = walletRepository
+	orderRepository := order.NewOrderRepository(database)
+	categoryRepository := category.NewCategoryRepository(database)
+
+	settlementUseCase := settlement_usecase.NewSettlementUseCase(
+		orderRepository, auctionRepository, bidRepository, walletRepository,
+		offer.NewOfferRepository(database), paymentProvider, categoryRepository, feeSchedule)
+	auctionRepository.Settler = settlementUseCase
+
+	notifiers := map[notification_entity.Channel]notification_entity.NotifierInterface{
+		notification_entity.LogChannel: notifier.NewLogNotifier(),
+	}
+	if emailNotifier := notifier.NewEmailNotifierFromEnv(); emailNotifier != nil {
+		notifiers[notification_entity.EmailChannel] = emailNotifier
+	}
+
+	notificationUseCase := notification_usecase.NewNotificationUseCase(
+		preferenceRepository, bidRepository, watchlistRepository, notifiers)
+	auctionRepository.Notifier = notificationUseCase
+	bidRepository.Notifier = notificationUseCase
 
 	// Starts the auction closing worker
 	go auctionRepository.StartAuctionClosingWorker(ctx)
+	// Starts the "ending soon" reminder worker
+	go auctionRepository.StartAuctionReminderWorker(ctx)
+	// Starts the worker that defaults unpaid orders
+	go settlementUseCase.StartPaymentDeadlineWorker(ctx)
 
 	userController = user_controller.NewUserController(
 		user_usecase.NewUserUseCase(userRepository))
 	auctionController = auction_controller.NewAuctionController(
-		auction_usecase.NewAuctionUseCase(auctionRepository, bidRepository))
-	bidController = bid_controller.NewBidController(bid_usecase.NewBidUseCase(bidRepository))
+		auction_usecase.NewAuctionUseCase(
+			auctionRepository, bidRepository, watchlistRepository, exchangeRateRepository, orderRepository,
+			categoryRepository, blobStorage))
+	bidUseCase = bid_usecase.NewBidUseCase(
+		bidRepository, bid.NewPendingBidRepository(database), auctionRepository,
+		userRepository, exchangeRateRepository, walletRepository, ratelimit.NewRateLimiter(redisClient))
+	bidController = bid_controller.NewBidController(bidUseCase)
+	notificationController = notification_controller.NewNotificationController(notificationUseCase)
+	watchlistController = watchlist_controller.NewWatchlistController(
+		watchlist_usecase.NewWatchlistUseCase(watchlistRepository, auctionRepository))
+	walletController = wallet_controller.NewWalletController(
+		wallet_usecase.NewWalletUseCase(walletRepository, userRepository))
+	orderController = order_controller.NewOrderController(settlementUseCase)
+	categoryController = category_controller.NewCategoryController(
+		category_usecase.NewCategoryUseCase(categoryRepository, auctionRepository))
 
 	return
 }
@@ -0,0 +1,36 @@
+package redisdb
+
+import (
+	"context"
+	"os"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	REDIS_URL = "REDIS_URL"
+)
+
+// NewRedisConnection connects to the Redis server configured in REDIS_URL.
+// It returns a nil client when Redis is not configured
+func NewRedisConnection(ctx context.Context) (*redis.Client, error) {
+	redisURL := os.Getenv(REDIS_URL)
+	if redisURL == "" {
+		return nil, nil
+	}
+
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		logger.Error("Error trying to parse redis url", err)
+		return nil, err
+	}
+
+	client := redis.NewClient(options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		logger.Error("Error trying to ping redis", err)
+		return nil, err
+	}
+
+	return client, nil
+}
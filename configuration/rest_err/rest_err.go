@@ -2,6 +2,7 @@ package rest_err
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 )
@@ -11,6 +12,8 @@ type RestErr struct {
 	Err     string   `json:"err"`
 	Code    int      `json:"code"`
 	Causes  []Causes `json:"causes"`
+	// RetryAfter is sent in the Retry-After header instead of the body
+	RetryAfter time.Duration `json:"-"`
 }
 
 type Causes struct {
@@ -28,6 +31,12 @@ func ConvertError(internalError *internal_error.InternalError) *RestErr {
 		return NewBadRequestError(internalError.Error())
 	case "not_found":
 		return NewNotFoundError(internalError.Error())
+	case "service_unavailable":
+		return NewServiceUnavailableError(internalError.Error())
+	case "too_many_requests":
+		restErr := NewTooManyRequestsError(internalError.Error())
+		restErr.RetryAfter = internalError.RetryAfter
+		return restErr
 	default:
 		return NewInternalServerError(internalError.Error())
 	}
@@ -51,6 +60,51 @@ func NewInternalServerError(message string) *RestErr {
 	}
 }
 
+func NewServiceUnavailableError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "service_unavailable",
+		Code:    http.StatusServiceUnavailable,
+		Causes:  nil,
+	}
+}
+
+func NewTooManyRequestsError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "too_many_requests",
+		Code:    http.StatusTooManyRequests,
+		Causes:  nil,
+	}
+}
+
+func NewConflictError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "conflict",
+		Code:    http.StatusConflict,
+		Causes:  nil,
+	}
+}
+
+func NewUnprocessableEntityError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "unprocessable_entity",
+		Code:    http.StatusUnprocessableEntity,
+		Causes:  nil,
+	}
+}
+
+func NewUnauthorizedError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "unauthorized",
+		Code:    http.StatusUnauthorized,
+		Causes:  nil,
+	}
+}
+
 func NewNotFoundError(message string) *RestErr {
 	return &RestErr{
 		Message: message,
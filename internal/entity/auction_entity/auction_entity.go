@@ -3,9 +3,14 @@ package auction_entity
 import (
 	"context"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/category_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/image_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 )
 
@@ -26,34 +31,69 @@ func calculateEndTime() time.Time {
 	return time.Now().Add(duration)
 }
 
+// CreateAuction opens an auction in the given native currency, DEFAULT_CURRENCY when empty.
+// Every bid on the auction must be placed in that currency. The seller is optional. category is
+// the one found in the category tree, with its inherited attributes, nil when it does not exist
 func CreateAuction(
-	productName, category, description string,
-	condition ProductCondition) (*Auction, *internal_error.InternalError) {
+	productName string,
+	category *category_entity.Category,
+	description string,
+	condition ProductCondition,
+	currency, sellerId string,
+	attributes map[string]string) (*Auction, *internal_error.InternalError) {
+	if currency == "" {
+		currency = money_entity.DefaultCurrency()
+	}
+
 	auction := &Auction{
 		Id:          uuid.New().String(),
 		ProductName: productName,
-		Category:    category,
 		Description: description,
 		Condition:   condition,
+		Currency:    currency,
+		SellerId:    sellerId,
+		Attributes:  setAttributes(attributes),
 		Status:      Active,
 		Timestamp:   time.Now(),
 		EndTime:     calculateEndTime(),
 	}
 
-	if err := auction.Validate(); err != nil {
+	if category != nil {
+		auction.Category = category.Id
+	}
+
+	if err := auction.Validate(category); err != nil {
 		return nil, err
 	}
 
 	return auction, nil
 }
 
-func (au *Auction) Validate() *internal_error.InternalError {
+// setAttributes trims the attribute values and leaves out the empty ones
+func setAttributes(attributes map[string]string) map[string]string {
+	values := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		if value = strings.TrimSpace(value); value != "" {
+			values[name] = value
+		}
+	}
+
+	return values
+}
+
+// Validate checks the auction against its category, which must exist and whose attribute
+// schema the item attributes must follow
+func (au *Auction) Validate(category *category_entity.Category) *internal_error.InternalError {
 	if len(au.ProductName) <= 1 {
 		return internal_error.NewBadRequestError("product name must be longer than 1 character")
 	}
 
-	if len(au.Category) <= 2 {
-		return internal_error.NewBadRequestError("category must be longer than 2 characters")
+	if category == nil || category.Id != au.Category {
+		return internal_error.NewBadRequestError("category does not exist")
+	}
+
+	if err := category.ValidateAttributes(au.Attributes); err != nil {
+		return err
 	}
 
 	if len(au.Description) <= 10 {
@@ -64,6 +104,16 @@ func (au *Auction) Validate() *internal_error.InternalError {
 		return internal_error.NewBadRequestError("invalid product condition")
 	}
 
+	if _, ok := money_entity.Exponent(au.Currency); !ok {
+		return internal_error.NewBadRequestError("currency is not supported")
+	}
+
+	if au.SellerId != "" {
+		if err := uuid.Validate(au.SellerId); err != nil {
+			return internal_error.NewBadRequestError("seller id is not a valid id")
+		}
+	}
+
 	return nil
 }
 
@@ -73,9 +123,63 @@ type Auction struct {
 	Category    string
 	Description string
 	Condition   ProductCondition
+	Currency    string
+	SellerId    string
 	Status      AuctionStatus
 	Timestamp   time.Time
 	EndTime     time.Time
+
+	// Item attributes by name, e.g. brand or size, as defined by the category
+	Attributes map[string]string
+
+	// Pictures of the item, in the order they were uploaded
+	Images []image_entity.Image
+
+	// Lowest price the seller accepts to sell at. Zero when the auction has no reserve
+	ReservePrice money_entity.Money
+
+	// Current high bid and bid summary, kept up to date as bids are accepted
+	HighBidId     string
+	HighBidUserId string
+	HighBidAmount money_entity.Money
+	BidCount      int64
+	BidderCount   int64
+
+	// Result stored when the auction is closed
+	WinningBidId string
+	WinnerUserId string
+	FinalPrice   money_entity.Money
+	ClosedAt     time.Time
+}
+
+// SetReservePrice parses the reserve price in the auction currency
+func (au *Auction) SetReservePrice(value string) *internal_error.InternalError {
+	reservePrice, err := money_entity.Parse(value, au.Currency)
+	if err != nil {
+		return err
+	}
+
+	if !reservePrice.IsPositive() {
+		return internal_error.NewBadRequestError("reserve price must be positive")
+	}
+
+	au.ReservePrice = reservePrice
+	return nil
+}
+
+// ReserveMet reports whether the high bid reached the reserve price. Auctions without reserve
+// always meet it
+func (au *Auction) ReserveMet() bool {
+	return au.ReservePrice.IsZero() || au.HighBidAmount.Amount >= au.ReservePrice.Amount
+}
+
+// TimeRemaining returns how long the auction still accepts bids at now, zero once it ended
+func (au *Auction) TimeRemaining(now time.Time) time.Duration {
+	if au.Status != Active || !au.EndTime.After(now) {
+		return 0
+	}
+
+	return au.EndTime.Sub(now)
 }
 
 type ProductCondition int
@@ -92,16 +196,149 @@ const (
 	Refurbished
 )
 
+// AuctionSort is the order auction listings are returned in
+type AuctionSort string
+
+const (
+	SortEndingSoonest AuctionSort = "ending_soonest"
+	SortNewest        AuctionSort = "newest"
+	SortHighestPrice  AuctionSort = "highest_price"
+	SortMostBids      AuctionSort = "most_bids"
+)
+
+// ValidateCurrency rejects price sorts without a currency, since the stored amounts of auctions
+// in different currencies can't be compared
+func (s AuctionSort) ValidateCurrency(currency string) *internal_error.InternalError {
+	if s == SortHighestPrice && currency == "" {
+		return internal_error.NewBadRequestError("currency is required to sort by price")
+	}
+
+	return nil
+}
+
+// AuctionSearch filters an auction search. Zero values leave the filter out. Currency is
+// required with a price range or a price sort, since amounts in different currencies can't be compared,
+// and the range bounds are in that currency. Categories holds a category and its descendants
+type AuctionSearch struct {
+	Text         string
+	Status       *AuctionStatus
+	Categories   []string
+	Condition    ProductCondition
+	SellerId     string
+	Currency     string
+	MinPrice     money_entity.Money
+	MaxPrice     money_entity.Money
+	EndingAfter  time.Time
+	EndingBefore time.Time
+	NoBids       bool
+}
+
+func (as *AuctionSearch) Validate() *internal_error.InternalError {
+	if as.MinPrice.Amount < 0 || as.MaxPrice.Amount < 0 {
+		return internal_error.NewBadRequestError("prices must not be negative")
+	}
+
+	if (!as.MinPrice.IsZero() || !as.MaxPrice.IsZero()) && as.Currency == "" {
+		return internal_error.NewBadRequestError("currency is required to filter by price")
+	}
+
+	if !as.MinPrice.IsZero() && !as.MaxPrice.IsZero() && as.MinPrice.Compare(as.MaxPrice) > 0 {
+		return internal_error.NewBadRequestError("min price must not be greater than max price")
+	}
+
+	if !as.EndingAfter.IsZero() && !as.EndingBefore.IsZero() && as.EndingAfter.After(as.EndingBefore) {
+		return internal_error.NewBadRequestError("ending after must not be later than ending before")
+	}
+
+	return nil
+}
+
+type CategoryFacet struct {
+	Category string
+	Count    int64
+}
+
+type ConditionFacet struct {
+	Condition ProductCondition
+	Count     int64
+}
+
+// AuctionFacets counts the auctions matching a search per category and condition, the most
+// common first
+type AuctionFacets struct {
+	Categories []CategoryFacet
+	Conditions []ConditionFacet
+}
+
+// AuctionState is the part of an auction needed to validate incoming bids
+type AuctionState struct {
+	Status   AuctionStatus
+	EndTime  time.Time
+	Currency string
+}
+
+// AuctionStateCacheInterface caches auction states between bid batches. Entries are
+// invalidated whenever the auction lifecycle changes them, e.g. on closing or extension
+type AuctionStateCacheInterface interface {
+	Get(ctx context.Context, auctionId string) (*AuctionState, bool)
+
+	Set(ctx context.Context, auctionId string, state AuctionState)
+
+	Invalidate(ctx context.Context, auctionId string)
+}
+
 type AuctionRepositoryInterface interface {
 	CreateAuction(
 		ctx context.Context,
 		auctionEntity *Auction) *internal_error.InternalError
 
+	// DeleteAuction removes an auction that was just created and can't be kept
+	DeleteAuction(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	// FindAuctions returns a page of the auctions and the cursor of the next page, empty on the
+	// last one. categories holds a category and its descendants
 	FindAuctions(
 		ctx context.Context,
 		status AuctionStatus,
-		category, productName string) ([]Auction, *internal_error.InternalError)
+		categories []string,
+		productName, currency string,
+		sort AuctionSort,
+		page pagination_entity.PageRequest) ([]Auction, string, *internal_error.InternalError)
+
+	// SearchAuctions returns a page of the auctions matching the search and the cursor of the
+	// next page, empty on the last one
+	SearchAuctions(
+		ctx context.Context,
+		search AuctionSearch,
+		sort AuctionSort,
+		page pagination_entity.PageRequest) ([]Auction, string, *internal_error.InternalError)
+
+	FindAuctionFacets(
+		ctx context.Context, search AuctionSearch) (*AuctionFacets, *internal_error.InternalError)
 
 	FindAuctionById(
 		ctx context.Context, id string) (*Auction, *internal_error.InternalError)
+
+	FindAuctionState(
+		ctx context.Context, id string) (*AuctionState, *internal_error.InternalError)
+
+	CountAuctionsByCategory(
+		ctx context.Context, category string) (int64, *internal_error.InternalError)
+
+	// AddAuctionImages attaches all the images to the auction, or none of them when they would
+	// go over the maximum number of images
+	AddAuctionImages(
+		ctx context.Context, auctionId string, images []image_entity.Image) *internal_error.InternalError
+
+	RemoveAuctionImage(
+		ctx context.Context, auctionId, imageId string) *internal_error.InternalError
+
+	// FindUnsettledAuctions returns the auctions closed before the given time whose settlement
+	// didn't finish, so it can be retried
+	FindUnsettledAuctions(
+		ctx context.Context, closedBefore time.Time) ([]Auction, *internal_error.InternalError)
+
+	MarkAuctionSettled(
+		ctx context.Context, id string) *internal_error.InternalError
 }
@@ -0,0 +1,58 @@
+package auction_entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+)
+
+// TestTimeRemaining tests that only active auctions before their end time have time remaining
+func TestTimeRemaining(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		status   AuctionStatus
+		endTime  time.Time
+		expected time.Duration
+	}{
+		{status: Active, endTime: now.Add(time.Minute), expected: time.Minute},
+		{status: Active, endTime: now.Add(-time.Minute), expected: 0},
+		{status: Active, endTime: now, expected: 0},
+		{status: Completed, endTime: now.Add(time.Minute), expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		auction := Auction{Status: testCase.status, EndTime: testCase.endTime}
+		if remaining := auction.TimeRemaining(now); remaining != testCase.expected {
+			t.Errorf("Esperado %v restante para status %d, recebido %v", testCase.expected, testCase.status, remaining)
+		}
+	}
+}
+
+// TestSortValidateCurrency tests that only price sorts require a currency
+func TestSortValidateCurrency(t *testing.T) {
+	if err := SortHighestPrice.ValidateCurrency(""); err == nil {
+		t.Error("Ordenação por preço sem moeda deveria ser rejeitada")
+	}
+	if err := SortHighestPrice.ValidateCurrency("BRL"); err != nil {
+		t.Errorf("Ordenação por preço com moeda deveria ser aceita: %v", err)
+	}
+	if err := SortNewest.ValidateCurrency(""); err != nil {
+		t.Errorf("Ordenação sem preço não deveria exigir moeda: %v", err)
+	}
+}
+
+// TestSearchValidateCurrency tests that price ranges are rejected without the currency of the
+// prices
+func TestSearchValidateCurrency(t *testing.T) {
+	search := AuctionSearch{MaxPrice: money_entity.New(10000, "USD")}
+	if err := search.Validate(); err == nil || err.Err != "bad_request" {
+		t.Errorf("Faixa de preço sem moeda deveria ser rejeitada, recebido %v", err)
+	}
+
+	search.Currency = "USD"
+	if err := search.Validate(); err != nil {
+		t.Errorf("Faixa de preço com moeda deveria ser aceita: %v", err)
+	}
+}
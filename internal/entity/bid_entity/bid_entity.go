@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 )
 
@@ -12,11 +14,11 @@ type Bid struct {
 	Id        string
 	UserId    string
 	AuctionId string
-	Amount    float64
+	Amount    money_entity.Money
 	Timestamp time.Time
 }
 
-func CreateBid(userId, auctionId string, amount float64) (*Bid, *internal_error.InternalError) {
+func CreateBid(userId, auctionId string, amount money_entity.Money) (*Bid, *internal_error.InternalError) {
 	bid := &Bid{
 		Id:        uuid.New().String(),
 		UserId:    userId,
@@ -37,21 +39,91 @@ func (b *Bid) Validate() *internal_error.InternalError {
 		return internal_error.NewBadRequestError("UserId is not a valid id")
 	} else if err := uuid.Validate(b.AuctionId); err != nil {
 		return internal_error.NewBadRequestError("AuctionId is not a valid id")
-	} else if b.Amount <= 0 {
+	} else if !b.Amount.IsPositive() {
 		return internal_error.NewBadRequestError("Amount is not a valid value")
+	} else if err := b.Amount.Validate(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// Outranks reports whether the bid beats the other one for the lead of an auction: a higher
+// amount wins and, on a tie, the earlier bid (the bid id breaks exact timestamp ties)
+func (b *Bid) Outranks(other Bid) bool {
+	if comparison := b.Amount.Compare(other.Amount); comparison != 0 {
+		return comparison > 0
+	}
+	if b.Timestamp.UnixMilli() != other.Timestamp.UnixMilli() {
+		return b.Timestamp.UnixMilli() < other.Timestamp.UnixMilli()
+	}
+
+	return b.Id < other.Id
+}
+
+// BidSort is the order bid listings are returned in
+type BidSort string
+
+const (
+	SortNewest        BidSort = "newest"
+	SortHighestAmount BidSort = "highest_amount"
+)
+
+type BidOutcome string
+
+const (
+	// BidInserted is a bid stored by this attempt
+	BidInserted BidOutcome = "inserted"
+	// BidDuplicated is a bid that was already stored by a previous attempt
+	BidDuplicated BidOutcome = "duplicated"
+	// BidRejected is a bid the auction can't accept, e.g. placed after its end time, in another
+	// currency or above the available credit of its user
+	BidRejected BidOutcome = "rejected"
+	// BidFailed is a bid that could not be stored and should be retried
+	BidFailed BidOutcome = "failed"
+)
+
+// BidResult is the outcome of persisting a single bid of a batch
+type BidResult struct {
+	BidId   string
+	Outcome BidOutcome
+	Err     *internal_error.InternalError
+}
+
 type BidEntityRepository interface {
 	CreateBid(
 		ctx context.Context,
-		bidEntities []Bid) *internal_error.InternalError
+		bidEntities []Bid) ([]BidResult, *internal_error.InternalError)
 
+	// FindBidByAuctionId returns a page of the bids of the auction and the cursor of the next
+	// page, empty on the last one
 	FindBidByAuctionId(
+		ctx context.Context,
+		auctionId string,
+		sort BidSort,
+		page pagination_entity.PageRequest) ([]Bid, string, *internal_error.InternalError)
+
+	// FindRankedBidsByAuctionId returns the best bid of each bidder, best ranked first
+	FindRankedBidsByAuctionId(
 		ctx context.Context, auctionId string) ([]Bid, *internal_error.InternalError)
 
 	FindWinningBidByAuctionId(
 		ctx context.Context, auctionId string) (*Bid, *internal_error.InternalError)
 }
+
+// PendingBidRepositoryInterface is the durable log every bid is written to before it is
+// acknowledged. Entries are removed once the bid was persisted by the batch processor, or moved
+// to a dead letter status, which is no longer replayed, once it gave up on them
+type PendingBidRepositoryInterface interface {
+	AppendPendingBid(
+		ctx context.Context, bidEntity Bid) *internal_error.InternalError
+
+	FindUnprocessedPendingBids(
+		ctx context.Context) ([]Bid, *internal_error.InternalError)
+
+	RemovePendingBids(
+		ctx context.Context, bidIds []string) *internal_error.InternalError
+
+	DeadLetterPendingBids(
+		ctx context.Context, bidIds []string) *internal_error.InternalError
+}
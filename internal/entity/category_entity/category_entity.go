@@ -0,0 +1,237 @@
+package category_entity
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// categoryIdPattern keeps category ids readable in URLs and queries, e.g. "mobile-phones"
+var categoryIdPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// maxAttributeValueLength bounds the values auctions supply for their attributes
+const maxAttributeValueLength = 200
+
+// AttributeType is the kind of value an attribute holds. Values are always sent as strings
+type AttributeType string
+
+const (
+	TextAttribute    AttributeType = "text"
+	NumberAttribute  AttributeType = "number"
+	BooleanAttribute AttributeType = "boolean"
+	// EnumAttribute only accepts one of the values listed by the attribute
+	EnumAttribute AttributeType = "enum"
+)
+
+// Attribute is an item attribute auctions in the category can supply, e.g. brand or size
+type Attribute struct {
+	Name     string
+	Type     AttributeType
+	Values   []string
+	Required bool
+}
+
+func (a *Attribute) validateValue(value string) *internal_error.InternalError {
+	if len(value) > maxAttributeValueLength {
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("attribute %s must have up to %d characters", a.Name, maxAttributeValueLength))
+	}
+
+	switch a.Type {
+	case NumberAttribute:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return internal_error.NewBadRequestError(fmt.Sprintf("attribute %s must be a number", a.Name))
+		}
+	case BooleanAttribute:
+		if value != "true" && value != "false" {
+			return internal_error.NewBadRequestError(fmt.Sprintf("attribute %s must be true or false", a.Name))
+		}
+	case EnumAttribute:
+		if !slices.Contains(a.Values, value) {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("attribute %s must be one of %s", a.Name, strings.Join(a.Values, ", ")))
+		}
+	}
+
+	return nil
+}
+
+// Category is a node of the category tree. Ancestors holds the ids from the root down to the
+// parent, so the descendants of a category are the ones with it among their ancestors
+type Category struct {
+	Id         string
+	Name       string
+	ParentId   string
+	Ancestors  []string
+	Attributes []Attribute
+	Timestamp  time.Time
+}
+
+// CreateCategory creates a category under parent, a root category when parent is nil
+func CreateCategory(
+	id, name string,
+	parent *Category,
+	attributes []Attribute) (*Category, *internal_error.InternalError) {
+	category := &Category{
+		Id:         strings.TrimSpace(id),
+		Name:       strings.TrimSpace(name),
+		Ancestors:  []string{},
+		Attributes: attributes,
+		Timestamp:  time.Now(),
+	}
+
+	if parent != nil {
+		category.ParentId = parent.Id
+		category.Ancestors = append(append(category.Ancestors, parent.Ancestors...), parent.Id)
+	}
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (c *Category) Validate() *internal_error.InternalError {
+	if !categoryIdPattern.MatchString(c.Id) || len(c.Id) > 50 {
+		return internal_error.NewBadRequestError(
+			"category id must have up to 50 lowercase letters, digits and hyphens")
+	}
+
+	if len(c.Name) <= 2 {
+		return internal_error.NewBadRequestError("category name must be longer than 2 characters")
+	}
+
+	names := make(map[string]bool, len(c.Attributes))
+	for i, attribute := range c.Attributes {
+		name := strings.TrimSpace(attribute.Name)
+		if name == "" {
+			return internal_error.NewBadRequestError("attribute name must not be empty")
+		}
+		if names[name] {
+			return internal_error.NewBadRequestError("attribute " + name + " is defined more than once")
+		}
+
+		names[name] = true
+		c.Attributes[i].Name = name
+
+		if attribute.Type == "" {
+			c.Attributes[i].Type = TextAttribute
+		}
+
+		switch c.Attributes[i].Type {
+		case TextAttribute, NumberAttribute, BooleanAttribute:
+			if len(attribute.Values) > 0 {
+				return internal_error.NewBadRequestError("only enum attributes list values")
+			}
+		case EnumAttribute:
+			if len(attribute.Values) == 0 {
+				return internal_error.NewBadRequestError("enum attribute " + name + " must list its values")
+			}
+		default:
+			return internal_error.NewBadRequestError("attribute " + name + " has an invalid type")
+		}
+	}
+
+	return nil
+}
+
+// Inherit returns the category at the end of path, from the root down, with the attributes of
+// its ancestors. A category can redefine an inherited attribute, e.g. to make it required
+func Inherit(path []Category) *Category {
+	if len(path) == 0 {
+		return nil
+	}
+
+	category := path[len(path)-1]
+	positions := make(map[string]int)
+	var attributes []Attribute
+	for _, node := range path {
+		for _, attribute := range node.Attributes {
+			if position, ok := positions[attribute.Name]; ok {
+				attributes[position] = attribute
+				continue
+			}
+
+			positions[attribute.Name] = len(attributes)
+			attributes = append(attributes, attribute)
+		}
+	}
+	category.Attributes = attributes
+
+	return &category
+}
+
+// ValidateAttributes checks the attributes of an item against the schema of the category: every
+// attribute must be defined by it, hold a value of its type, and the required ones must be set
+func (c *Category) ValidateAttributes(values map[string]string) *internal_error.InternalError {
+	definitions := make(map[string]Attribute, len(c.Attributes))
+	for _, attribute := range c.Attributes {
+		definitions[attribute.Name] = attribute
+	}
+
+	for name, value := range values {
+		attribute, ok := definitions[name]
+		if !ok {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("category %s does not define the attribute %s", c.Id, name))
+		}
+
+		// Empty values are left out, and required attributes reported as missing below
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		if err := attribute.validateValue(value); err != nil {
+			return err
+		}
+	}
+
+	var missing []string
+	for _, attribute := range c.Attributes {
+		if attribute.Required && strings.TrimSpace(values[attribute.Name]) == "" {
+			missing = append(missing, attribute.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("category %s requires the attributes %s", c.Id, strings.Join(missing, ", ")))
+	}
+
+	return nil
+}
+
+type CategoryRepositoryInterface interface {
+	CreateCategory(
+		ctx context.Context, category *Category) *internal_error.InternalError
+
+	UpdateCategory(
+		ctx context.Context, category *Category) *internal_error.InternalError
+
+	DeleteCategory(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	FindCategoryById(
+		ctx context.Context, id string) (*Category, *internal_error.InternalError)
+
+	// FindCategoryPath returns the category and its ancestors, from the root down
+	FindCategoryPath(
+		ctx context.Context, id string) ([]Category, *internal_error.InternalError)
+
+	FindCategories(
+		ctx context.Context) ([]Category, *internal_error.InternalError)
+
+	// FindDescendantIds returns the id of the category and of all categories below it, none
+	// when the category does not exist
+	FindDescendantIds(
+		ctx context.Context, id string) ([]string, *internal_error.InternalError)
+
+	CountChildren(
+		ctx context.Context, id string) (int64, *internal_error.InternalError)
+}
@@ -0,0 +1,90 @@
+package category_entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCreateCategory tests that subcategories keep the path of their ancestors and that
+// invalid ids and repeated attributes are rejected
+func TestCreateCategory(t *testing.T) {
+	electronics, err := CreateCategory("electronics", "Eletrônicos", nil, nil)
+	if err != nil {
+		t.Fatalf("Erro ao criar categoria: %v", err)
+	}
+
+	phones, err := CreateCategory("phones", "Celulares", electronics, []Attribute{{Name: " brand ", Required: true}})
+	if err != nil {
+		t.Fatalf("Erro ao criar subcategoria: %v", err)
+	}
+	if phones.ParentId != "electronics" || !reflect.DeepEqual(phones.Ancestors, []string{"electronics"}) {
+		t.Errorf("Esperado ancestral electronics, recebido %q %v", phones.ParentId, phones.Ancestors)
+	}
+	if phones.Attributes[0].Name != "brand" {
+		t.Errorf("Esperado nome do atributo sem espaços, recebido %q", phones.Attributes[0].Name)
+	}
+
+	if _, err := CreateCategory("Mobile Phones", "Celulares", electronics, nil); err == nil {
+		t.Error("Id com espaços e maiúsculas deveria ser rejeitado")
+	}
+
+	attributes := []Attribute{{Name: "brand"}, {Name: "brand", Required: true}}
+	if _, err := CreateCategory("phones", "Celulares", electronics, attributes); err == nil {
+		t.Error("Atributo repetido deveria ser rejeitado")
+	}
+}
+
+// TestInheritAttributes tests that categories inherit the attributes of their ancestors and can
+// make them required
+func TestInheritAttributes(t *testing.T) {
+	path := []Category{
+		{Id: "electronics", Attributes: []Attribute{{Name: "brand"}, {Name: "voltage"}}},
+		{Id: "phones", Attributes: []Attribute{{Name: "brand", Required: true}, {Name: "storage"}}},
+	}
+
+	category := Inherit(path)
+	expected := []Attribute{{Name: "brand", Required: true}, {Name: "voltage"}, {Name: "storage"}}
+	if category.Id != "phones" || !reflect.DeepEqual(category.Attributes, expected) {
+		t.Errorf("Esperado %v em phones, recebido %v em %s", expected, category.Attributes, category.Id)
+	}
+	if len(path[1].Attributes) != 2 {
+		t.Error("O caminho original não deveria ser alterado")
+	}
+}
+
+// TestValidateAttributes tests that item attributes follow the schema of the category
+func TestValidateAttributes(t *testing.T) {
+	category, err := CreateCategory("phones", "Celulares", nil, []Attribute{
+		{Name: "brand", Required: true},
+		{Name: "storage", Type: NumberAttribute},
+		{Name: "unlocked", Type: BooleanAttribute},
+		{Name: "color", Type: EnumAttribute, Values: []string{"black", "white"}},
+	})
+	if err != nil {
+		t.Fatalf("Erro ao criar categoria: %v", err)
+	}
+
+	testCases := []struct {
+		values map[string]string
+		valid  bool
+	}{
+		{values: map[string]string{"brand": "Acme", "storage": "128", "unlocked": "true", "color": "black"}, valid: true},
+		{values: map[string]string{"brand": "Acme", "storage": ""}, valid: true},
+		{values: map[string]string{"brand": " "}, valid: false},
+		{values: map[string]string{"brand": "Acme", "storage": "muito"}, valid: false},
+		{values: map[string]string{"brand": "Acme", "unlocked": "sim"}, valid: false},
+		{values: map[string]string{"brand": "Acme", "color": "red"}, valid: false},
+		{values: map[string]string{"brand": "Acme", "weight": "1"}, valid: false},
+	}
+
+	for _, testCase := range testCases {
+		err := category.ValidateAttributes(testCase.values)
+		if (err == nil) != testCase.valid {
+			t.Errorf("Atributos %v: esperado válido = %v, recebido erro %v", testCase.values, testCase.valid, err)
+		}
+	}
+
+	if _, err := CreateCategory("phones", "Celulares", nil, []Attribute{{Name: "color", Type: EnumAttribute}}); err == nil {
+		t.Error("Atributo enum sem valores deveria ser rejeitado")
+	}
+}
@@ -0,0 +1,116 @@
+package exchange_rate_entity
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// RateTable holds how many units of each currency one unit of the base currency buys.
+// It is only used to display indicative amounts, the native amount is always authoritative
+type RateTable struct {
+	Base      string
+	Rates     map[string]*big.Rat
+	UpdatedAt time.Time
+}
+
+func CreateRateTable(
+	base string, rates map[string]*big.Rat) (*RateTable, *internal_error.InternalError) {
+	rateTable := &RateTable{
+		Base:      base,
+		Rates:     make(map[string]*big.Rat, len(rates)+1),
+		UpdatedAt: time.Now(),
+	}
+	for currency, rate := range rates {
+		rateTable.Rates[currency] = rate
+	}
+	rateTable.Rates[base] = big.NewRat(1, 1)
+
+	if err := rateTable.Validate(); err != nil {
+		return nil, err
+	}
+
+	return rateTable, nil
+}
+
+func (rt *RateTable) Validate() *internal_error.InternalError {
+	if _, ok := money_entity.Exponent(rt.Base); !ok {
+		return internal_error.NewBadRequestError(fmt.Sprintf("Currency %s is not supported", rt.Base))
+	}
+
+	for currency, rate := range rt.Rates {
+		if _, ok := money_entity.Exponent(currency); !ok {
+			return internal_error.NewBadRequestError(fmt.Sprintf("Currency %s is not supported", currency))
+		}
+		if rate == nil || rate.Sign() <= 0 {
+			return internal_error.NewBadRequestError(fmt.Sprintf("Rate of %s must be positive", currency))
+		}
+	}
+
+	return nil
+}
+
+// Rate returns how many units of the target currency one unit of the source currency buys
+func (rt *RateTable) Rate(from, to string) (*big.Rat, *internal_error.InternalError) {
+	fromRate, ok := rt.Rates[from]
+	if !ok {
+		return nil, internal_error.NewBadRequestError(fmt.Sprintf("There is no exchange rate for %s", from))
+	}
+	toRate, ok := rt.Rates[to]
+	if !ok {
+		return nil, internal_error.NewBadRequestError(fmt.Sprintf("There is no exchange rate for %s", to))
+	}
+
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
+
+// Convert converts the amount into the target currency, rounding half away from zero to its
+// minor units
+func (rt *RateTable) Convert(
+	amount money_entity.Money, to string) (money_entity.Money, *internal_error.InternalError) {
+	rate, err := rt.Rate(amount.Currency, to)
+	if err != nil {
+		return money_entity.Money{}, err
+	}
+
+	fromExponent, _ := money_entity.Exponent(amount.Currency)
+	toExponent, _ := money_entity.Exponent(to)
+
+	converted := new(big.Rat).SetInt64(amount.Amount)
+	converted.Mul(converted, rate)
+	converted.Mul(converted, new(big.Rat).SetFrac(pow10(toExponent), pow10(fromExponent)))
+
+	return money_entity.New(roundHalfAwayFromZero(converted), to), nil
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
+
+func roundHalfAwayFromZero(value *big.Rat) int64 {
+	numerator := new(big.Int).Abs(value.Num())
+	denominator := value.Denom()
+
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if new(big.Int).Mul(remainder, big.NewInt(2)).Cmp(denominator) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+
+	return quotient.Int64()
+}
+
+type ExchangeRateRepositoryInterface interface {
+	FindRateTable(
+		ctx context.Context) (*RateTable, *internal_error.InternalError)
+
+	UpdateRateTable(
+		ctx context.Context, rateTable *RateTable) *internal_error.InternalError
+}
@@ -0,0 +1,43 @@
+package exchange_rate_entity
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+)
+
+// TestConvert tests conversions through the base currency, between different minor units
+func TestConvert(t *testing.T) {
+	brlRate, _ := new(big.Rat).SetString("5.10")
+	jpyRate, _ := new(big.Rat).SetString("150")
+
+	rateTable, err := CreateRateTable("USD", map[string]*big.Rat{"BRL": brlRate, "JPY": jpyRate})
+	if err != nil {
+		t.Fatalf("Erro ao criar tabela de câmbio: %v", err)
+	}
+
+	testCases := []struct {
+		amount   money_entity.Money
+		to       string
+		expected money_entity.Money
+	}{
+		{money_entity.New(1000, "USD"), "BRL", money_entity.New(5100, "BRL")},
+		{money_entity.New(5100, "BRL"), "USD", money_entity.New(1000, "USD")},
+		{money_entity.New(1000, "BRL"), "USD", money_entity.New(196, "USD")},
+		{money_entity.New(1000, "BRL"), "JPY", money_entity.New(294, "JPY")},
+		{money_entity.New(1000, "BRL"), "BRL", money_entity.New(1000, "BRL")},
+	}
+
+	for _, testCase := range testCases {
+		converted, err := rateTable.Convert(testCase.amount, testCase.to)
+		if err != nil || converted != testCase.expected {
+			t.Errorf("Conversão de %s para %s: esperado %s, recebido %s (erro: %v)",
+				testCase.amount.Display(), testCase.to, testCase.expected.Display(), converted.Display(), err)
+		}
+	}
+
+	if _, err := rateTable.Convert(money_entity.New(1000, "BRL"), "EUR"); err == nil {
+		t.Error("Conversão para moeda sem taxa deveria falhar")
+	}
+}
@@ -0,0 +1,233 @@
+package fee_entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// Tier charges a percentage of the price plus a fixed amount, both in major units of the
+// auction currency, for prices up to UpTo. The last tier of a rule has no upper bound
+type Tier struct {
+	UpTo    *big.Rat
+	Percent *big.Rat
+	Fixed   *big.Rat
+}
+
+// Rule is the fee of a category and of its subcategories without a rule of their own. The rule
+// without category applies to every category that has no rule along its path
+type Rule struct {
+	Category string
+	Tiers    []Tier
+}
+
+// Schedule holds the final value fee charged to sellers and the premium charged to buyers
+type Schedule struct {
+	SellerFeeRules    []Rule
+	BuyerPremiumRules []Rule
+}
+
+// Breakdown is what each side pays on top of, or out of, the final price
+type Breakdown struct {
+	FinalPrice   money_entity.Money
+	SellerFee    money_entity.Money
+	BuyerPremium money_entity.Money
+}
+
+// SellerPayout is what the seller receives once the final value fee is deducted
+func (b Breakdown) SellerPayout() money_entity.Money {
+	return b.FinalPrice.Subtract(b.SellerFee)
+}
+
+// BuyerTotal is what the buyer pays, premium included
+func (b Breakdown) BuyerTotal() money_entity.Money {
+	return b.FinalPrice.Add(b.BuyerPremium)
+}
+
+// Calculate returns the fees of an item sold at the price. categoryPath holds the category of
+// the item followed by its ancestors, and the rule of the nearest one applies. A nil schedule
+// charges no fees
+func (s *Schedule) Calculate(categoryPath []string, price money_entity.Money) Breakdown {
+	breakdown := Breakdown{
+		FinalPrice:   price,
+		SellerFee:    money_entity.New(0, price.Currency),
+		BuyerPremium: money_entity.New(0, price.Currency),
+	}
+	if s == nil {
+		return breakdown
+	}
+
+	if rule := findRule(s.SellerFeeRules, categoryPath); rule != nil {
+		breakdown.SellerFee = rule.calculate(price)
+	}
+	if rule := findRule(s.BuyerPremiumRules, categoryPath); rule != nil {
+		breakdown.BuyerPremium = rule.calculate(price)
+	}
+
+	return breakdown
+}
+
+func findRule(rules []Rule, categoryPath []string) *Rule {
+	for _, category := range categoryPath {
+		for i, rule := range rules {
+			if rule.Category == category {
+				return &rules[i]
+			}
+		}
+	}
+
+	var defaultRule *Rule
+	for i, rule := range rules {
+		if rule.Category == "" {
+			defaultRule = &rules[i]
+		}
+	}
+
+	return defaultRule
+}
+
+// calculate applies the tier the price falls into, rounding half up to minor units
+func (r *Rule) calculate(price money_entity.Money) money_entity.Money {
+	exponent, _ := money_entity.Exponent(price.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+	majorPrice := new(big.Rat).Quo(new(big.Rat).SetInt64(price.Amount), scale)
+
+	tier := r.Tiers[len(r.Tiers)-1]
+	for _, candidate := range r.Tiers {
+		if candidate.UpTo != nil && majorPrice.Cmp(candidate.UpTo) <= 0 {
+			tier = candidate
+			break
+		}
+	}
+
+	fee := new(big.Rat).Mul(majorPrice, tier.Percent)
+	fee.Quo(fee, big.NewRat(100, 1))
+	fee.Add(fee, tier.Fixed)
+	fee.Mul(fee, scale)
+
+	fee.Add(fee, big.NewRat(1, 2))
+	return money_entity.New(new(big.Int).Quo(fee.Num(), fee.Denom()).Int64(), price.Currency)
+}
+
+type tierJSON struct {
+	UpTo    json.Number `json:"up_to"`
+	Percent json.Number `json:"percent"`
+	Fixed   json.Number `json:"fixed"`
+}
+
+type ruleJSON struct {
+	Category string      `json:"category"`
+	Percent  json.Number `json:"percent"`
+	Fixed    json.Number `json:"fixed"`
+	Tiers    []tierJSON  `json:"tiers"`
+}
+
+type scheduleJSON struct {
+	SellerFee    []ruleJSON `json:"seller_fee"`
+	BuyerPremium []ruleJSON `json:"buyer_premium"`
+}
+
+// ParseSchedule reads a fee schedule such as
+//
+//	{
+//	  "seller_fee": [
+//	    {"percent": 10},
+//	    {"category": "Eletrônicos", "tiers": [{"up_to": 1000, "percent": 8}, {"percent": 5, "fixed": 10}]}
+//	  ],
+//	  "buyer_premium": [{"percent": 5}]
+//	}
+func ParseSchedule(data []byte) (*Schedule, *internal_error.InternalError) {
+	var parsed scheduleJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, internal_error.NewBadRequestError(fmt.Sprintf("Invalid fee schedule: %s", err.Error()))
+	}
+
+	sellerFeeRules, err := parseRules(parsed.SellerFee)
+	if err != nil {
+		return nil, err
+	}
+
+	buyerPremiumRules, err := parseRules(parsed.BuyerPremium)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schedule{
+		SellerFeeRules:    sellerFeeRules,
+		BuyerPremiumRules: buyerPremiumRules,
+	}, nil
+}
+
+func parseRules(rulesJSON []ruleJSON) ([]Rule, *internal_error.InternalError) {
+	categories := make(map[string]bool, len(rulesJSON))
+	rules := make([]Rule, 0, len(rulesJSON))
+	for _, ruleJSON := range rulesJSON {
+		if categories[ruleJSON.Category] {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Invalid fee schedule: category %q has more than one rule", ruleJSON.Category))
+		}
+		categories[ruleJSON.Category] = true
+
+		tiersJSON := ruleJSON.Tiers
+		if len(tiersJSON) == 0 {
+			tiersJSON = []tierJSON{{Percent: ruleJSON.Percent, Fixed: ruleJSON.Fixed}}
+		}
+
+		rule := Rule{Category: ruleJSON.Category}
+		for i, tierJSON := range tiersJSON {
+			tier, err := parseTier(tierJSON)
+			if err != nil {
+				return nil, err
+			}
+
+			last := i == len(tiersJSON)-1
+			if last != (tier.UpTo == nil) {
+				return nil, internal_error.NewBadRequestError(
+					"Invalid fee schedule: only the last tier of a rule has no up_to")
+			}
+			if i > 0 && tier.UpTo != nil && tier.UpTo.Cmp(rule.Tiers[i-1].UpTo) <= 0 {
+				return nil, internal_error.NewBadRequestError(
+					"Invalid fee schedule: tiers must be in increasing up_to order")
+			}
+
+			rule.Tiers = append(rule.Tiers, tier)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func parseTier(tierJSON tierJSON) (Tier, *internal_error.InternalError) {
+	tier := Tier{Percent: new(big.Rat), Fixed: new(big.Rat)}
+
+	for _, field := range []struct {
+		value  json.Number
+		target **big.Rat
+	}{
+		{tierJSON.UpTo, &tier.UpTo},
+		{tierJSON.Percent, &tier.Percent},
+		{tierJSON.Fixed, &tier.Fixed},
+	} {
+		if field.value == "" {
+			continue
+		}
+
+		value, ok := new(big.Rat).SetString(field.value.String())
+		if !ok || value.Sign() < 0 {
+			return Tier{}, internal_error.NewBadRequestError(
+				fmt.Sprintf("Invalid fee schedule: %s is not a valid amount", field.value))
+		}
+		*field.target = value
+	}
+
+	return tier, nil
+}
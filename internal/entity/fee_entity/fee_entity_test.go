@@ -0,0 +1,89 @@
+package fee_entity
+
+import (
+	"testing"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+)
+
+const testSchedule = `{
+	"seller_fee": [
+		{"percent": 10},
+		{"category": "Eletrônicos", "tiers": [{"up_to": 1000, "percent": 8}, {"percent": 5, "fixed": 10}]}
+	],
+	"buyer_premium": [{"category": "Arte", "percent": 12.5}]
+}`
+
+// TestCalculateFees tests percentage, fixed and tiered fees, and the fallback to the rule
+// without category
+func TestCalculateFees(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(testSchedule))
+	if err != nil {
+		t.Fatalf("Erro ao ler tabela de taxas: %v", err)
+	}
+
+	testCases := []struct {
+		category     string
+		price        money_entity.Money
+		sellerFee    money_entity.Money
+		buyerPremium money_entity.Money
+	}{
+		{"Livros", money_entity.New(5000, "BRL"), money_entity.New(500, "BRL"), money_entity.New(0, "BRL")},
+		{"Eletrônicos", money_entity.New(100000, "BRL"), money_entity.New(8000, "BRL"), money_entity.New(0, "BRL")},
+		{"Eletrônicos", money_entity.New(200000, "BRL"), money_entity.New(11000, "BRL"), money_entity.New(0, "BRL")},
+		{"Arte", money_entity.New(333, "BRL"), money_entity.New(33, "BRL"), money_entity.New(42, "BRL")},
+		{"Arte", money_entity.New(1000, "JPY"), money_entity.New(100, "JPY"), money_entity.New(125, "JPY")},
+	}
+
+	for _, testCase := range testCases {
+		breakdown := schedule.Calculate([]string{testCase.category}, testCase.price)
+		if breakdown.SellerFee != testCase.sellerFee || breakdown.BuyerPremium != testCase.buyerPremium {
+			t.Errorf("%s a %s: esperado taxa do vendedor %s e prêmio do comprador %s, recebido %s e %s",
+				testCase.category, testCase.price.Display(),
+				testCase.sellerFee.Display(), testCase.buyerPremium.Display(),
+				breakdown.SellerFee.Display(), breakdown.BuyerPremium.Display())
+		}
+	}
+
+	breakdown := schedule.Calculate([]string{"Livros"}, money_entity.New(5000, "BRL"))
+	if breakdown.SellerPayout() != money_entity.New(4500, "BRL") || breakdown.BuyerTotal() != money_entity.New(5000, "BRL") {
+		t.Errorf("Repasse ao vendedor ou total do comprador incorretos: %s e %s",
+			breakdown.SellerPayout().Display(), breakdown.BuyerTotal().Display())
+	}
+}
+
+// TestCalculateFeesInheritsRule tests that a subcategory without a rule of its own is charged the
+// rule of its nearest ancestor
+func TestCalculateFeesInheritsRule(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(testSchedule))
+	if err != nil {
+		t.Fatalf("Erro ao ler tabela de taxas: %v", err)
+	}
+
+	price := money_entity.New(100000, "BRL")
+	breakdown := schedule.Calculate([]string{"celulares", "Eletrônicos", "Livros"}, price)
+	if breakdown.SellerFee != money_entity.New(8000, "BRL") {
+		t.Errorf("Esperado a taxa de Eletrônicos, 80.00 BRL, recebido %s", breakdown.SellerFee.Display())
+	}
+
+	breakdown = schedule.Calculate([]string{"celulares", "acessorios"}, price)
+	if breakdown.SellerFee != money_entity.New(10000, "BRL") {
+		t.Errorf("Esperado a taxa padrão, 100.00 BRL, recebido %s", breakdown.SellerFee.Display())
+	}
+}
+
+// TestParseScheduleRejectsInvalidTiers tests that tiers must end unbounded and grow in order
+func TestParseScheduleRejectsInvalidTiers(t *testing.T) {
+	invalidSchedules := []string{
+		`{"seller_fee": [{"tiers": [{"up_to": 100, "percent": 5}]}]}`,
+		`{"seller_fee": [{"tiers": [{"up_to": 100, "percent": 5}, {"up_to": 50, "percent": 4}, {"percent": 3}]}]}`,
+		`{"seller_fee": [{"percent": -1}]}`,
+		`{"seller_fee": [{"percent": 1}, {"percent": 2}]}`,
+	}
+
+	for _, invalidSchedule := range invalidSchedules {
+		if _, err := ParseSchedule([]byte(invalidSchedule)); err == nil {
+			t.Errorf("Tabela de taxas inválida aceita: %s", invalidSchedule)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package idempotency_entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type RecordStatus string
+
+const (
+	// InProgress is a key whose first request is still being handled
+	InProgress RecordStatus = "in_progress"
+	// Completed is a key whose response was stored and is replayed to retries
+	Completed RecordStatus = "completed"
+)
+
+// Record ties an Idempotency-Key to the request that first used it and to its response
+type Record struct {
+	Key          string
+	RequestHash  string
+	Status       RecordStatus
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	ExpiresAt    time.Time
+}
+
+type IdempotencyRepositoryInterface interface {
+	// ReserveKey stores the record as in progress. When the key is already in use it stores
+	// nothing and returns the existing record instead
+	ReserveKey(
+		ctx context.Context, record Record) (*Record, *internal_error.InternalError)
+
+	// CompleteKey stores the response of the key, replayed to retries until expiresAt
+	CompleteKey(
+		ctx context.Context,
+		key string,
+		statusCode int,
+		contentType string,
+		responseBody []byte,
+		expiresAt time.Time) *internal_error.InternalError
+
+	// ReleaseKey drops the reservation so the request can be retried with the same key
+	ReleaseKey(
+		ctx context.Context, key string) *internal_error.InternalError
+}
@@ -0,0 +1,88 @@
+package image_entity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// MaxImagesPerAuction bounds the images attached to a single auction
+const MaxImagesPerAuction = 10
+
+// contentTypeExtensions holds the image formats accepted for upload
+var contentTypeExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// Image is a picture of the auctioned item. Key locates the file in the blob storage, which
+// also builds the URL it is served from
+type Image struct {
+	Id          string
+	AuctionId   string
+	Key         string
+	ContentType string
+	Size        int64
+	Timestamp   time.Time
+}
+
+// CreateImage creates an image of the auction with the content type detected from its bytes.
+// Images larger than maxSize are rejected
+func CreateImage(
+	auctionId, contentType string,
+	size, maxSize int64) (*Image, *internal_error.InternalError) {
+	image := &Image{
+		Id:          uuid.New().String(),
+		AuctionId:   auctionId,
+		ContentType: contentType,
+		Size:        size,
+		Timestamp:   time.Now(),
+	}
+
+	if err := image.Validate(maxSize); err != nil {
+		return nil, err
+	}
+
+	image.Key = fmt.Sprintf("auctions/%s/%s%s", auctionId, image.Id, contentTypeExtensions[contentType])
+	return image, nil
+}
+
+func (i *Image) Validate(maxSize int64) *internal_error.InternalError {
+	if err := uuid.Validate(i.AuctionId); err != nil {
+		return internal_error.NewBadRequestError("AuctionId is not a valid id")
+	}
+
+	if _, ok := contentTypeExtensions[i.ContentType]; !ok {
+		return internal_error.NewBadRequestError("image must be a JPEG, PNG or WebP file")
+	}
+
+	if i.Size <= 0 {
+		return internal_error.NewBadRequestError("image must not be empty")
+	}
+
+	if i.Size > maxSize {
+		return internal_error.NewBadRequestError(fmt.Sprintf("image must have up to %d bytes", maxSize))
+	}
+
+	return nil
+}
+
+// BlobStorageInterface stores the files uploaded to the application, such as auction images
+type BlobStorageInterface interface {
+	Put(
+		ctx context.Context,
+		key, contentType string,
+		body io.Reader,
+		size int64) *internal_error.InternalError
+
+	Delete(
+		ctx context.Context, key string) *internal_error.InternalError
+
+	// URL returns the address the file is served from
+	URL(key string) string
+}
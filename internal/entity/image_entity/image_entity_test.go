@@ -0,0 +1,38 @@
+package image_entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// TestCreateImage tests that only images of the accepted formats and size are created, keyed
+// under their auction
+func TestCreateImage(t *testing.T) {
+	auctionId := uuid.New().String()
+
+	image, err := CreateImage(auctionId, "image/png", 100, 1000)
+	if err != nil {
+		t.Fatalf("Erro ao criar imagem: %v", err)
+	}
+	if !strings.HasPrefix(image.Key, "auctions/"+auctionId+"/") || !strings.HasSuffix(image.Key, ".png") {
+		t.Errorf("Chave inesperada: %s", image.Key)
+	}
+
+	testCases := []struct {
+		contentType string
+		size        int64
+	}{
+		{contentType: "text/plain; charset=utf-8", size: 100},
+		{contentType: "image/gif", size: 100},
+		{contentType: "image/jpeg", size: 0},
+		{contentType: "image/jpeg", size: 1001},
+	}
+
+	for _, testCase := range testCases {
+		if _, err := CreateImage(auctionId, testCase.contentType, testCase.size, 1000); err == nil {
+			t.Errorf("Imagem %s com %d bytes deveria ser rejeitada", testCase.contentType, testCase.size)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package money_entity
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// Money is an exact amount in the minor units of its ISO 4217 currency, e.g. 1050 BRL is R$ 10,50
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// currencyExponents holds the number of minor unit digits of the supported currencies
+var currencyExponents = map[string]int{
+	"BRL": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"ARS": 2,
+	"MXN": 2,
+	"CAD": 2,
+	"AUD": 2,
+	"CHF": 2,
+	"CLP": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+}
+
+// Exponent returns the number of minor unit digits of the currency
+func Exponent(currency string) (int, bool) {
+	exponent, ok := currencyExponents[currency]
+	return exponent, ok
+}
+
+// DefaultCurrency returns the currency configured in DEFAULT_CURRENCY, BRL when it is not set
+func DefaultCurrency() string {
+	currency := strings.ToUpper(strings.TrimSpace(os.Getenv("DEFAULT_CURRENCY")))
+	if _, ok := Exponent(currency); !ok {
+		return "BRL"
+	}
+
+	return currency
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount in major units, e.g. "10.50", without going through floating
+// point. It rejects amounts with more decimal places than the currency has
+func Parse(value, currency string) (Money, *internal_error.InternalError) {
+	exponent, ok := Exponent(currency)
+	if !ok {
+		return Money{}, internal_error.NewBadRequestError(fmt.Sprintf("Currency %s is not supported", currency))
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	integerPart, fractionPart, _ := strings.Cut(value, ".")
+	if integerPart == "" || strings.ContainsAny(integerPart+fractionPart, "+-eE") {
+		return Money{}, internal_error.NewBadRequestError("Amount is not a valid value")
+	}
+
+	// Trailing zeros don't change the value, e.g. 10.500 BRL
+	fractionPart = strings.TrimRight(fractionPart, "0")
+	if len(fractionPart) > exponent {
+		return Money{}, internal_error.NewBadRequestError(
+			fmt.Sprintf("Amount has more than %d decimal places for %s", exponent, currency))
+	}
+	fractionPart += strings.Repeat("0", exponent-len(fractionPart))
+
+	amount, err := strconv.ParseInt(integerPart+fractionPart, 10, 64)
+	if err != nil {
+		return Money{}, internal_error.NewBadRequestError("Amount is not a valid value")
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// String formats the amount in major units, e.g. "10.50"
+func (m Money) String() string {
+	exponent, ok := Exponent(m.Currency)
+	if !ok || exponent == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := fmt.Sprintf("%0*d", exponent+1, amount)
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// Display formats the amount with its currency, e.g. "10.50 BRL"
+func (m Money) Display() string {
+	return m.String() + " " + m.Currency
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0 && m.Currency == ""
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Compare returns -1, 0 or 1 when the amount is smaller, equal or greater than the other one.
+// Both amounts must be in the same currency
+func (m Money) Compare(other Money) int {
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Add returns the sum of both amounts, which must be in the same currency
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}
+}
+
+// Subtract returns the difference of both amounts, which must be in the same currency
+func (m Money) Subtract(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}
+}
+
+func (m Money) Validate() *internal_error.InternalError {
+	if _, ok := Exponent(m.Currency); !ok {
+		return internal_error.NewBadRequestError(fmt.Sprintf("Currency %s is not supported", m.Currency))
+	}
+
+	return nil
+}
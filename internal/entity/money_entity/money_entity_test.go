@@ -0,0 +1,66 @@
+package money_entity
+
+import "testing"
+
+// TestParseMoney tests that decimal amounts are read exactly in minor units
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency string
+		expected int64
+		valid    bool
+	}{
+		{"10.50", "BRL", 1050, true},
+		{"0.1", "BRL", 10, true},
+		{"100", "BRL", 10000, true},
+		{"10.500", "BRL", 1050, true},
+		{"1500", "JPY", 1500, true},
+		{"1.234", "KWD", 1234, true},
+		{"10.505", "BRL", 0, false},
+		{"1.5", "JPY", 0, false},
+		{"1e3", "BRL", 0, false},
+		{"abc", "BRL", 0, false},
+		{"10.50", "XYZ", 0, false},
+	}
+
+	for _, testCase := range testCases {
+		money, err := Parse(testCase.value, testCase.currency)
+		if testCase.valid != (err == nil) {
+			t.Errorf("Parse(%q, %s): validade esperada %v, erro: %v", testCase.value, testCase.currency, testCase.valid, err)
+			continue
+		}
+		if testCase.valid && money.Amount != testCase.expected {
+			t.Errorf("Parse(%q, %s): esperado %d, recebido %d", testCase.value, testCase.currency, testCase.expected, money.Amount)
+		}
+	}
+}
+
+// TestMoneyString tests the formatting in major units
+func TestMoneyString(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		expected string
+	}{
+		{New(1050, "BRL"), "10.50"},
+		{New(5, "BRL"), "0.05"},
+		{New(-1050, "BRL"), "-10.50"},
+		{New(1500, "JPY"), "1500"},
+		{New(1234, "KWD"), "1.234"},
+	}
+
+	for _, testCase := range testCases {
+		if testCase.money.String() != testCase.expected {
+			t.Errorf("Formato esperado: %s, recebido: %s", testCase.expected, testCase.money.String())
+		}
+	}
+
+	// Adding ten increments of 0.10 is exact
+	total := New(0, "BRL")
+	increment, _ := Parse("0.1", "BRL")
+	for i := 0; i < 10; i++ {
+		total.Amount += increment.Amount
+	}
+	if total.String() != "1.00" {
+		t.Errorf("Soma esperada: 1.00, recebida: %s", total.String())
+	}
+}
@@ -0,0 +1,129 @@
+package notification_entity
+
+import (
+	"context"
+	"net/mail"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type NotificationKind string
+
+const (
+	Outbid        NotificationKind = "outbid"
+	AuctionWon    NotificationKind = "auction_won"
+	AuctionClosed NotificationKind = "auction_closed"
+	EndingSoon    NotificationKind = "ending_soon"
+)
+
+type Channel string
+
+const (
+	EmailChannel Channel = "email"
+	LogChannel   Channel = "log"
+)
+
+type Notification struct {
+	UserId    string
+	AuctionId string
+	Kind      NotificationKind
+	Subject   string
+	Message   string
+	Timestamp time.Time
+}
+
+func CreateNotification(
+	userId, auctionId string,
+	kind NotificationKind,
+	subject, message string) Notification {
+	return Notification{
+		UserId:    userId,
+		AuctionId: auctionId,
+		Kind:      kind,
+		Subject:   subject,
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+}
+
+type Preference struct {
+	UserId        string
+	Email         string
+	Channels      []Channel
+	OutbidAlerts  bool
+	WinnerAlerts  bool
+	ClosingAlerts bool
+}
+
+// DefaultPreference is used for users that never configured their notifications:
+// every alert is enabled but only delivered to the log channel
+func DefaultPreference(userId string) *Preference {
+	return &Preference{
+		UserId:        userId,
+		Channels:      []Channel{LogChannel},
+		OutbidAlerts:  true,
+		WinnerAlerts:  true,
+		ClosingAlerts: true,
+	}
+}
+
+func (p *Preference) Validate() *internal_error.InternalError {
+	for _, channel := range p.Channels {
+		switch channel {
+		case LogChannel:
+		case EmailChannel:
+			// Only a bare address is accepted, not forms like "Name <user@example.com>", since
+			// the email is used as is when sending
+			if addr, err := mail.ParseAddress(p.Email); err != nil || addr.Address != p.Email {
+				return internal_error.NewBadRequestError("a valid email is required for the email channel")
+			}
+		default:
+			return internal_error.NewBadRequestError("invalid notification channel")
+		}
+	}
+
+	return nil
+}
+
+// Allows reports whether the user opted in to receive notifications of the given kind
+func (p *Preference) Allows(kind NotificationKind) bool {
+	switch kind {
+	case Outbid:
+		return p.OutbidAlerts
+	case AuctionWon:
+		return p.WinnerAlerts
+	case AuctionClosed, EndingSoon:
+		return p.ClosingAlerts
+	default:
+		return true
+	}
+}
+
+type NotifierInterface interface {
+	Notify(
+		ctx context.Context,
+		preference Preference,
+		notification Notification) *internal_error.InternalError
+}
+
+type PreferenceRepositoryInterface interface {
+	FindPreferenceByUserId(
+		ctx context.Context, userId string) (*Preference, *internal_error.InternalError)
+
+	UpsertPreference(
+		ctx context.Context, preference *Preference) *internal_error.InternalError
+}
+
+// NotificationDispatcherInterface is implemented by the component that resolves the
+// recipients of auction events and fans them out to the configured notifiers
+type NotificationDispatcherInterface interface {
+	NotifyOutbid(ctx context.Context, outbidBid, newBid bid_entity.Bid)
+
+	NotifyAuctionClosed(ctx context.Context, auction auction_entity.Auction)
+
+	NotifyAuctionEndingSoon(
+		ctx context.Context, auction auction_entity.Auction, window time.Duration)
+}
@@ -0,0 +1,31 @@
+package notification_entity
+
+import "testing"
+
+// TestPreferenceValidateEmail tests that the email channel only accepts a bare email address
+func TestPreferenceValidateEmail(t *testing.T) {
+	testCases := []struct {
+		email string
+		valid bool
+	}{
+		{email: "user@example.com", valid: true},
+		{email: "", valid: false},
+		{email: "user", valid: false},
+		{email: "Usuário <user@example.com>", valid: false},
+		{email: "<user@example.com>", valid: false},
+		{email: " user@example.com", valid: false},
+	}
+
+	for _, testCase := range testCases {
+		preference := Preference{Channels: []Channel{EmailChannel}, Email: testCase.email}
+		if err := preference.Validate(); (err == nil) != testCase.valid {
+			t.Errorf("Validação inesperada do email %q: esperado válido=%v, recebido %v",
+				testCase.email, testCase.valid, err)
+		}
+	}
+
+	preference := Preference{Channels: []Channel{LogChannel}}
+	if err := preference.Validate(); err != nil {
+		t.Errorf("Canal de log não deveria exigir email: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package offer_entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type OfferStatus string
+
+const (
+	Pending  OfferStatus = "pending"
+	Accepted OfferStatus = "accepted"
+	Declined OfferStatus = "declined"
+	Expired  OfferStatus = "expired"
+)
+
+// Offer is a second chance for a runner-up bidder to buy the item of a closed auction at their
+// own bid price, when the winner defaulted or the reserve price was not met
+type Offer struct {
+	Id          string
+	AuctionId   string
+	BidId       string
+	UserId      string
+	Amount      money_entity.Money
+	Status      OfferStatus
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	RespondedAt time.Time
+}
+
+// CreateOffer offers the item to the user of the bid, open until the expiry
+func CreateOffer(bid bid_entity.Bid, expiry time.Duration) (*Offer, *internal_error.InternalError) {
+	now := time.Now()
+	offer := &Offer{
+		Id:        uuid.New().String(),
+		AuctionId: bid.AuctionId,
+		BidId:     bid.Id,
+		UserId:    bid.UserId,
+		Amount:    bid.Amount,
+		Status:    Pending,
+		ExpiresAt: now.Add(expiry),
+		CreatedAt: now,
+	}
+
+	if err := offer.Validate(); err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+func (o *Offer) Validate() *internal_error.InternalError {
+	if err := uuid.Validate(o.UserId); err != nil {
+		return internal_error.NewBadRequestError("UserId is not a valid id")
+	} else if err := uuid.Validate(o.AuctionId); err != nil {
+		return internal_error.NewBadRequestError("AuctionId is not a valid id")
+	} else if !o.Amount.IsPositive() {
+		return internal_error.NewBadRequestError("Amount is not a valid value")
+	} else if !o.ExpiresAt.After(o.CreatedAt) {
+		return internal_error.NewBadRequestError("Offer must expire after it is created")
+	}
+
+	return nil
+}
+
+// IsOpen reports whether the offer can still be accepted or declined at the given time
+func (o *Offer) IsOpen(now time.Time) bool {
+	return o.Status == Pending && now.Before(o.ExpiresAt)
+}
+
+type OfferRepositoryInterface interface {
+	// CreateOffer stores a single offer per bid, returning the one already stored when the
+	// offer is retried. It fails with a bad request when another offer of the auction is still
+	// pending
+	CreateOffer(
+		ctx context.Context, offer *Offer) (*Offer, *internal_error.InternalError)
+
+	FindOfferById(
+		ctx context.Context, id string) (*Offer, *internal_error.InternalError)
+
+	FindOffersByAuctionId(
+		ctx context.Context, auctionId string) ([]Offer, *internal_error.InternalError)
+
+	FindOffersByUserId(
+		ctx context.Context, userId string) ([]Offer, *internal_error.InternalError)
+
+	// FindPendingOffersExpiredBefore returns the pending offers that expired before the given time
+	FindPendingOffersExpiredBefore(
+		ctx context.Context, before time.Time) ([]Offer, *internal_error.InternalError)
+
+	// UpdateOfferStatus moves the offer only while it is still in the from status, reporting
+	// whether it did. The given time is recorded as the response time
+	UpdateOfferStatus(
+		ctx context.Context,
+		id string,
+		from, to OfferStatus,
+		at time.Time) (bool, *internal_error.InternalError)
+}
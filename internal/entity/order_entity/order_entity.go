@@ -0,0 +1,196 @@
+package order_entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/fee_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type OrderStatus string
+
+const (
+	Pending   OrderStatus = "pending"
+	Paid      OrderStatus = "paid"
+	Overdue   OrderStatus = "overdue"
+	Defaulted OrderStatus = "defaulted"
+)
+
+// CanTransitionTo reports whether an order in this status may move to the next one. Overdue
+// orders can still be paid until they are defaulted, paid and defaulted orders are final
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	switch s {
+	case Pending:
+		return next == Paid || next == Overdue
+	case Overdue:
+		return next == Paid || next == Defaulted
+	default:
+		return false
+	}
+}
+
+// Order is what the buyer of an auction owes for the item. Prepaid is the part already
+// settled from the buyer's wallet when the auction closed. The seller fee is deducted from
+// what the seller receives and the buyer premium is added to what the buyer pays
+type Order struct {
+	Id               string
+	AuctionId        string
+	BidId            string
+	BuyerId          string
+	SellerId         string
+	ProductName      string
+	FinalPrice       money_entity.Money
+	SellerFee        money_entity.Money
+	BuyerPremium     money_entity.Money
+	Prepaid          money_entity.Money
+	Status           OrderStatus
+	PaymentReference string
+	DueAt            time.Time
+	CreatedAt        time.Time
+	PaidAt           time.Time
+}
+
+// CreateOrder sells the auction item to the user of the bid at the bid amount, with the fees
+// calculated for that amount. Orders with nothing left to pay are created as paid
+func CreateOrder(
+	auction auction_entity.Auction,
+	bid bid_entity.Bid,
+	fees fee_entity.Breakdown,
+	prepaid money_entity.Money,
+	paymentWindow time.Duration) (*Order, *internal_error.InternalError) {
+	now := time.Now()
+	order := &Order{
+		Id:           uuid.New().String(),
+		AuctionId:    auction.Id,
+		BidId:        bid.Id,
+		BuyerId:      bid.UserId,
+		SellerId:     auction.SellerId,
+		ProductName:  auction.ProductName,
+		FinalPrice:   bid.Amount,
+		SellerFee:    fees.SellerFee,
+		BuyerPremium: fees.BuyerPremium,
+		Prepaid:      prepaid,
+		Status:       Pending,
+		DueAt:        now.Add(paymentWindow),
+		CreatedAt:    now,
+	}
+
+	if fees.FinalPrice != bid.Amount {
+		return nil, internal_error.NewBadRequestError("Fees were not calculated for the final price")
+	}
+
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !order.AmountDue().IsPositive() {
+		order.Status = Paid
+		order.PaidAt = now
+	}
+
+	return order, nil
+}
+
+func (o *Order) Validate() *internal_error.InternalError {
+	if err := uuid.Validate(o.BuyerId); err != nil {
+		return internal_error.NewBadRequestError("BuyerId is not a valid id")
+	} else if !o.FinalPrice.IsPositive() {
+		return internal_error.NewBadRequestError("Final price is not a valid value")
+	}
+
+	for _, amount := range []money_entity.Money{o.SellerFee, o.BuyerPremium, o.Prepaid} {
+		if amount.Currency != o.FinalPrice.Currency {
+			return internal_error.NewBadRequestError("Order amounts must be in the same currency")
+		} else if amount.Amount < 0 {
+			return internal_error.NewBadRequestError("Order amounts can't be negative")
+		}
+	}
+
+	if o.SellerFee.Amount > o.FinalPrice.Amount {
+		return internal_error.NewBadRequestError("Seller fee can't exceed the final price")
+	}
+
+	return nil
+}
+
+// Total is what the buyer pays for the item, premium included
+func (o *Order) Total() money_entity.Money {
+	return o.FinalPrice.Add(o.BuyerPremium)
+}
+
+// SellerPayout is what the seller receives once the seller fee is deducted
+func (o *Order) SellerPayout() money_entity.Money {
+	return o.FinalPrice.Subtract(o.SellerFee)
+}
+
+// AmountDue is what is left to pay after the prepaid amount
+func (o *Order) AmountDue() money_entity.Money {
+	amountDue := o.Total().Subtract(o.Prepaid)
+	if amountDue.Amount < 0 {
+		return money_entity.New(0, amountDue.Currency)
+	}
+
+	return amountDue
+}
+
+type OrderRepositoryInterface interface {
+	// CreateOrder stores a single order per bid, returning the one already stored when the
+	// settlement is retried
+	CreateOrder(
+		ctx context.Context, order *Order) (*Order, *internal_error.InternalError)
+
+	FindOrderById(
+		ctx context.Context, id string) (*Order, *internal_error.InternalError)
+
+	FindOrdersByBuyerId(
+		ctx context.Context, buyerId string) ([]Order, *internal_error.InternalError)
+
+	FindOrdersByAuctionId(
+		ctx context.Context, auctionId string) ([]Order, *internal_error.InternalError)
+
+	// FindOrdersDueBefore returns the orders in the status that were due before the given time
+	FindOrdersDueBefore(
+		ctx context.Context,
+		status OrderStatus,
+		before time.Time) ([]Order, *internal_error.InternalError)
+
+	// UpdateOrderStatus moves the order only while it is still in the from status, reporting
+	// whether it did. Paid orders record the given time as the payment time
+	UpdateOrderStatus(
+		ctx context.Context,
+		id string,
+		from, to OrderStatus,
+		at time.Time) (bool, *internal_error.InternalError)
+
+	UpdatePaymentReference(
+		ctx context.Context, id, paymentReference string) *internal_error.InternalError
+}
+
+type PaymentStatus string
+
+const (
+	PaymentPending   PaymentStatus = "pending"
+	PaymentConfirmed PaymentStatus = "confirmed"
+	PaymentFailed    PaymentStatus = "failed"
+)
+
+// PaymentProviderInterface is implemented by the services that charge buyers for their orders
+type PaymentProviderInterface interface {
+	// CreatePayment charges the amount due of the order, returning the provider reference
+	CreatePayment(
+		ctx context.Context, order Order) (string, *internal_error.InternalError)
+
+	ConfirmPayment(
+		ctx context.Context, paymentReference string) (PaymentStatus, *internal_error.InternalError)
+}
+
+// AuctionSettlerInterface is implemented by the component that settles auctions once closed
+type AuctionSettlerInterface interface {
+	SettleAuction(
+		ctx context.Context, auctionId string) *internal_error.InternalError
+}
@@ -0,0 +1,74 @@
+package order_entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/fee_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+)
+
+// TestCreateOrder tests that the amount due discounts what was prepaid and that fully prepaid
+// orders are created as paid
+func TestCreateOrder(t *testing.T) {
+	auction := auction_entity.Auction{Id: uuid.New().String(), ProductName: "Produto"}
+	bid := bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: auction.Id,
+		Amount:    money_entity.New(10000, "BRL"),
+	}
+
+	fees := fee_entity.Breakdown{
+		FinalPrice:   bid.Amount,
+		SellerFee:    money_entity.New(1000, "BRL"),
+		BuyerPremium: money_entity.New(500, "BRL"),
+	}
+
+	order, err := CreateOrder(auction, bid, fees, money_entity.New(10000, "BRL"), time.Hour)
+	if err != nil {
+		t.Fatalf("Erro ao criar pedido: %v", err)
+	}
+	if order.Status != Pending || order.AmountDue() != money_entity.New(500, "BRL") {
+		t.Errorf("Esperado pedido pendente com 5.00 BRL a pagar, recebido %s com %s",
+			order.Status, order.AmountDue().Display())
+	}
+	if order.SellerPayout() != money_entity.New(9000, "BRL") {
+		t.Errorf("Esperado repasse ao vendedor de 90.00 BRL, recebido %s", order.SellerPayout().Display())
+	}
+
+	fees.BuyerPremium = money_entity.New(0, "BRL")
+	order, err = CreateOrder(auction, bid, fees, money_entity.New(10000, "BRL"), time.Hour)
+	if err != nil {
+		t.Fatalf("Erro ao criar pedido: %v", err)
+	}
+	if order.Status != Paid {
+		t.Errorf("Pedido sem valor a pagar deveria ser criado como pago, recebido %s", order.Status)
+	}
+}
+
+// TestOrderStatusTransitions tests that paid and defaulted orders are final
+func TestOrderStatusTransitions(t *testing.T) {
+	testCases := []struct {
+		from, to OrderStatus
+		allowed  bool
+	}{
+		{Pending, Paid, true},
+		{Pending, Overdue, true},
+		{Pending, Defaulted, false},
+		{Overdue, Paid, true},
+		{Overdue, Defaulted, true},
+		{Paid, Overdue, false},
+		{Defaulted, Paid, false},
+	}
+
+	for _, testCase := range testCases {
+		if allowed := testCase.from.CanTransitionTo(testCase.to); allowed != testCase.allowed {
+			t.Errorf("Transição de %s para %s: esperado %v, recebido %v",
+				testCase.from, testCase.to, testCase.allowed, allowed)
+		}
+	}
+}
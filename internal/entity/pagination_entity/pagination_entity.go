@@ -0,0 +1,24 @@
+package pagination_entity
+
+const (
+	DefaultLimit int64 = 20
+	MaxLimit     int64 = 100
+)
+
+// PageRequest asks for up to Limit items after the cursor returned with the previous page. An
+// empty cursor asks for the first page
+type PageRequest struct {
+	Limit  int64
+	Cursor string
+}
+
+// NewPageRequest uses DefaultLimit when no limit is given and caps it at MaxLimit
+func NewPageRequest(limit int64, cursor string) PageRequest {
+	if limit <= 0 {
+		limit = DefaultLimit
+	} else if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	return PageRequest{Limit: limit, Cursor: cursor}
+}
@@ -0,0 +1,63 @@
+package rate_limit_entity
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// Limit configures a token bucket: it refills Rate tokens per second up to Burst tokens.
+// A limit without a positive rate or burst doesn't limit anything
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// ParseLimit reads a limit written as "rate:burst", e.g. "2:10" for 2 tokens per second and a
+// burst of 10
+func ParseLimit(value string) (Limit, error) {
+	rateValue, burstValue, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected rate:burst", value)
+	}
+
+	rate, err := strconv.ParseFloat(rateValue, 64)
+	if err != nil {
+		return Limit{}, fmt.Errorf("invalid rate in rate limit %q", value)
+	}
+
+	burst, err := strconv.Atoi(burstValue)
+	if err != nil {
+		return Limit{}, fmt.Errorf("invalid burst in rate limit %q", value)
+	}
+
+	return Limit{Rate: rate, Burst: burst}, nil
+}
+
+// Bucket is the token bucket identified by Key, sized by Limit
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+type RateLimiterInterface interface {
+	// Allow takes a token from the bucket identified by the key. When the bucket is empty it
+	// returns how long until the next token is available
+	Allow(
+		ctx context.Context, key string, limit Limit) (bool, time.Duration, *internal_error.InternalError)
+
+	// AllowAll takes a token from every bucket only when all of them have one, so a request
+	// rejected by one bucket doesn't consume the others. Otherwise it returns the index of the
+	// first empty bucket and how long until every bucket has a token. The index is -1 when
+	// the tokens were taken
+	AllowAll(
+		ctx context.Context, buckets []Bucket) (int, time.Duration, *internal_error.InternalError)
+}
@@ -6,9 +6,17 @@ import (
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 )
 
+type UserRole string
+
+const (
+	RegularRole  UserRole = "regular"
+	VerifiedRole UserRole = "verified"
+)
+
 type User struct {
 	Id   string
 	Name string
+	Role UserRole
 }
 
 type UserRepositoryInterface interface {
@@ -0,0 +1,146 @@
+package wallet_entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// Wallet is the balance of a user in one currency. Funds held for leading bids can't back
+// other bids until the hold is released
+type Wallet struct {
+	UserId      string
+	Currency    string
+	Balance     money_entity.Money
+	CreditLimit money_entity.Money
+	Held        money_entity.Money
+	UpdatedAt   time.Time
+}
+
+// NewWallet returns the empty wallet a user has in a currency before any deposit
+func NewWallet(userId, currency string) *Wallet {
+	return &Wallet{
+		UserId:      userId,
+		Currency:    currency,
+		Balance:     money_entity.New(0, currency),
+		CreditLimit: money_entity.New(0, currency),
+		Held:        money_entity.New(0, currency),
+	}
+}
+
+// Available is the credit the user can still commit to new bids
+func (w *Wallet) Available() money_entity.Money {
+	return w.Balance.Add(w.CreditLimit).Subtract(w.Held)
+}
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldReleased HoldStatus = "released"
+	HoldSettled  HoldStatus = "settled"
+	HoldRefunded HoldStatus = "refunded"
+)
+
+// Hold reserves the amount of the bid a user leads an auction with. Each user has at most one
+// active hold per auction, raised as the user outbids themselves
+type Hold struct {
+	UserId    string
+	AuctionId string
+	BidId     string
+	Amount    money_entity.Money
+	Status    HoldStatus
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TransactionType string
+
+const (
+	DepositTransaction    TransactionType = "deposit"
+	SettlementTransaction TransactionType = "settlement"
+	RefundTransaction     TransactionType = "refund"
+)
+
+// Transaction records every change to a wallet balance. Reference points to what caused it,
+// e.g. the auction a hold was settled for
+type Transaction struct {
+	Id        string
+	UserId    string
+	Type      TransactionType
+	Amount    money_entity.Money
+	Reference string
+	CreatedAt time.Time
+}
+
+func CreateDeposit(
+	userId string, amount money_entity.Money) (*Transaction, *internal_error.InternalError) {
+	transaction := &Transaction{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		Type:      DepositTransaction,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	}
+
+	if err := uuid.Validate(userId); err != nil {
+		return nil, internal_error.NewBadRequestError("UserId is not a valid id")
+	} else if !amount.IsPositive() {
+		return nil, internal_error.NewBadRequestError("Amount is not a valid value")
+	} else if err := amount.Validate(); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// NewInsufficientCreditError is returned when the available credit doesn't cover a bid
+func NewInsufficientCreditError() *internal_error.InternalError {
+	return internal_error.NewBadRequestError("Bid exceeds available credit")
+}
+
+type WalletRepositoryInterface interface {
+	// FindWallet returns an empty wallet when the user never had funds in the currency
+	FindWallet(
+		ctx context.Context, userId, currency string) (*Wallet, *internal_error.InternalError)
+
+	FindWallets(
+		ctx context.Context, userId string) ([]Wallet, *internal_error.InternalError)
+
+	Deposit(
+		ctx context.Context, transaction Transaction) *internal_error.InternalError
+
+	UpdateCreditLimit(
+		ctx context.Context, userId string, creditLimit money_entity.Money) *internal_error.InternalError
+
+	// FindHold returns the active hold of the user on the auction, nil when there is none
+	FindHold(
+		ctx context.Context, userId, auctionId string) (*Hold, *internal_error.InternalError)
+
+	// HoldFunds raises the hold of the user on the auction to the hold amount, failing with an
+	// insufficient credit error when the wallet can't cover the difference. A hold that already
+	// covers the amount is kept as it is
+	HoldFunds(
+		ctx context.Context, hold Hold) *internal_error.InternalError
+
+	// ReleaseHold releases the hold only while it still belongs to the bid, so a stale release
+	// doesn't drop the hold of a newer bid of the same user
+	ReleaseHold(
+		ctx context.Context, userId, auctionId, bidId string) *internal_error.InternalError
+
+	// SettleHold debits the held amount from the wallet, returning the settled hold or nil
+	// when the user had no active hold on the auction
+	SettleHold(
+		ctx context.Context, userId, auctionId string) (*Hold, *internal_error.InternalError)
+
+	// RefundSettledHold credits the settled amount of the user on the auction back, returning
+	// the refunded hold or nil when there was no settled hold left to refund
+	RefundSettledHold(
+		ctx context.Context, userId, auctionId string) (*Hold, *internal_error.InternalError)
+
+	ReleaseAuctionHolds(
+		ctx context.Context, auctionId string) *internal_error.InternalError
+}
@@ -0,0 +1,44 @@
+package wallet_entity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+)
+
+// TestWalletAvailable tests that held funds are taken from the balance plus the credit limit
+func TestWalletAvailable(t *testing.T) {
+	wallet := NewWallet(uuid.New().String(), "BRL")
+	wallet.Balance = money_entity.New(10000, "BRL")
+	wallet.CreditLimit = money_entity.New(5000, "BRL")
+	wallet.Held = money_entity.New(12000, "BRL")
+
+	if available := wallet.Available(); available != money_entity.New(3000, "BRL") {
+		t.Errorf("Esperado crédito disponível 30.00 BRL, recebido %s", available.Display())
+	}
+}
+
+// TestCreateDeposit tests that only positive deposits in supported currencies are accepted
+func TestCreateDeposit(t *testing.T) {
+	userId := uuid.New().String()
+
+	if _, err := CreateDeposit(userId, money_entity.New(1000, "BRL")); err != nil {
+		t.Errorf("Depósito válido rejeitado: %v", err)
+	}
+
+	invalidDeposits := []money_entity.Money{
+		money_entity.New(0, "BRL"),
+		money_entity.New(-1000, "BRL"),
+		money_entity.New(1000, "XYZ"),
+	}
+	for _, amount := range invalidDeposits {
+		if _, err := CreateDeposit(userId, amount); err == nil {
+			t.Errorf("Depósito de %s deveria ser rejeitado", amount.Display())
+		}
+	}
+
+	if _, err := CreateDeposit("invalid", money_entity.New(1000, "BRL")); err == nil {
+		t.Error("Depósito para usuário inválido deveria ser rejeitado")
+	}
+}
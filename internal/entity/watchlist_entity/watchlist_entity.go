@@ -0,0 +1,56 @@
+package watchlist_entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type WatchlistItem struct {
+	UserId    string
+	AuctionId string
+	Timestamp time.Time
+}
+
+func CreateWatchlistItem(userId, auctionId string) (*WatchlistItem, *internal_error.InternalError) {
+	item := &WatchlistItem{
+		UserId:    userId,
+		AuctionId: auctionId,
+		Timestamp: time.Now(),
+	}
+
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (w *WatchlistItem) Validate() *internal_error.InternalError {
+	if err := uuid.Validate(w.UserId); err != nil {
+		return internal_error.NewBadRequestError("UserId is not a valid id")
+	} else if err := uuid.Validate(w.AuctionId); err != nil {
+		return internal_error.NewBadRequestError("AuctionId is not a valid id")
+	}
+
+	return nil
+}
+
+type WatchlistRepositoryInterface interface {
+	AddWatchedAuction(
+		ctx context.Context, item *WatchlistItem) *internal_error.InternalError
+
+	RemoveWatchedAuction(
+		ctx context.Context, userId, auctionId string) *internal_error.InternalError
+
+	FindWatchedAuctionsByUserId(
+		ctx context.Context, userId string) ([]WatchlistItem, *internal_error.InternalError)
+
+	FindWatcherIdsByAuctionId(
+		ctx context.Context, auctionId string) ([]string, *internal_error.InternalError)
+
+	CountWatchersByAuctionId(
+		ctx context.Context, auctionId string) (int64, *internal_error.InternalError)
+}
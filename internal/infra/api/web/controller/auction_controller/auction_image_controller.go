@@ -0,0 +1,106 @@
+package auction_controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/image_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/validation"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/auction_usecase"
+)
+
+// AddAuctionImages receives the images in the "images" fields of a multipart form, along with
+// the seller_id of the auction
+func (u *AuctionController) AddAuctionImages(c *gin.Context) {
+	auctionId := c.Param("auctionId")
+
+	if err := uuid.Validate(auctionId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "auctionId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	// Leaves room for the other form fields besides the largest images accepted
+	maxRequestSize := image_entity.MaxImagesPerAuction*auction_usecase.GetMaxImageSize() + 1<<20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestSize)
+
+	var imageInputDTO auction_usecase.ImageInputDTO
+	if err := c.ShouldBind(&imageInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		restErr := rest_err.NewBadRequestError("Images must be sent as a multipart form")
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	var uploads []auction_usecase.ImageUpload
+	for _, fileHeader := range form.File["images"] {
+		file, err := fileHeader.Open()
+		if err != nil {
+			restErr := rest_err.NewBadRequestError("Error trying to read image")
+
+			c.JSON(restErr.Code, restErr)
+			return
+		}
+		defer file.Close()
+
+		uploads = append(uploads, auction_usecase.ImageUpload{Body: file, Size: fileHeader.Size})
+	}
+
+	images, imageErr := u.auctionUseCase.AddAuctionImages(
+		context.Background(), auctionId, imageInputDTO, uploads)
+	if imageErr != nil {
+		restErr := rest_err.ConvertError(imageErr)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, images)
+}
+
+func (u *AuctionController) RemoveAuctionImage(c *gin.Context) {
+	for _, param := range []string{"auctionId", "imageId"} {
+		if err := uuid.Validate(c.Param(param)); err != nil {
+			errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+				Field:   param,
+				Message: "Invalid UUID value",
+			})
+
+			c.JSON(errRest.Code, errRest)
+			return
+		}
+	}
+
+	var imageInputDTO auction_usecase.ImageInputDTO
+	if err := c.ShouldBindQuery(&imageInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if err := u.auctionUseCase.RemoveAuctionImage(
+		context.Background(), c.Param("auctionId"), c.Param("imageId"), imageInputDTO); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
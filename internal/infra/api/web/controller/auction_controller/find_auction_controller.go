@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/validation"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/auction_usecase"
 )
 
@@ -38,6 +39,7 @@ func (u *AuctionController) FindAuctions(c *gin.Context) {
 	status := c.Query("status")
 	category := c.Query("category")
 	productName := c.Query("productName")
+	currency := c.Query("currency")
 
 	statusNumber, errConv := strconv.Atoi(status)
 	if errConv != nil {
@@ -46,8 +48,15 @@ func (u *AuctionController) FindAuctions(c *gin.Context) {
 		return
 	}
 
+	var pageInputDTO auction_usecase.AuctionPageInputDTO
+	if err := c.ShouldBindQuery(&pageInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
 	auctions, err := u.auctionUseCase.FindAuctions(context.Background(),
-		auction_usecase.AuctionStatus(statusNumber), category, productName)
+		auction_usecase.AuctionStatus(statusNumber), category, productName, currency, pageInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -70,7 +79,8 @@ func (u *AuctionController) FindWinningBidByAuctionId(c *gin.Context) {
 		return
 	}
 
-	auctionData, err := u.auctionUseCase.FindWinningBidByAuctionId(context.Background(), auctionId)
+	auctionData, err := u.auctionUseCase.FindWinningBidByAuctionId(
+		context.Background(), auctionId, c.Query("currency"))
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -79,3 +89,21 @@ func (u *AuctionController) FindWinningBidByAuctionId(c *gin.Context) {
 
 	c.JSON(http.StatusOK, auctionData)
 }
+
+func (u *AuctionController) SearchAuctions(c *gin.Context) {
+	var searchInputDTO auction_usecase.AuctionSearchInputDTO
+	if err := c.ShouldBindQuery(&searchInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	auctions, err := u.auctionUseCase.SearchAuctions(context.Background(), searchInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctions)
+}
@@ -2,7 +2,11 @@ package bid_controller
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
@@ -12,14 +16,28 @@ import (
 
 type BidController struct {
 	bidUseCase bid_usecase.BidUseCaseInterface
+	retryAfter time.Duration
 }
 
 func NewBidController(bidUseCase bid_usecase.BidUseCaseInterface) *BidController {
 	return &BidController{
 		bidUseCase: bidUseCase,
+		retryAfter: getBidRetryAfter(),
 	}
 }
 
+// getBidRetryAfter returns how long clients are told to wait when the bid pipeline is saturated.
+// Rate limited bids are told the wait computed by the limiter instead
+func getBidRetryAfter() time.Duration {
+	retryAfter := os.Getenv("BID_RETRY_AFTER")
+	duration, err := time.ParseDuration(retryAfter)
+	if err != nil || duration < time.Second {
+		return time.Second
+	}
+
+	return duration
+}
+
 func (u *BidController) CreateBid(c *gin.Context) {
 	var bidInputDTO bid_usecase.BidInputDTO
 
@@ -34,6 +52,16 @@ func (u *BidController) CreateBid(c *gin.Context) {
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
 
+		if restErr.Code == http.StatusServiceUnavailable || restErr.Code == http.StatusTooManyRequests {
+			retryAfter := u.retryAfter
+			if restErr.RetryAfter > 0 {
+				retryAfter = restErr.RetryAfter
+			}
+
+			// Retry-After is in whole seconds, rounded up so a retry isn't rejected again
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+
 		c.JSON(restErr.Code, restErr)
 		return
 	}
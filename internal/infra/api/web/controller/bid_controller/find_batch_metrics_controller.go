@@ -0,0 +1,12 @@
+package bid_controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (u *BidController) FindBatchMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, u.bidUseCase.FindBatchMetrics(context.Background()))
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/validation"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/bid_usecase"
 )
 
 func (u *BidController) FindBidByAuctionId(c *gin.Context) {
@@ -22,12 +24,20 @@ func (u *BidController) FindBidByAuctionId(c *gin.Context) {
 		return
 	}
 
-	bidOutputList, err := u.bidUseCase.FindBidByAuctionId(context.Background(), auctionId)
+	var pageInputDTO bid_usecase.BidPageInputDTO
+	if err := c.ShouldBindQuery(&pageInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	bidPage, err := u.bidUseCase.FindBidByAuctionId(
+		context.Background(), auctionId, c.Query("currency"), pageInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
 		return
 	}
 
-	c.JSON(http.StatusOK, bidOutputList)
+	c.JSON(http.StatusOK, bidPage)
 }
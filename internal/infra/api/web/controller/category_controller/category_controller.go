@@ -0,0 +1,97 @@
+package category_controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/validation"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/category_usecase"
+)
+
+type CategoryController struct {
+	categoryUseCase category_usecase.CategoryUseCaseInterface
+}
+
+func NewCategoryController(categoryUseCase category_usecase.CategoryUseCaseInterface) *CategoryController {
+	return &CategoryController{
+		categoryUseCase: categoryUseCase,
+	}
+}
+
+func (cc *CategoryController) FindCategoryTree(c *gin.Context) {
+	categories, err := cc.categoryUseCase.FindCategoryTree(context.Background())
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (cc *CategoryController) FindCategoryById(c *gin.Context) {
+	category, err := cc.categoryUseCase.FindCategoryById(context.Background(), c.Param("categoryId"))
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (cc *CategoryController) CreateCategory(c *gin.Context) {
+	var categoryInputDTO category_usecase.CategoryInputDTO
+	if err := c.ShouldBindJSON(&categoryInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	category, err := cc.categoryUseCase.CreateCategory(context.Background(), categoryInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+func (cc *CategoryController) UpdateCategory(c *gin.Context) {
+	var categoryInputDTO category_usecase.CategoryUpdateInputDTO
+	if err := c.ShouldBindJSON(&categoryInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	category, err := cc.categoryUseCase.UpdateCategory(
+		context.Background(), c.Param("categoryId"), categoryInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (cc *CategoryController) DeleteCategory(c *gin.Context) {
+	if err := cc.categoryUseCase.DeleteCategory(context.Background(), c.Param("categoryId")); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
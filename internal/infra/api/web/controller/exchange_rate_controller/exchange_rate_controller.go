@@ -0,0 +1,53 @@
+package exchange_rate_controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/validation"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/exchange_rate_usecase"
+)
+
+type ExchangeRateController struct {
+	exchangeRateUseCase exchange_rate_usecase.ExchangeRateUseCaseInterface
+}
+
+func NewExchangeRateController(
+	exchangeRateUseCase exchange_rate_usecase.ExchangeRateUseCaseInterface) *ExchangeRateController {
+	return &ExchangeRateController{
+		exchangeRateUseCase: exchangeRateUseCase,
+	}
+}
+
+func (e *ExchangeRateController) FindRateTable(c *gin.Context) {
+	rateTable, err := e.exchangeRateUseCase.FindRateTable(context.Background())
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, rateTable)
+}
+
+func (e *ExchangeRateController) UpdateRateTable(c *gin.Context) {
+	var rateTableInputDTO exchange_rate_usecase.RateTableInputDTO
+	if err := c.ShouldBindJSON(&rateTableInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if err := e.exchangeRateUseCase.UpdateRateTable(context.Background(), rateTableInputDTO); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
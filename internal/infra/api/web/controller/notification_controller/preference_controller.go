@@ -0,0 +1,78 @@
+package notification_controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/validation"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/notification_usecase"
+)
+
+type NotificationController struct {
+	notificationUseCase notification_usecase.NotificationUseCaseInterface
+}
+
+func NewNotificationController(
+	notificationUseCase notification_usecase.NotificationUseCaseInterface) *NotificationController {
+	return &NotificationController{
+		notificationUseCase: notificationUseCase,
+	}
+}
+
+func (n *NotificationController) FindPreferenceByUserId(c *gin.Context) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	preferenceData, err := n.notificationUseCase.FindPreferenceByUserId(context.Background(), userId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferenceData)
+}
+
+func (n *NotificationController) UpdatePreference(c *gin.Context) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	var preferenceInputDTO notification_usecase.PreferenceInputDTO
+	if err := c.ShouldBindJSON(&preferenceInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	err := n.notificationUseCase.UpdatePreference(context.Background(), userId, preferenceInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
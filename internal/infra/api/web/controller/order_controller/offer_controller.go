@@ -0,0 +1,100 @@
+package order_controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/validation"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/settlement_usecase"
+)
+
+func (o *OrderController) CreateSecondChanceOffer(c *gin.Context) {
+	auctionId, ok := validParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	var offerInputDTO settlement_usecase.SecondChanceOfferInputDTO
+	if err := c.ShouldBindJSON(&offerInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	offerData, err := o.settlementUseCase.CreateSecondChanceOffer(
+		context.Background(), auctionId, offerInputDTO.SellerId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, offerData)
+}
+
+func (o *OrderController) AcceptOffer(c *gin.Context) {
+	offerId, responseInputDTO, ok := bindOfferResponse(c)
+	if !ok {
+		return
+	}
+
+	orderData, err := o.settlementUseCase.AcceptOffer(context.Background(), offerId, responseInputDTO.UserId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderData)
+}
+
+func (o *OrderController) DeclineOffer(c *gin.Context) {
+	offerId, responseInputDTO, ok := bindOfferResponse(c)
+	if !ok {
+		return
+	}
+
+	offerData, err := o.settlementUseCase.DeclineOffer(context.Background(), offerId, responseInputDTO.UserId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, offerData)
+}
+
+func (o *OrderController) FindOffersByUserId(c *gin.Context) {
+	userId, ok := validParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	offers, err := o.settlementUseCase.FindOffersByUserId(context.Background(), userId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+func bindOfferResponse(c *gin.Context) (string, settlement_usecase.OfferResponseInputDTO, bool) {
+	var responseInputDTO settlement_usecase.OfferResponseInputDTO
+
+	offerId, ok := validParam(c, "offerId")
+	if !ok {
+		return "", responseInputDTO, false
+	}
+
+	if err := c.ShouldBindJSON(&responseInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+		c.JSON(restErr.Code, restErr)
+		return "", responseInputDTO, false
+	}
+
+	return offerId, responseInputDTO, true
+}
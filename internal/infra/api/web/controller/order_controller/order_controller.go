@@ -0,0 +1,85 @@
+package order_controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/settlement_usecase"
+)
+
+type OrderController struct {
+	settlementUseCase settlement_usecase.SettlementUseCaseInterface
+}
+
+func NewOrderController(settlementUseCase settlement_usecase.SettlementUseCaseInterface) *OrderController {
+	return &OrderController{
+		settlementUseCase: settlementUseCase,
+	}
+}
+
+func (o *OrderController) FindOrderById(c *gin.Context) {
+	orderId, ok := validParam(c, "orderId")
+	if !ok {
+		return
+	}
+
+	orderData, err := o.settlementUseCase.FindOrderById(context.Background(), orderId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderData)
+}
+
+func (o *OrderController) FindOrdersByUserId(c *gin.Context) {
+	userId, ok := validParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	orders, err := o.settlementUseCase.FindOrdersByBuyerId(context.Background(), userId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+func (o *OrderController) ConfirmPayment(c *gin.Context) {
+	orderId, ok := validParam(c, "orderId")
+	if !ok {
+		return
+	}
+
+	orderData, err := o.settlementUseCase.ConfirmPayment(context.Background(), orderId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderData)
+}
+
+func validParam(c *gin.Context, name string) (string, bool) {
+	value := c.Param(name)
+
+	if err := uuid.Validate(value); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   name,
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return value, true
+}
@@ -0,0 +1,103 @@
+package wallet_controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/api/web/validation"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/wallet_usecase"
+)
+
+type WalletController struct {
+	walletUseCase wallet_usecase.WalletUseCaseInterface
+}
+
+func NewWalletController(walletUseCase wallet_usecase.WalletUseCaseInterface) *WalletController {
+	return &WalletController{
+		walletUseCase: walletUseCase,
+	}
+}
+
+func (w *WalletController) FindWallets(c *gin.Context) {
+	userId, ok := validUserId(c)
+	if !ok {
+		return
+	}
+
+	wallets, err := w.walletUseCase.FindWallets(context.Background(), userId)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallets)
+}
+
+func (w *WalletController) Deposit(c *gin.Context) {
+	userId, ok := validUserId(c)
+	if !ok {
+		return
+	}
+
+	var depositInputDTO wallet_usecase.AmountInputDTO
+	if err := c.ShouldBindJSON(&depositInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if err := w.walletUseCase.Deposit(context.Background(), userId, depositInputDTO); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusCreated)
+}
+
+func (w *WalletController) UpdateCreditLimit(c *gin.Context) {
+	userId, ok := validUserId(c)
+	if !ok {
+		return
+	}
+
+	var creditLimitInputDTO wallet_usecase.AmountInputDTO
+	if err := c.ShouldBindJSON(&creditLimitInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if err := w.walletUseCase.UpdateCreditLimit(context.Background(), userId, creditLimitInputDTO); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func validUserId(c *gin.Context) (string, bool) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return userId, true
+}
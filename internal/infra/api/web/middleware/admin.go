@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+)
+
+// AdminToken only lets through requests carrying "Authorization: Bearer <token>". When no
+// token is configured every request is rejected, so admin routes are never left open
+func AdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !found ||
+			subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			restErr := rest_err.NewUnauthorizedError("A valid admin token is required")
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetAdminToken returns the token expected by AdminToken
+func GetAdminToken() string {
+	return os.Getenv("ADMIN_TOKEN")
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestAdminToken tests that only requests with the configured token reach the handler
+func TestAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		token         string
+		authorization string
+		expected      int
+	}{
+		{"secret", "Bearer secret", http.StatusOK},
+		{"secret", "Bearer wrong", http.StatusUnauthorized},
+		{"secret", "secret", http.StatusUnauthorized},
+		{"secret", "", http.StatusUnauthorized},
+		{"", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, testCase := range testCases {
+		router := gin.New()
+		router.PUT("/admin", AdminToken(testCase.token), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		request := httptest.NewRequest(http.MethodPut, "/admin", nil)
+		if testCase.authorization != "" {
+			request.Header.Set("Authorization", testCase.authorization)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.expected {
+			t.Errorf("Authorization %q: esperado status %d, recebido %d",
+				testCase.authorization, testCase.expected, recorder.Code)
+		}
+	}
+}
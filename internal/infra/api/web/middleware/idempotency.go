@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/rest_err"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/idempotency_entity"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	idempotencyRetryAfter    = "1"
+	// idempotencyReservationTTL bounds how long a key stays in progress when its request never
+	// finishes, e.g. because the process crashed while handling it
+	idempotencyReservationTTL = time.Minute
+)
+
+// responseRecorder keeps a copy of the response so it can be stored for the key
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (rr *responseRecorder) Write(data []byte) (int, error) {
+	rr.body.Write(data)
+	return rr.ResponseWriter.Write(data)
+}
+
+func (rr *responseRecorder) WriteString(data string) (int, error) {
+	rr.body.WriteString(data)
+	return rr.ResponseWriter.WriteString(data)
+}
+
+// Idempotency makes a request carrying an Idempotency-Key safe to retry: the first request is
+// handled and its response stored, retries with the same payload get that response back and a
+// reused key with a different payload is rejected. Server errors, panics and anything else that
+// keeps the response from being stored release the key, so the request can be retried. Requests
+// without the header are handled as usual
+func Idempotency(
+	idempotencyRepository idempotency_entity.IdempotencyRepositoryInterface,
+	keyTTL time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			restErr := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+				Field:   IdempotencyKeyHeader,
+				Message: "Idempotency-Key must have at most 255 characters",
+			})
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			restErr := rest_err.NewBadRequestError("Error trying to read request body")
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped to the route, so the same key may be used on different endpoints
+		scopedKey := c.Request.Method + " " + c.FullPath() + ":" + key
+		requestHash := sha256.Sum256(body)
+
+		existing, reserveErr := idempotencyRepository.ReserveKey(context.Background(), idempotency_entity.Record{
+			Key:         scopedKey,
+			RequestHash: hex.EncodeToString(requestHash[:]),
+			ExpiresAt:   time.Now().Add(min(keyTTL, idempotencyReservationTTL)),
+		})
+		if reserveErr != nil {
+			restErr := rest_err.ConvertError(reserveErr)
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		if existing != nil {
+			replayRecord(c, existing, hex.EncodeToString(requestHash[:]))
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+
+		// Also runs when the handler panics, before the recovery middleware answers
+		completed := false
+		defer func() {
+			if !completed {
+				idempotencyRepository.ReleaseKey(context.Background(), scopedKey)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors and rate limited requests are worth retrying with the same key
+		if recorder.Status() >= http.StatusInternalServerError ||
+			recorder.Status() == http.StatusTooManyRequests {
+			return
+		}
+
+		completed = idempotencyRepository.CompleteKey(context.Background(), scopedKey,
+			recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes(),
+			time.Now().Add(keyTTL)) == nil
+	}
+}
+
+func replayRecord(c *gin.Context, record *idempotency_entity.Record, requestHash string) {
+	if record.RequestHash != requestHash {
+		restErr := rest_err.NewUnprocessableEntityError(
+			"Idempotency-Key was already used with a different request payload")
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return
+	}
+
+	if record.Status == idempotency_entity.InProgress {
+		restErr := rest_err.NewConflictError(
+			"A request with this Idempotency-Key is still being processed")
+		c.Header("Retry-After", idempotencyRetryAfter)
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	if len(record.ResponseBody) == 0 {
+		c.AbortWithStatus(record.StatusCode)
+		return
+	}
+
+	c.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+	c.Abort()
+}
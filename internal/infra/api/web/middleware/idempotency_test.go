@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/idempotency_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type fakeIdempotencyRepository struct {
+	mutex   sync.Mutex
+	records map[string]idempotency_entity.Record
+}
+
+func (fr *fakeIdempotencyRepository) ReserveKey(
+	ctx context.Context,
+	record idempotency_entity.Record) (*idempotency_entity.Record, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	if existing, ok := fr.records[record.Key]; ok {
+		return &existing, nil
+	}
+
+	record.Status = idempotency_entity.InProgress
+	fr.records[record.Key] = record
+	return nil, nil
+}
+
+func (fr *fakeIdempotencyRepository) CompleteKey(
+	ctx context.Context,
+	key string,
+	statusCode int,
+	contentType string,
+	responseBody []byte,
+	expiresAt time.Time) *internal_error.InternalError {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	record := fr.records[key]
+	record.Status = idempotency_entity.Completed
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.ResponseBody = responseBody
+	record.ExpiresAt = expiresAt
+	fr.records[key] = record
+	return nil
+}
+
+func (fr *fakeIdempotencyRepository) ReleaseKey(
+	ctx context.Context, key string) *internal_error.InternalError {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	delete(fr.records, key)
+	return nil
+}
+
+func setupIdempotencyRouter(handlerStatus *int) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	repository := &fakeIdempotencyRepository{records: make(map[string]idempotency_entity.Record)}
+
+	router := gin.New()
+	router.POST("/bid", Idempotency(repository, time.Hour), func(c *gin.Context) {
+		calls++
+		c.JSON(*handlerStatus, gin.H{"call": calls})
+	})
+
+	return router, &calls
+}
+
+func sendWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/bid", strings.NewReader(body))
+	request.Header.Set(IdempotencyKeyHeader, key)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// TestIdempotencyReplaysOriginalResponse tests that a retry returns the stored response without running the handler again
+func TestIdempotencyReplaysOriginalResponse(t *testing.T) {
+	status := http.StatusCreated
+	router, calls := setupIdempotencyRouter(&status)
+
+	first := sendWithKey(router, "key-1", `{"amount": 100}`)
+	retry := sendWithKey(router, "key-1", `{"amount": 100}`)
+
+	if *calls != 1 {
+		t.Errorf("Handler deveria ser chamado uma vez, chamado: %d", *calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Resposta repetida esperada: %d %s, recebida: %d %s",
+			first.Code, first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("A resposta repetida deveria ter o cabeçalho Idempotent-Replayed")
+	}
+}
+
+// TestIdempotencyRejectsDifferentPayload tests that a reused key with another payload is rejected
+func TestIdempotencyRejectsDifferentPayload(t *testing.T) {
+	status := http.StatusCreated
+	router, calls := setupIdempotencyRouter(&status)
+
+	sendWithKey(router, "key-1", `{"amount": 100}`)
+	reused := sendWithKey(router, "key-1", `{"amount": 200}`)
+
+	if reused.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Status esperado: %d, recebido: %d", http.StatusUnprocessableEntity, reused.Code)
+	}
+	if *calls != 1 {
+		t.Errorf("Handler deveria ser chamado uma vez, chamado: %d", *calls)
+	}
+}
+
+// TestIdempotencyReleasesKeyOnServerError tests that a server error lets the client retry with the same key
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	router, calls := setupIdempotencyRouter(&status)
+
+	sendWithKey(router, "key-1", `{"amount": 100}`)
+
+	status = http.StatusCreated
+	retry := sendWithKey(router, "key-1", `{"amount": 100}`)
+
+	if retry.Code != http.StatusCreated || *calls != 2 {
+		t.Errorf("Nova tentativa esperada com status %d, recebido: %d após %d chamadas",
+			http.StatusCreated, retry.Code, *calls)
+	}
+}
+
+// TestIdempotencyReleasesKeyWhenRateLimited tests that a rate limited request lets the client
+// retry with the same key once the limit allows it
+func TestIdempotencyReleasesKeyWhenRateLimited(t *testing.T) {
+	status := http.StatusTooManyRequests
+	router, calls := setupIdempotencyRouter(&status)
+
+	sendWithKey(router, "key-1", `{"amount": 100}`)
+
+	status = http.StatusCreated
+	retry := sendWithKey(router, "key-1", `{"amount": 100}`)
+
+	if retry.Code != http.StatusCreated || *calls != 2 {
+		t.Errorf("Nova tentativa esperada com status %d, recebido: %d após %d chamadas",
+			http.StatusCreated, retry.Code, *calls)
+	}
+}
+
+// TestIdempotencyReleasesKeyOnPanic tests that a handler panic caught by the recovery middleware
+// lets the client retry with the same key
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	repository := &fakeIdempotencyRepository{records: make(map[string]idempotency_entity.Record)}
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.POST("/bid", Idempotency(repository, time.Hour), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("falha simulada")
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	first := sendWithKey(router, "key-1", `{"amount": 100}`)
+	retry := sendWithKey(router, "key-1", `{"amount": 100}`)
+
+	if first.Code != http.StatusInternalServerError {
+		t.Errorf("Status esperado na primeira chamada: %d, recebido: %d", http.StatusInternalServerError, first.Code)
+	}
+	if retry.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("Nova tentativa esperada com status %d, recebido: %d após %d chamadas",
+			http.StatusCreated, retry.Code, calls)
+	}
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewAuctionStateCache returns the Redis backed cache when a client is given, so every
+// replica shares it, and the in-memory cache otherwise
+func NewAuctionStateCache(redisClient *redis.Client) auction_entity.AuctionStateCacheInterface {
+	if redisClient != nil {
+		return NewRedisAuctionCache(redisClient, GetAuctionCacheTTL())
+	}
+
+	return NewMemoryAuctionCache(GetAuctionCacheTTL(), GetAuctionCacheSize())
+}
+
+func GetAuctionCacheTTL() time.Duration {
+	cacheTTL := os.Getenv("AUCTION_CACHE_TTL")
+	duration, err := time.ParseDuration(cacheTTL)
+	if err != nil || duration <= 0 {
+		return 30 * time.Second
+	}
+
+	return duration
+}
+
+func GetAuctionCacheSize() int {
+	value, err := strconv.Atoi(os.Getenv("AUCTION_CACHE_SIZE"))
+	if err != nil || value <= 0 {
+		return 10000
+	}
+
+	return value
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestMemoryAuctionCacheExpiration tests that entries expire after the TTL
+func TestMemoryAuctionCacheExpiration(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	memoryCache := NewMemoryAuctionCache(time.Minute, 10)
+	memoryCache.now = func() time.Time { return now }
+
+	memoryCache.Set(ctx, "auction", auction_entity.AuctionState{Status: auction_entity.Active, EndTime: now})
+
+	if _, ok := memoryCache.Get(ctx, "auction"); !ok {
+		t.Fatal("Estado do leilão deveria estar no cache")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := memoryCache.Get(ctx, "auction"); ok {
+		t.Error("Estado do leilão deveria ter expirado")
+	}
+	if memoryCache.Len() != 0 {
+		t.Errorf("Cache deveria estar vazio, tamanho: %d", memoryCache.Len())
+	}
+}
+
+// TestMemoryAuctionCacheEviction tests that the least recently used entry is evicted
+func TestMemoryAuctionCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	state := auction_entity.AuctionState{Status: auction_entity.Active, EndTime: time.Now()}
+
+	memoryCache := NewMemoryAuctionCache(time.Minute, 2)
+	memoryCache.Set(ctx, "a", state)
+	memoryCache.Set(ctx, "b", state)
+
+	// Touches "a" so "b" becomes the least recently used entry
+	memoryCache.Get(ctx, "a")
+	memoryCache.Set(ctx, "c", state)
+
+	if _, ok := memoryCache.Get(ctx, "b"); ok {
+		t.Error("Entrada b deveria ter sido removida")
+	}
+	if _, ok := memoryCache.Get(ctx, "a"); !ok {
+		t.Error("Entrada a deveria continuar no cache")
+	}
+	if _, ok := memoryCache.Get(ctx, "c"); !ok {
+		t.Error("Entrada c deveria estar no cache")
+	}
+}
+
+// TestMemoryAuctionCacheInvalidate tests that lifecycle invalidations drop the entry
+func TestMemoryAuctionCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+
+	memoryCache := NewMemoryAuctionCache(time.Minute, 10)
+	memoryCache.Set(ctx, "auction", auction_entity.AuctionState{Status: auction_entity.Active, EndTime: time.Now()})
+	memoryCache.Invalidate(ctx, "auction")
+
+	if _, ok := memoryCache.Get(ctx, "auction"); ok {
+		t.Error("Estado do leilão deveria ter sido invalidado")
+	}
+}
+
+// TestRedisAuctionCache tests the shared cache against an in-process Redis server
+func TestRedisAuctionCache(t *testing.T) {
+	ctx := context.Background()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	redisCache := NewRedisAuctionCache(client, time.Minute)
+	otherReplicaCache := NewRedisAuctionCache(client, time.Minute)
+
+	endTime := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+	redisCache.Set(ctx, "auction", auction_entity.AuctionState{Status: auction_entity.Active, EndTime: endTime})
+
+	state, ok := otherReplicaCache.Get(ctx, "auction")
+	if !ok {
+		t.Fatal("Estado do leilão deveria ser compartilhado entre réplicas")
+	}
+	if state.Status != auction_entity.Active || !state.EndTime.Equal(endTime) {
+		t.Errorf("Estado esperado: %v %v, recebido: %v %v",
+			auction_entity.Active, endTime, state.Status, state.EndTime)
+	}
+
+	otherReplicaCache.Invalidate(ctx, "auction")
+	if _, ok := redisCache.Get(ctx, "auction"); ok {
+		t.Error("Invalidação deveria valer para todas as réplicas")
+	}
+
+	redisCache.Set(ctx, "expiring", auction_entity.AuctionState{Status: auction_entity.Active, EndTime: endTime})
+	server.FastForward(2 * time.Minute)
+	if _, ok := redisCache.Get(ctx, "expiring"); ok {
+		t.Error("Estado do leilão deveria ter expirado no Redis")
+	}
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+)
+
+type memoryCacheEntry struct {
+	auctionId string
+	state     auction_entity.AuctionState
+	expiresAt time.Time
+}
+
+// MemoryAuctionCache is a process local auction state cache with TTL expiration
+// and least recently used eviction once it reaches its maximum size
+type MemoryAuctionCache struct {
+	mutex   sync.Mutex
+	ttl     time.Duration
+	maxSize int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+func NewMemoryAuctionCache(ttl time.Duration, maxSize int) *MemoryAuctionCache {
+	return &MemoryAuctionCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func (mc *MemoryAuctionCache) Get(
+	ctx context.Context, auctionId string) (*auction_entity.AuctionState, bool) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	element, ok := mc.entries[auctionId]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*memoryCacheEntry)
+	if mc.now().After(entry.expiresAt) {
+		mc.removeElement(element)
+		return nil, false
+	}
+
+	mc.order.MoveToFront(element)
+	state := entry.state
+
+	return &state, true
+}
+
+func (mc *MemoryAuctionCache) Set(
+	ctx context.Context, auctionId string, state auction_entity.AuctionState) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	if element, ok := mc.entries[auctionId]; ok {
+		entry := element.Value.(*memoryCacheEntry)
+		entry.state = state
+		entry.expiresAt = mc.now().Add(mc.ttl)
+		mc.order.MoveToFront(element)
+		return
+	}
+
+	mc.entries[auctionId] = mc.order.PushFront(&memoryCacheEntry{
+		auctionId: auctionId,
+		state:     state,
+		expiresAt: mc.now().Add(mc.ttl),
+	})
+
+	for mc.maxSize > 0 && mc.order.Len() > mc.maxSize {
+		mc.removeElement(mc.order.Back())
+	}
+}
+
+func (mc *MemoryAuctionCache) Invalidate(ctx context.Context, auctionId string) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	if element, ok := mc.entries[auctionId]; ok {
+		mc.removeElement(element)
+	}
+}
+
+// Len returns the number of entries currently held, including expired ones not yet evicted
+func (mc *MemoryAuctionCache) Len() int {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	return mc.order.Len()
+}
+
+func (mc *MemoryAuctionCache) removeElement(element *list.Element) {
+	entry := mc.order.Remove(element).(*memoryCacheEntry)
+	delete(mc.entries, entry.auctionId)
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/redis/go-redis/v9"
+)
+
+type auctionStateRedis struct {
+	Status   auction_entity.AuctionStatus `json:"status"`
+	EndTime  int64                        `json:"end_time"`
+	Currency string                       `json:"currency"`
+}
+
+// RedisAuctionCache shares the auction state cache between replicas. Entries expire after the
+// TTL and eviction under memory pressure is left to the Redis maxmemory policy (e.g. allkeys-lru)
+type RedisAuctionCache struct {
+	client    *redis.Client
+	ttl       time.Duration
+	keyPrefix string
+}
+
+func NewRedisAuctionCache(client *redis.Client, ttl time.Duration) *RedisAuctionCache {
+	return &RedisAuctionCache{
+		client:    client,
+		ttl:       ttl,
+		keyPrefix: "auction:state:",
+	}
+}
+
+func (rc *RedisAuctionCache) Get(
+	ctx context.Context, auctionId string) (*auction_entity.AuctionState, bool) {
+	value, err := rc.client.Get(ctx, rc.keyPrefix+auctionId).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Error("Error trying to read auction state from redis", err)
+		}
+		return nil, false
+	}
+
+	var stateRedis auctionStateRedis
+	if err := json.Unmarshal(value, &stateRedis); err != nil {
+		logger.Error("Error trying to decode auction state from redis", err)
+		return nil, false
+	}
+
+	return &auction_entity.AuctionState{
+		Status:   stateRedis.Status,
+		EndTime:  time.Unix(stateRedis.EndTime, 0),
+		Currency: stateRedis.Currency,
+	}, true
+}
+
+func (rc *RedisAuctionCache) Set(
+	ctx context.Context, auctionId string, state auction_entity.AuctionState) {
+	value, err := json.Marshal(auctionStateRedis{
+		Status:   state.Status,
+		EndTime:  state.EndTime.Unix(),
+		Currency: state.Currency,
+	})
+	if err != nil {
+		logger.Error("Error trying to encode auction state for redis", err)
+		return
+	}
+
+	if err := rc.client.Set(ctx, rc.keyPrefix+auctionId, value, rc.ttl).Err(); err != nil {
+		logger.Error("Error trying to write auction state to redis", err)
+	}
+}
+
+func (rc *RedisAuctionCache) Invalidate(ctx context.Context, auctionId string) {
+	if err := rc.client.Del(ctx, rc.keyPrefix+auctionId).Err(); err != nil {
+		logger.Error("Error trying to invalidate auction state in redis", err)
+	}
+}
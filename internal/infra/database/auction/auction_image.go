@@ -0,0 +1,77 @@
+package auction
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/image_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// AddAuctionImages pushes the images in a single update, only while the auction has room for
+// all of them, so concurrent uploads can't go over the maximum
+func (ar *AuctionRepository) AddAuctionImages(
+	ctx context.Context, auctionId string, images []image_entity.Image) *internal_error.InternalError {
+	if len(images) == 0 {
+		return nil
+	}
+	if len(images) > image_entity.MaxImagesPerAuction {
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("Auction can have up to %d images", image_entity.MaxImagesPerAuction))
+	}
+
+	imagesMongo := make([]ImageMongo, 0, len(images))
+	for _, image := range images {
+		imagesMongo = append(imagesMongo, ImageMongo{
+			Id:          image.Id,
+			Key:         image.Key,
+			ContentType: image.ContentType,
+			Size:        image.Size,
+			Timestamp:   image.Timestamp.Unix(),
+		})
+	}
+
+	filter := bson.M{
+		"_id": auctionId,
+		fmt.Sprintf("images.%d", image_entity.MaxImagesPerAuction-len(images)): bson.M{"$exists": false},
+	}
+	update := bson.M{"$push": bson.M{"images": bson.M{"$each": imagesMongo}}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error("Error trying to add auction images", err)
+		return internal_error.NewInternalServerError("Error trying to add auction images")
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := ar.FindAuctionById(ctx, auctionId); err != nil {
+			return err
+		}
+
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("Auction can have up to %d images", image_entity.MaxImagesPerAuction))
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) RemoveAuctionImage(
+	ctx context.Context, auctionId, imageId string) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionId, "images.id": imageId}
+	update := bson.M{"$pull": bson.M{"images": bson.M{"id": imageId}}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error("Error trying to remove auction image", err)
+		return internal_error.NewInternalServerError("Error trying to remove auction image")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Image %s not found in auction %s", imageId, auctionId))
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package auction
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LeaseEntityMongo is the claim an instance holds over the closing of an auction
+type LeaseEntityMongo struct {
+	Id        string `bson:"_id"`
+	Holder    string `bson:"holder"`
+	ExpiresAt int64  `bson:"expires_at"`
+}
+
+// getClosingLeaseTTL returns how long a closing claim is valid before another instance may take over
+func getClosingLeaseTTL() time.Duration {
+	leaseTTL := os.Getenv("CLOSING_LEASE_TTL")
+	duration, err := time.ParseDuration(leaseTTL)
+	if err != nil || duration <= 0 {
+		return 30 * time.Second
+	}
+
+	return duration
+}
+
+// acquireClosingLease claims the closing of the auction for this instance. The claim succeeds
+// when nobody holds it, when it already belongs to this instance or when the previous holder's
+// lease expired, e.g. because that instance crashed before finishing
+func (ar *AuctionRepository) acquireClosingLease(
+	ctx context.Context, auctionId string) (bool, *internal_error.InternalError) {
+	now := time.Now()
+
+	filter := bson.M{
+		"_id": auctionId,
+		"$or": bson.A{
+			bson.M{"holder": ar.instanceId},
+			bson.M{"expires_at": bson.M{"$lte": now.UnixMilli()}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"holder":     ar.instanceId,
+			"expires_at": now.Add(ar.leaseTTL).UnixMilli(),
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := ar.LeaseCollection.UpdateOne(ctx, filter, update, opts); err != nil {
+		// The upsert collides with the _id of a lease that is held by another instance
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+
+		logger.Error("Error trying to acquire auction closing lease", err)
+		return false, internal_error.NewInternalServerError("Error trying to acquire auction closing lease")
+	}
+
+	return true, nil
+}
+
+// keepClosingLease renews the closing lease every third of its TTL while the auction is being
+// closed, settled and notified, so a slow closing isn't taken over by another instance. The
+// returned context is cancelled when the lease is lost, stopping the work; stop ends the renewal
+func (ar *AuctionRepository) keepClosingLease(
+	ctx context.Context, auctionId string) (leaseCtx context.Context, stop func()) {
+	return keepRenewing(ctx, ar.leaseTTL/3, func(ctx context.Context) bool {
+		renewed, err := ar.acquireClosingLease(ctx, auctionId)
+		if err == nil && !renewed {
+			logger.Error("Auction closing lease was lost", nil, zap.String("auctionId", auctionId))
+			return false
+		}
+
+		return true
+	})
+}
+
+// keepRenewing calls renew every interval until stop is called. renew reports whether the claim
+// is still held; once it isn't, the returned context is cancelled, stopping the work done
+// under the claim. Failed renewals that can't tell whether the claim was lost are retried
+func keepRenewing(
+	ctx context.Context,
+	interval time.Duration,
+	renew func(ctx context.Context) bool) (renewCtx context.Context, stop func()) {
+	renewCtx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				held := renew(renewCtx)
+				if renewCtx.Err() != nil {
+					return
+				}
+				if !held {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return renewCtx, cancel
+}
+
+// releaseClosingLease drops the claim once the auction was finalized
+func (ar *AuctionRepository) releaseClosingLease(ctx context.Context, auctionId string) {
+	filter := bson.M{"_id": auctionId, "holder": ar.instanceId}
+	if _, err := ar.LeaseCollection.DeleteOne(ctx, filter); err != nil {
+		logger.Error("Error trying to release auction closing lease", err,
+			zap.String("auctionId", auctionId))
+	}
+}
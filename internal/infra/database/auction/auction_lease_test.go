@@ -0,0 +1,44 @@
+package auction
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestKeepRenewing tests that a claim is renewed while the work runs and that the work is
+// stopped once the claim is lost
+func TestKeepRenewing(t *testing.T) {
+	var renewals atomic.Int32
+	renewCtx, stop := keepRenewing(context.Background(), 10*time.Millisecond, func(ctx context.Context) bool {
+		return renewals.Add(1) < 3
+	})
+	defer stop()
+
+	select {
+	case <-renewCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("O contexto deveria ser cancelado ao perder a reserva")
+	}
+
+	if count := renewals.Load(); count != 3 {
+		t.Errorf("Esperadas 3 renovações, recebidas %d", count)
+	}
+
+	var held atomic.Int32
+	renewCtx, stop = keepRenewing(context.Background(), 10*time.Millisecond, func(ctx context.Context) bool {
+		held.Add(1)
+		return true
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	if renewCtx.Err() != nil || held.Load() == 0 {
+		t.Error("A reserva mantida deveria ser renovada sem cancelar o contexto")
+	}
+
+	stop()
+	if renewCtx.Err() == nil {
+		t.Error("Parar a renovação deveria cancelar o contexto")
+	}
+}
@@ -0,0 +1,286 @@
+package auction
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReminderEntityMongo records a reminder window of an auction. The reminder is claimed by an
+// instance until ClaimedUntil while it is dispatched and only marked Sent afterwards, so a
+// claim left behind by a crash expires and the reminder is sent again
+type ReminderEntityMongo struct {
+	Id           string `bson:"_id"`
+	AuctionId    string `bson:"auction_id"`
+	Window       string `bson:"window"`
+	Timestamp    int64  `bson:"timestamp"`
+	ClaimedBy    string `bson:"claimed_by,omitempty"`
+	ClaimedUntil int64  `bson:"claimed_until,omitempty"`
+	Sent         bool   `bson:"sent"`
+}
+
+// getReminderClaimTTL returns how long a reminder claim is valid before another instance may
+// send the reminder. The claim is renewed while the reminder is dispatched
+func getReminderClaimTTL() time.Duration {
+	claimTTL := os.Getenv("REMINDER_CLAIM_TTL")
+	duration, err := time.ParseDuration(claimTTL)
+	if err != nil || duration <= 0 {
+		return time.Minute
+	}
+
+	return duration
+}
+
+func reminderId(auctionId string, window time.Duration) string {
+	return fmt.Sprintf("%s:%s", auctionId, window)
+}
+
+// getReminderWindows parses the REMINDER_WINDOWS environment variable (e.g. "1h,10m")
+// and returns the windows sorted from the smallest to the largest
+func getReminderWindows() []time.Duration {
+	windowsStr := os.Getenv("REMINDER_WINDOWS")
+	if windowsStr == "" {
+		windowsStr = "1h,10m"
+	}
+
+	var windows []time.Duration
+	for _, value := range strings.Split(windowsStr, ",") {
+		window, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || window <= 0 {
+			logger.Info("Ignoring invalid reminder window", zap.String("window", value))
+			continue
+		}
+		windows = append(windows, window)
+	}
+
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+
+	return windows
+}
+
+// applicableReminderWindows returns the windows (ascending) that the remaining time already fell into
+func applicableReminderWindows(remaining time.Duration, windows []time.Duration) []time.Duration {
+	var applicable []time.Duration
+	for _, window := range windows {
+		if remaining <= window {
+			applicable = append(applicable, window)
+		}
+	}
+
+	return applicable
+}
+
+// findAuctionsEndingWithin returns the active auctions whose end_time falls in (now, now + window]
+func (ar *AuctionRepository) findAuctionsEndingWithin(
+	ctx context.Context, window time.Duration) ([]auction_entity.Auction, *internal_error.InternalError) {
+	now := time.Now()
+
+	filter := bson.M{
+		"status": auction_entity.Active,
+		"end_time": bson.M{
+			"$gt":  now.Unix(),
+			"$lte": now.Add(window).Unix(),
+		},
+	}
+
+	cursor, err := ar.Collection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error finding auctions ending soon", err)
+		return nil, internal_error.NewInternalServerError("Error finding auctions ending soon")
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.Error("Error decoding auctions ending soon", err)
+		return nil, internal_error.NewInternalServerError("Error decoding auctions ending soon")
+	}
+
+	var auctionsEntity []auction_entity.Auction
+	for _, auction := range auctionsMongo {
+		auctionsEntity = append(auctionsEntity, auction_entity.Auction{
+			Id:          auction.Id,
+			ProductName: auction.ProductName,
+			Category:    auction.Category,
+			Description: auction.Description,
+			Condition:   auction.Condition,
+			Currency:    auction.currency(),
+			Status:      auction.Status,
+			Timestamp:   time.Unix(auction.Timestamp, 0),
+			EndTime:     time.Unix(auction.EndTime, 0),
+		})
+	}
+
+	return auctionsEntity, nil
+}
+
+// claimReminder claims the reminder of the given window for this instance while it is sent.
+// It returns false when the reminder was already sent or another instance holds an unexpired
+// claim. Reminders recorded before claims expired have no claimed_until and count as sent
+func (ar *AuctionRepository) claimReminder(
+	ctx context.Context, auctionId string, window time.Duration) (bool, *internal_error.InternalError) {
+	now := time.Now()
+
+	filter := bson.M{
+		"_id":           reminderId(auctionId, window),
+		"sent":          bson.M{"$ne": true},
+		"claimed_until": bson.M{"$lte": now.UnixMilli()},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"auction_id":    auctionId,
+			"window":        window.String(),
+			"timestamp":     now.Unix(),
+			"claimed_by":    ar.instanceId,
+			"claimed_until": now.Add(ar.reminderClaimTTL).UnixMilli(),
+			"sent":          false,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := ar.ReminderCollection.UpdateOne(ctx, filter, update, opts); err != nil {
+		// The upsert collides with the _id of a reminder that was sent or is claimed
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+
+		logger.Error("Error trying to claim auction reminder", err)
+		return false, internal_error.NewInternalServerError("Error trying to claim auction reminder")
+	}
+
+	return true, nil
+}
+
+// keepReminderClaim renews the claim of the reminder every third of its TTL while it is
+// dispatched, so a reminder with many recipients isn't sent again by another instance. The
+// returned context is cancelled when the claim is lost, stopping the dispatch
+func (ar *AuctionRepository) keepReminderClaim(
+	ctx context.Context, auctionId string, window time.Duration) (claimCtx context.Context, stop func()) {
+	return keepRenewing(ctx, ar.reminderClaimTTL/3, func(ctx context.Context) bool {
+		filter := bson.M{
+			"_id":        reminderId(auctionId, window),
+			"claimed_by": ar.instanceId,
+			"sent":       bson.M{"$ne": true},
+		}
+		update := bson.M{
+			"$set": bson.M{"claimed_until": time.Now().Add(ar.reminderClaimTTL).UnixMilli()},
+		}
+
+		result, err := ar.ReminderCollection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			logger.Error("Error trying to renew auction reminder claim", err)
+			return true
+		}
+
+		if result.MatchedCount == 0 {
+			logger.Error("Auction reminder claim was lost", nil, zap.String("auctionId", auctionId))
+			return false
+		}
+
+		return true
+	})
+}
+
+// markRemindersSent records the reminders of the given windows as sent
+func (ar *AuctionRepository) markRemindersSent(
+	ctx context.Context, auctionId string, windows []time.Duration) *internal_error.InternalError {
+	now := time.Now().Unix()
+
+	for _, window := range windows {
+		update := bson.M{
+			"$set": bson.M{"sent": true},
+			"$setOnInsert": bson.M{
+				"auction_id": auctionId,
+				"window":     window.String(),
+				"timestamp":  now,
+			},
+		}
+
+		opts := options.Update().SetUpsert(true)
+		if _, err := ar.ReminderCollection.UpdateOne(
+			ctx, bson.M{"_id": reminderId(auctionId, window)}, update, opts); err != nil {
+			logger.Error("Error trying to record auction reminder", err)
+			return internal_error.NewInternalServerError("Error trying to record auction reminder")
+		}
+	}
+
+	return nil
+}
+
+// sendEndingSoonReminders emits, for every auction inside a reminder window, the reminder of
+// the smallest window it fell into. Larger windows are recorded as sent along with it, so an
+// auction created close to its end doesn't produce a burst of reminders
+func (ar *AuctionRepository) sendEndingSoonReminders(ctx context.Context, windows []time.Duration) {
+	if len(windows) == 0 {
+		return
+	}
+
+	auctions, err := ar.findAuctionsEndingWithin(ctx, windows[len(windows)-1])
+	if err != nil {
+		logger.Error("Error finding auctions ending soon in worker", err)
+		return
+	}
+
+	for _, auction := range auctions {
+		applicable := applicableReminderWindows(time.Until(auction.EndTime), windows)
+		if len(applicable) == 0 {
+			continue
+		}
+
+		window := applicable[0]
+		claimed, err := ar.claimReminder(ctx, auction.Id, window)
+		if err != nil || !claimed {
+			continue
+		}
+
+		logger.Info("Sending ending soon reminder",
+			zap.String("auctionId", auction.Id), zap.String("window", window.String()))
+
+		if ar.Notifier != nil {
+			claimCtx, stopRenewing := ar.keepReminderClaim(ctx, auction.Id, window)
+			ar.Notifier.NotifyAuctionEndingSoon(claimCtx, auction, window)
+			stopRenewing()
+		}
+
+		// Left unmarked, the claim expires and the reminder is sent again
+		_ = ar.markRemindersSent(ctx, auction.Id, applicable)
+	}
+}
+
+// StartAuctionReminderWorker starts the worker that emits "ending soon" reminders
+func (ar *AuctionRepository) StartAuctionReminderWorker(ctx context.Context) {
+	logger.Info("Starting auction reminder worker")
+
+	checkInterval := 1 * time.Minute
+	if interval := os.Getenv("WORKER_CHECK_INTERVAL"); interval != "" {
+		if parsedInterval, err := time.ParseDuration(interval); err == nil {
+			checkInterval = parsedInterval
+		}
+	}
+
+	windows := getReminderWindows()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Auction reminder worker stopped")
+			return
+		default:
+			ar.sendEndingSoonReminders(ctx, windows)
+
+			time.Sleep(checkInterval)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package auction
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestGetReminderWindows tests the parsing and ordering of the reminder windows
+func TestGetReminderWindows(t *testing.T) {
+	os.Setenv("REMINDER_WINDOWS", "10m, 1h,invalid,-5m")
+	defer os.Unsetenv("REMINDER_WINDOWS")
+
+	windows := getReminderWindows()
+
+	expected := []time.Duration{10 * time.Minute, time.Hour}
+	if len(windows) != len(expected) {
+		t.Fatalf("Janelas esperadas: %v, recebidas: %v", expected, windows)
+	}
+	for i := range expected {
+		if windows[i] != expected[i] {
+			t.Errorf("Janela %d esperada: %v, recebida: %v", i, expected[i], windows[i])
+		}
+	}
+}
+
+// TestApplicableReminderWindows tests which windows an auction already fell into
+func TestApplicableReminderWindows(t *testing.T) {
+	windows := []time.Duration{10 * time.Minute, time.Hour}
+
+	testCases := []struct {
+		remaining time.Duration
+		expected  []time.Duration
+	}{
+		{remaining: 2 * time.Hour, expected: nil},
+		{remaining: 45 * time.Minute, expected: []time.Duration{time.Hour}},
+		{remaining: 5 * time.Minute, expected: []time.Duration{10 * time.Minute, time.Hour}},
+	}
+
+	for _, testCase := range testCases {
+		applicable := applicableReminderWindows(testCase.remaining, windows)
+		if len(applicable) != len(testCase.expected) {
+			t.Errorf("Para %v restantes, esperado: %v, recebido: %v",
+				testCase.remaining, testCase.expected, applicable)
+			continue
+		}
+		for i := range applicable {
+			if applicable[i] != testCase.expected[i] {
+				t.Errorf("Para %v restantes, esperado: %v, recebido: %v",
+					testCase.remaining, testCase.expected, applicable)
+			}
+		}
+	}
+}
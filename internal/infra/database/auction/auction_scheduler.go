@@ -0,0 +1,199 @@
+package auction
+
+import (
+	"container/heap"
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// closingItem is an auction waiting to be closed at its end time
+type closingItem struct {
+	auctionId string
+	endTime   time.Time
+	index     int
+}
+
+// closingQueue is a min-heap of auctions ordered by end time
+type closingQueue []*closingItem
+
+func (cq closingQueue) Len() int { return len(cq) }
+
+func (cq closingQueue) Less(i, j int) bool { return cq[i].endTime.Before(cq[j].endTime) }
+
+func (cq closingQueue) Swap(i, j int) {
+	cq[i], cq[j] = cq[j], cq[i]
+	cq[i].index = i
+	cq[j].index = j
+}
+
+func (cq *closingQueue) Push(value any) {
+	item := value.(*closingItem)
+	item.index = len(*cq)
+	*cq = append(*cq, item)
+}
+
+func (cq *closingQueue) Pop() any {
+	old := *cq
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*cq = old[:n-1]
+	return item
+}
+
+// followUpQueueSize is how many closed auctions may wait for a follow-up worker before the
+// scheduler loop waits for one to free up
+const followUpQueueSize = 100
+
+// auctionClosingScheduler keeps the upcoming end times in memory so each auction is
+// closed at its exact end time instead of on the next polling cycle. Only the closing runs in
+// the scheduler loop; the work that follows it, such as settlement and notifications, runs on
+// a fixed number of follow-up workers, so a slow follow-up doesn't delay other due auctions
+type auctionClosingScheduler struct {
+	mutex           sync.Mutex
+	queue           closingQueue
+	items           map[string]*closingItem
+	wakeup          chan struct{}
+	followUps       chan func()
+	followUpWorkers int
+}
+
+func newAuctionClosingScheduler() *auctionClosingScheduler {
+	return &auctionClosingScheduler{
+		items:           make(map[string]*closingItem),
+		wakeup:          make(chan struct{}, 1),
+		followUps:       make(chan func(), followUpQueueSize),
+		followUpWorkers: getClosingFollowUpWorkers(),
+	}
+}
+
+// getClosingFollowUpWorkers returns how many closed auctions are settled and notified at once
+func getClosingFollowUpWorkers() int {
+	workers, err := strconv.Atoi(os.Getenv("CLOSING_FOLLOW_UP_WORKERS"))
+	if err != nil || workers <= 0 {
+		return 4
+	}
+
+	return workers
+}
+
+// Schedule adds the auction to the queue or moves it to its new end time
+func (cs *auctionClosingScheduler) Schedule(auctionId string, endTime time.Time) {
+	cs.mutex.Lock()
+	if item, ok := cs.items[auctionId]; ok {
+		item.endTime = endTime
+		heap.Fix(&cs.queue, item.index)
+	} else {
+		item := &closingItem{auctionId: auctionId, endTime: endTime}
+		heap.Push(&cs.queue, item)
+		cs.items[auctionId] = item
+	}
+	cs.mutex.Unlock()
+
+	// Wakes the scheduler loop up so it can rearm its timer
+	select {
+	case cs.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the earliest scheduled end time
+func (cs *auctionClosingScheduler) next() (time.Time, bool) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	if len(cs.queue) == 0 {
+		return time.Time{}, false
+	}
+
+	return cs.queue[0].endTime, true
+}
+
+// popDue removes and returns the ids of every auction whose end time is not after now
+func (cs *auctionClosingScheduler) popDue(now time.Time) []string {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+
+	var auctionIds []string
+	for len(cs.queue) > 0 && !cs.queue[0].endTime.After(now) {
+		item := heap.Pop(&cs.queue).(*closingItem)
+		delete(cs.items, item.auctionId)
+		auctionIds = append(auctionIds, item.auctionId)
+	}
+
+	return auctionIds
+}
+
+// run sleeps until the earliest end time, a new schedule or the next resync, whichever comes
+// first. closeAuction returns the follow-up of the closing, if any, which is handed off to the
+// follow-up workers
+func (cs *auctionClosingScheduler) run(
+	ctx context.Context,
+	resyncInterval time.Duration,
+	resync func(ctx context.Context),
+	closeAuction func(ctx context.Context, auctionId string) func()) {
+	for i := 0; i < cs.followUpWorkers; i++ {
+		go cs.runFollowUps(ctx)
+	}
+
+	resync(ctx)
+
+	resyncTicker := time.NewTicker(resyncInterval)
+	defer resyncTicker.Stop()
+
+	for {
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if endTime, ok := cs.next(); ok {
+			timer = time.NewTimer(time.Until(endTime))
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case <-cs.wakeup:
+		case <-resyncTicker.C:
+			resync(ctx)
+		case <-timerC:
+			for _, auctionId := range cs.popDue(time.Now()) {
+				if followUp := closeAuction(ctx, auctionId); followUp != nil {
+					cs.handOff(ctx, followUp)
+				}
+			}
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// handOff queues the follow-up for the workers. When the queue is full the loop waits for a
+// free slot, so the follow-ups of a burst of closings are bounded in memory
+func (cs *auctionClosingScheduler) handOff(ctx context.Context, followUp func()) {
+	select {
+	case cs.followUps <- followUp:
+	case <-ctx.Done():
+	}
+}
+
+// runFollowUps runs the queued follow-ups one at a time until the context is done. Follow-ups
+// still queued at shutdown are dropped; settlement is retried by the payment deadline worker
+func (cs *auctionClosingScheduler) runFollowUps(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case followUp := <-cs.followUps:
+			followUp()
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package auction
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestClosingSchedulerOrder tests that auctions come out of the scheduler ordered by end time
+func TestClosingSchedulerOrder(t *testing.T) {
+	scheduler := newAuctionClosingScheduler()
+	now := time.Now()
+
+	scheduler.Schedule("c", now.Add(3*time.Second))
+	scheduler.Schedule("a", now.Add(1*time.Second))
+	scheduler.Schedule("b", now.Add(2*time.Second))
+
+	// Extends "a" so it becomes the last one
+	scheduler.Schedule("a", now.Add(4*time.Second))
+
+	next, ok := scheduler.next()
+	if !ok || !next.Equal(now.Add(2*time.Second)) {
+		t.Errorf("Próximo fechamento esperado: %v, recebido: %v", now.Add(2*time.Second), next)
+	}
+
+	due := scheduler.popDue(now.Add(3 * time.Second))
+	if len(due) != 2 || due[0] != "b" || due[1] != "c" {
+		t.Fatalf("Leilões vencidos esperados: [b c], recebidos: %v", due)
+	}
+
+	due = scheduler.popDue(now.Add(10 * time.Second))
+	if len(due) != 1 || due[0] != "a" {
+		t.Fatalf("Leilões vencidos esperados: [a], recebidos: %v", due)
+	}
+
+	if _, ok := scheduler.next(); ok {
+		t.Error("O agendador deveria estar vazio")
+	}
+}
+
+// TestClosingSchedulerRun tests that the scheduler closes an auction at its end time
+// and picks up auctions scheduled while it is already waiting
+func TestClosingSchedulerRun(t *testing.T) {
+	scheduler := newAuctionClosingScheduler()
+
+	closed := make(chan string, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go scheduler.run(ctx, time.Hour,
+		func(ctx context.Context) {},
+		func(ctx context.Context, auctionId string) func() {
+			closed <- auctionId
+			return nil
+		})
+
+	start := time.Now()
+	scheduler.Schedule("late", start.Add(2*time.Second))
+	scheduler.Schedule("early", start.Add(200*time.Millisecond))
+
+	select {
+	case auctionId := <-closed:
+		if auctionId != "early" {
+			t.Errorf("Leilão esperado: early, recebido: %s", auctionId)
+		}
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond || elapsed > time.Second {
+			t.Errorf("Leilão deveria ser fechado perto do seu horário de término, fechado após %v", elapsed)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("O leilão não foi fechado")
+	}
+}
+
+// TestClosingSchedulerSlowFollowUp tests that a slow follow-up, such as a notifier waiting on
+// the mail server, doesn't delay the closing of another auction due at the same time
+func TestClosingSchedulerSlowFollowUp(t *testing.T) {
+	scheduler := newAuctionClosingScheduler()
+
+	closed := make(chan string, 2)
+	notifierDone := make(chan struct{})
+	defer close(notifierDone)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go scheduler.run(ctx, time.Hour,
+		func(ctx context.Context) {},
+		func(ctx context.Context, auctionId string) func() {
+			closed <- auctionId
+			return func() { <-notifierDone }
+		})
+
+	start := time.Now()
+	scheduler.Schedule("first", start.Add(100*time.Millisecond))
+	scheduler.Schedule("second", start.Add(100*time.Millisecond))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-closed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("O notificador lento atrasou o fechamento do outro leilão")
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Leilões deveriam ser fechados perto do horário de término, fechados após %v", elapsed)
+	}
+}
@@ -0,0 +1,54 @@
+package auction
+
+import (
+	"context"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (ar *AuctionRepository) FindUnsettledAuctions(
+	ctx context.Context, closedBefore time.Time) ([]auction_entity.Auction, *internal_error.InternalError) {
+	filter := bson.M{
+		"status":     auction_entity.Completed,
+		"settled_at": bson.M{"$exists": false},
+		"closed_at":  bson.M{"$lte": closedBefore.Unix()},
+	}
+
+	cursor, err := ar.Collection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to find unsettled auctions", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find unsettled auctions")
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.Error("Error trying to decode unsettled auctions", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find unsettled auctions")
+	}
+
+	auctions := make([]auction_entity.Auction, 0, len(auctionsMongo))
+	for _, auctionMongo := range auctionsMongo {
+		auctions = append(auctions, *auctionMongo.toAuctionEntity())
+	}
+
+	return auctions, nil
+}
+
+// MarkAuctionSettled records that the order of the auction was created and its holds settled
+func (ar *AuctionRepository) MarkAuctionSettled(
+	ctx context.Context, id string) *internal_error.InternalError {
+	update := bson.M{"$set": bson.M{"settled_at": time.Now().Unix()}}
+	if _, err := ar.Collection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		logger.Error("Error trying to mark auction as settled", err, zap.String("auctionId", id))
+		return internal_error.NewInternalServerError("Error trying to mark auction as settled")
+	}
+
+	return nil
+}
@@ -2,16 +2,24 @@ package auction
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/timestamp"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 	"go.uber.org/zap"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // findExpiredAuctions queries the database for all auctions with status = Active
@@ -45,6 +53,7 @@ func (ar *AuctionRepository) findExpiredAuctions(ctx context.Context) ([]auction
 			Category:    auction.Category,
 			Description: auction.Description,
 			Condition:   auction.Condition,
+			Currency:    auction.currency(),
 			Status:      auction.Status,
 			Timestamp:   time.Unix(auction.Timestamp, 0),
 			EndTime:     time.Unix(auction.EndTime, 0),
@@ -54,41 +63,294 @@ func (ar *AuctionRepository) findExpiredAuctions(ctx context.Context) ([]auction
 	return auctionsEntity, nil
 }
 
-// closeAuction receives an auction ID and executes an UPDATE in the database to change its status to Completed
+// closingBidMongo is the subset of a bid document needed to backfill the auction high bid
+type closingBidMongo struct {
+	Id        string               `bson:"_id"`
+	UserId    string               `bson:"user_id"`
+	AuctionId string               `bson:"auction_id"`
+	Amount    primitive.Decimal128 `bson:"amount"`
+	Currency  string               `bson:"currency"`
+	Timestamp int64                `bson:"timestamp"`
+}
+
+// backfillHighBid offers the highest stored bid to UpdateHighBid before closing. This covers
+// auctions created before the high bid was tracked and bids whose high bid update was lost.
+// With wallets, only a bid whose funds can be held may take the lead, so bids whose users
+// can't cover them are skipped in favor of the next best one
+func (ar *AuctionRepository) backfillHighBid(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	var auctionMongo AuctionEntityMongo
+	projection := options.FindOne().SetProjection(bson.M{"high_bid_id": 1})
+	if err := ar.Collection.FindOne(ctx, bson.M{"_id": auctionId}, projection).Decode(&auctionMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+
+		logger.Error("Error trying to find auction high bid while closing auction", err)
+		return internal_error.NewInternalServerError("Error trying to find auction high bid while closing auction")
+	}
+
+	filter := bson.M{"auction_id": auctionId}
+	opts := options.Find().SetSort(bson.D{
+		{Key: "amount", Value: -1},
+		{Key: "timestamp", Value: 1},
+		{Key: "_id", Value: 1},
+	})
+
+	cursor, err := ar.BidCollection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Error trying to find the highest bid while closing auction", err)
+		return internal_error.NewInternalServerError("Error trying to find the highest bid while closing auction")
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var bidMongo closingBidMongo
+		if err := cursor.Decode(&bidMongo); err != nil {
+			logger.Error("Error trying to decode the highest bid while closing auction", err)
+			return internal_error.NewInternalServerError("Error trying to find the highest bid while closing auction")
+		}
+
+		// The stored bids below the current high bid can't take the lead
+		if bidMongo.Id == auctionMongo.HighBidId {
+			return nil
+		}
+
+		amount, convertErr := money.FromDecimal128(bidMongo.Amount, bidMongo.Currency)
+		if convertErr != nil {
+			logger.Error("Error trying to read the highest bid amount while closing auction", convertErr)
+			return internal_error.NewInternalServerError("Error trying to read the highest bid amount while closing auction")
+		}
+
+		bid := bid_entity.Bid{
+			Id:        bidMongo.Id,
+			UserId:    bidMongo.UserId,
+			AuctionId: bidMongo.AuctionId,
+			Amount:    amount,
+			Timestamp: timestamp.FromMillis(bidMongo.Timestamp),
+		}
+
+		if ar.WalletRepository != nil {
+			holdErr := ar.WalletRepository.HoldFunds(ctx, wallet_entity.Hold{
+				UserId:    bid.UserId,
+				AuctionId: bid.AuctionId,
+				BidId:     bid.Id,
+				Amount:    bid.Amount,
+			})
+			if holdErr != nil && holdErr.Err == "bad_request" {
+				logger.Info("Skipping bid the user can't cover while closing auction",
+					zap.String("auctionId", auctionId), zap.String("bidId", bid.Id))
+				continue
+			}
+			if holdErr != nil {
+				return holdErr
+			}
+		}
+
+		tookLead, previousHighBid, err := ar.UpdateHighBid(ctx, bid)
+		if err != nil {
+			return err
+		}
+
+		if !tookLead {
+			ar.releaseBidHold(ctx, bid)
+		} else if previousHighBid != nil {
+			ar.releaseBidHold(ctx, *previousHighBid)
+		}
+
+		return nil
+	}
+
+	if err := cursor.Err(); err != nil {
+		logger.Error("Error trying to find the highest bid while closing auction", err)
+		return internal_error.NewInternalServerError("Error trying to find the highest bid while closing auction")
+	}
+
+	return nil
+}
+
+// releaseBidHold releases the funds held for a bid that doesn't lead the auction
+func (ar *AuctionRepository) releaseBidHold(ctx context.Context, bid bid_entity.Bid) {
+	if ar.WalletRepository == nil {
+		return
+	}
+
+	if err := ar.WalletRepository.ReleaseHold(ctx, bid.UserId, bid.AuctionId, bid.Id); err != nil {
+		logger.Error("Error trying to release bid hold", err, zap.String("bidId", bid.Id))
+	}
+}
+
+// closeAuction receives an auction ID and executes an UPDATE in the database to change its status to Completed.
+// The same update copies the current high bid into the winning bid, the winner and the final price and records
+// the closing time, so the result can't change once the auction is closed
 func (ar *AuctionRepository) closeAuction(ctx context.Context, auctionId string) *internal_error.InternalError {
-	filter := bson.M{"_id": auctionId}
-	update := bson.M{
-		"$set": bson.M{
-			"status": auction_entity.Completed,
-		},
+	if err := ar.backfillHighBid(ctx, auctionId); err != nil {
+		return err
 	}
 
-	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	// A high bid below the reserve price doesn't win. Auctions without reserve compare against a
+	// missing field, which sorts below every amount
+	reserveMet := bson.D{{Key: "$gte", Value: bson.A{"$high_bid_amount", "$reserve_price"}}}
+	winnerField := func(field string) bson.D {
+		return bson.D{{Key: "$cond", Value: bson.A{reserveMet, field, "$$REMOVE"}}}
+	}
+
+	filter := bson.M{"_id": auctionId, "status": auction_entity.Active}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{
+			{Key: "status", Value: auction_entity.Completed},
+			{Key: "closed_at", Value: time.Now().Unix()},
+			{Key: "winning_bid_id", Value: winnerField("$high_bid_id")},
+			{Key: "winner_user_id", Value: winnerField("$high_bid_user_id")},
+			{Key: "final_price", Value: winnerField("$high_bid_amount")},
+		}}},
+	}
+
+	updateResult, err := ar.Collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		logger.Error("Error trying to close auction", err)
 		return internal_error.NewInternalServerError("Error trying to close auction")
 	}
 
-	if result.MatchedCount == 0 {
-		logger.Error("Auction not found for closing", nil)
-		return internal_error.NewNotFoundError("Auction not found for closing")
+	if updateResult.MatchedCount == 0 {
+		logger.Error("Active auction not found for closing", nil)
+		return internal_error.NewNotFoundError("Active auction not found for closing")
 	}
 
-	if result.ModifiedCount == 0 {
+	if updateResult.ModifiedCount == 0 {
 		logger.Error("Auction was not modified during closing", nil)
 		return internal_error.NewInternalServerError("Auction was not modified during closing")
 	}
 
+	ar.StateCache.Invalidate(ctx, auctionId)
+
 	logger.Info("Auction closed successfully", zap.String("auctionId", auctionId))
 
 	return nil
 }
 
-// StartAuctionClosingWorker starts the worker that checks and closes expired auctions
+// scheduleActiveAuctions loads the end time of every active auction into the closing scheduler.
+// It also picks up auctions created or extended by other instances
+func (ar *AuctionRepository) scheduleActiveAuctions(ctx context.Context) {
+	filter := bson.M{"status": auction_entity.Active}
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "end_time": 1})
+
+	cursor, err := ar.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Error finding active auctions to schedule", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionEntityMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.Error("Error decoding active auctions to schedule", err)
+		return
+	}
+
+	for _, auction := range auctionsMongo {
+		ar.closingScheduler.Schedule(auction.Id, time.Unix(auction.EndTime, 0))
+	}
+
+	logger.Info("Worker check", zap.String("scheduledAuctions", fmt.Sprintf("%d", len(auctionsMongo))))
+}
+
+// closeExpiredAuction closes the auction if it is still active and its stored end time has passed.
+// Auctions extended in the meantime are scheduled again for their new end time. Only the instance
+// holding the closing lease of the auction finalizes it. It returns the settlement and
+// notification of the closed auction, which keep the lease until they are done
+func (ar *AuctionRepository) closeExpiredAuction(ctx context.Context, auctionId string) func() {
+	acquired, leaseErr := ar.acquireClosingLease(ctx, auctionId)
+	if leaseErr != nil {
+		return nil
+	}
+
+	if !acquired {
+		logger.Info("Auction closing is held by another instance", zap.String("auctionId", auctionId))
+		return nil
+	}
+
+	// The release keeps the parent context, so it still runs when the lease is lost
+	leaseCtx, stopRenewing := ar.keepClosingLease(ctx, auctionId)
+	release := func() {
+		stopRenewing()
+		ar.releaseClosingLease(ctx, auctionId)
+	}
+
+	auction, err := ar.FindAuctionById(leaseCtx, auctionId)
+	if err != nil {
+		logger.Error("Error finding auction to close", err)
+		release()
+		return nil
+	}
+
+	if auction.Status != auction_entity.Active {
+		release()
+		return nil
+	}
+
+	if auction.EndTime.After(time.Now()) {
+		ar.closingScheduler.Schedule(auction.Id, auction.EndTime)
+		release()
+		return nil
+	}
+
+	if err := ar.closeAuction(leaseCtx, auction.Id); err != nil {
+		logger.Error("Error closing expired auction", err)
+		release()
+		return nil
+	}
+
+	return func() {
+		defer release()
+		ar.followUpClosedAuction(leaseCtx, *auction)
+	}
+}
+
+// followUpClosedAuction settles the closed auction and notifies its users
+func (ar *AuctionRepository) followUpClosedAuction(ctx context.Context, auction auction_entity.Auction) {
+	if ar.Settler != nil {
+		if err := ar.Settler.SettleAuction(ctx, auction.Id); err != nil {
+			logger.Error("Error settling closed auction", err, zap.String("auctionId", auction.Id))
+		}
+	}
+
+	if ar.Notifier != nil {
+		ar.Notifier.NotifyAuctionClosed(ctx, auction)
+	}
+}
+
+// ExtendAuctionEndTime moves the end time of an active auction and reschedules its closing
+func (ar *AuctionRepository) ExtendAuctionEndTime(
+	ctx context.Context, auctionId string, endTime time.Time) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionId, "status": auction_entity.Active}
+	update := bson.M{
+		"$set": bson.M{
+			"end_time": endTime.Unix(),
+		},
+	}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error("Error trying to extend auction end time", err)
+		return internal_error.NewInternalServerError("Error trying to extend auction end time")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError("Active auction not found for extension")
+	}
+
+	ar.StateCache.Invalidate(ctx, auctionId)
+	ar.closingScheduler.Schedule(auctionId, time.Unix(endTime.Unix(), 0))
+
+	return nil
+}
+
+// StartAuctionClosingWorker starts the worker that closes each auction at its end time
 func (ar *AuctionRepository) StartAuctionClosingWorker(ctx context.Context) {
 	logger.Info("Starting auction closing worker")
 
-	// Check interval (1 minute) - can be overridden for tests
+	// Resync interval (1 minute) - can be overridden for tests
 	checkInterval := 1 * time.Minute
 
 	// For tests, use a smaller interval if the environment variable is defined
@@ -99,37 +361,7 @@ func (ar *AuctionRepository) StartAuctionClosingWorker(ctx context.Context) {
 		}
 	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("Auction closing worker stopped")
-			return
-		default:
-			// Find expired auctions
-			expiredAuctions, err := ar.findExpiredAuctions(ctx)
-			if err != nil {
-				logger.Error("Error finding expired auctions in worker", err)
-				time.Sleep(checkInterval)
-				continue
-			}
-
-			// Logs for debug
-			logger.Info("Worker check", zap.String("expiredAuctionsFound", fmt.Sprintf("%d", len(expiredAuctions))))
-
-			// Close expired auctions
-			for _, auction := range expiredAuctions {
-				if err := ar.closeAuction(ctx, auction.Id); err != nil {
-					logger.Error("Error closing expired auction", err)
-				}
-			}
+	ar.closingScheduler.run(ctx, checkInterval, ar.scheduleActiveAuctions, ar.closeExpiredAuction)
 
-			// Log the number of closed auctions
-			if len(expiredAuctions) > 0 {
-				logger.Info("Closed expired auctions", zap.Int("count", len(expiredAuctions)))
-			}
-
-			// Wait before the next check
-			time.Sleep(checkInterval)
-		}
-	}
+	logger.Info("Auction closing worker stopped")
 }
@@ -2,11 +2,23 @@ package auction
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/image_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/cache"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -16,17 +28,118 @@ type AuctionEntityMongo struct {
 	Category    string                          `bson:"category"`
 	Description string                          `bson:"description"`
 	Condition   auction_entity.ProductCondition `bson:"condition"`
+	Currency    string                          `bson:"currency,omitempty"`
+	SellerId    string                          `bson:"seller_id,omitempty"`
 	Status      auction_entity.AuctionStatus    `bson:"status"`
 	Timestamp   int64                           `bson:"timestamp"`
 	EndTime     int64                           `bson:"end_time"`
+
+	Attributes map[string]string `bson:"attributes,omitempty"`
+	Images     []ImageMongo      `bson:"images,omitempty"`
+
+	ReservePrice primitive.Decimal128 `bson:"reserve_price,omitempty"`
+
+	HighBidId        string               `bson:"high_bid_id,omitempty"`
+	HighBidUserId    string               `bson:"high_bid_user_id,omitempty"`
+	HighBidAmount    primitive.Decimal128 `bson:"high_bid_amount,omitempty"`
+	HighBidTimestamp int64                `bson:"high_bid_timestamp,omitempty"`
+	BidCount         int64                `bson:"bid_count"`
+	// BidderCount is the number of users stored in "auction_bidders" for the auction
+	BidderCount int64 `bson:"bidder_count"`
+
+	WinningBidId string               `bson:"winning_bid_id,omitempty"`
+	WinnerUserId string               `bson:"winner_user_id,omitempty"`
+	FinalPrice   primitive.Decimal128 `bson:"final_price,omitempty"`
+	ClosedAt     int64                `bson:"closed_at,omitempty"`
+	SettledAt    int64                `bson:"settled_at,omitempty"`
 }
+
+// AuctionBidderMongo records that the user bid on the auction. The unique index on both fields
+// is created by the migrations
+type AuctionBidderMongo struct {
+	AuctionId string `bson:"auction_id"`
+	UserId    string `bson:"user_id"`
+}
+
+type ImageMongo struct {
+	Id          string `bson:"id"`
+	Key         string `bson:"key"`
+	ContentType string `bson:"content_type"`
+	Size        int64  `bson:"size"`
+	Timestamp   int64  `bson:"timestamp"`
+}
+
+// images returns the images of the auction
+func (am *AuctionEntityMongo) images() []image_entity.Image {
+	var images []image_entity.Image
+	for _, image := range am.Images {
+		images = append(images, image_entity.Image{
+			Id:          image.Id,
+			AuctionId:   am.Id,
+			Key:         image.Key,
+			ContentType: image.ContentType,
+			Size:        image.Size,
+			Timestamp:   time.Unix(image.Timestamp, 0),
+		})
+	}
+
+	return images
+}
+
+// currency returns the currency of the auction, the default one for auctions stored without it
+func (am *AuctionEntityMongo) currency() string {
+	if am.Currency == "" {
+		return money_entity.DefaultCurrency()
+	}
+
+	return am.Currency
+}
+
+// toMoney reads a stored amount of the auction. Missing amounts are returned as zero Money
+func (am *AuctionEntityMongo) toMoney(value primitive.Decimal128) money_entity.Money {
+	if value.IsZero() {
+		return money_entity.Money{}
+	}
+
+	amount, err := money.FromDecimal128(value, am.currency())
+	if err != nil {
+		logger.Error("Error trying to read auction amount", err, zap.String("auctionId", am.Id))
+		return money_entity.Money{}
+	}
+
+	return amount
+}
+
 type AuctionRepository struct {
-	Collection *mongo.Collection
+	Collection         *mongo.Collection
+	BidCollection      *mongo.Collection
+	ReminderCollection *mongo.Collection
+	LeaseCollection    *mongo.Collection
+	BidderCollection   *mongo.Collection
+	Notifier           notification_entity.NotificationDispatcherInterface
+	StateCache         auction_entity.AuctionStateCacheInterface
+	Settler            order_entity.AuctionSettlerInterface
+	// WalletRepository holds the funds of a bid that takes the lead while closing. Holds are
+	// ignored when nil
+	WalletRepository wallet_entity.WalletRepositoryInterface
+	closingScheduler *auctionClosingScheduler
+	instanceId       string
+	leaseTTL         time.Duration
+	reminderClaimTTL time.Duration
 }
 
 func NewAuctionRepository(database *mongo.Database) *AuctionRepository {
 	return &AuctionRepository{
-		Collection: database.Collection("auctions"),
+		Collection:         database.Collection("auctions"),
+		BidCollection:      database.Collection("bids"),
+		ReminderCollection: database.Collection("auction_reminders"),
+		LeaseCollection:    database.Collection("auction_closing_leases"),
+		BidderCollection:   database.Collection("auction_bidders"),
+		StateCache:         cache.NewMemoryAuctionCache(cache.GetAuctionCacheTTL(), cache.GetAuctionCacheSize()),
+		closingScheduler:   newAuctionClosingScheduler(),
+		instanceId:         uuid.New().String(),
+		leaseTTL:           getClosingLeaseTTL(),
+		reminderClaimTTL:   getReminderClaimTTL(),
 	}
 }
 
@@ -39,15 +152,43 @@ func (ar *AuctionRepository) CreateAuction(
 		Category:    auctionEntity.Category,
 		Description: auctionEntity.Description,
 		Condition:   auctionEntity.Condition,
+		Currency:    auctionEntity.Currency,
+		SellerId:    auctionEntity.SellerId,
 		Status:      auctionEntity.Status,
 		Timestamp:   auctionEntity.Timestamp.Unix(),
 		EndTime:     auctionEntity.EndTime.Unix(),
+		Attributes:  auctionEntity.Attributes,
+	}
+	if !auctionEntity.ReservePrice.IsZero() {
+		auctionEntityMongo.ReservePrice = money.ToDecimal128(auctionEntity.ReservePrice)
 	}
+
 	_, err := ar.Collection.InsertOne(ctx, auctionEntityMongo)
 	if err != nil {
 		logger.Error("Error trying to insert auction", err)
 		return internal_error.NewInternalServerError("Error trying to insert auction")
 	}
 
+	ar.StateCache.Set(ctx, auctionEntityMongo.Id, auction_entity.AuctionState{
+		Status:   auctionEntityMongo.Status,
+		EndTime:  time.Unix(auctionEntityMongo.EndTime, 0),
+		Currency: auctionEntityMongo.currency(),
+	})
+	ar.closingScheduler.Schedule(auctionEntityMongo.Id, time.Unix(auctionEntityMongo.EndTime, 0))
+
+	return nil
+}
+
+// DeleteAuction removes the auction and its cached state. The closing worker drops it once
+// it no longer finds the auction
+func (ar *AuctionRepository) DeleteAuction(
+	ctx context.Context, id string) *internal_error.InternalError {
+	if _, err := ar.Collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		logger.Error("Error trying to delete auction", err, zap.String("auctionId", id))
+		return internal_error.NewInternalServerError("Error trying to delete auction")
+	}
+
+	ar.StateCache.Invalidate(ctx, id)
+
 	return nil
 }
@@ -7,11 +7,15 @@ import (
 	"time"
 
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/category_entity"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// testCategory is the category of the auctions created by the tests
+var testCategory = &category_entity.Category{Id: "eletronicos", Name: "Eletrônicos"}
+
 // setupTestDatabase creates a test connection to MongoDB
 func setupTestDatabase(t *testing.T) (*mongo.Database, func()) {
 	ctx := context.Background()
@@ -65,9 +69,12 @@ func TestCreateAuctionSimple(t *testing.T) {
 	ctx := context.Background()
 	auction, err := auction_entity.CreateAuction(
 		"Produto Teste",
-		"Eletrônicos",
+		testCategory,
 		"Descrição de teste para validação básica",
 		auction_entity.New,
+		"BRL",
+		"",
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("Erro inesperado ao criar auction: %v", err)
@@ -123,9 +130,12 @@ func TestAuctionAutomaticClosing(t *testing.T) {
 	ctx := context.Background()
 	auction, err := auction_entity.CreateAuction(
 		"Produto Teste",
-		"Eletrônicos",
+		testCategory,
 		"Descrição de teste para validação do fechamento automático",
 		auction_entity.New,
+		"BRL",
+		"",
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("Erro inesperado ao criar auction: %v", err)
@@ -223,9 +233,12 @@ func TestAuctionNotExpiredYet(t *testing.T) {
 	ctx := context.Background()
 	auction, err := auction_entity.CreateAuction(
 		"Produto Teste Não Expirado",
-		"Eletrônicos",
+		testCategory,
 		"Descrição de teste para validação de leilão não expirado",
 		auction_entity.Used,
+		"BRL",
+		"",
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("Erro inesperado ao criar auction: %v", err)
@@ -283,9 +296,12 @@ func TestCreateAuctionEntity(t *testing.T) {
 	// Creates a test auction
 	auction, err := auction_entity.CreateAuction(
 		"Produto Teste",
-		"Eletrônicos",
+		testCategory,
 		"Descrição de teste para validação da entidade",
 		auction_entity.New,
+		"BRL",
+		"",
+		nil,
 	)
 
 	// Logs for debug
@@ -322,9 +338,12 @@ func TestBasicFunctionality(t *testing.T) {
 	// Creates a test auction
 	auction, err := auction_entity.CreateAuction(
 		"Produto Teste",
-		"Eletrônicos",
+		testCategory,
 		"Descrição de teste para validação básica",
 		auction_entity.New,
+		"BRL",
+		"",
+		nil,
 	)
 
 	// Logs for debug
@@ -369,9 +388,12 @@ func TestFindExpiredAuctions(t *testing.T) {
 	ctx := context.Background()
 	auction, err := auction_entity.CreateAuction(
 		"Produto Teste Expirado",
-		"Eletrônicos",
+		testCategory,
 		"Descrição de teste para validação de expiração",
 		auction_entity.New,
+		"BRL",
+		"",
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("Erro inesperado ao criar auction: %v", err)
@@ -432,9 +454,12 @@ func TestCloseAuction(t *testing.T) {
 	ctx := context.Background()
 	auction, err := auction_entity.CreateAuction(
 		"Produto Teste para Fechamento",
-		"Eletrônicos",
+		testCategory,
 		"Descrição de teste para validação de fechamento",
 		auction_entity.New,
+		"BRL",
+		"",
+		nil,
 	)
 	if err != nil {
 		t.Fatalf("Erro inesperado ao criar auction: %v", err)
@@ -473,5 +498,13 @@ func TestCloseAuction(t *testing.T) {
 		t.Errorf("Status esperado após fechamento: %v, recebido: %v", auction_entity.Completed, closedAuction.Status)
 	}
 
+	// Verifies that the closing result was stored
+	if closedAuction.ClosedAt.IsZero() {
+		t.Error("ClosedAt deveria ser preenchido no fechamento")
+	}
+	if closedAuction.WinningBidId != "" {
+		t.Errorf("Leilão sem lances não deveria ter vencedor, recebido: %s", closedAuction.WinningBidId)
+	}
+
 	t.Logf("Teste concluído com sucesso: leilão %s foi fechado", auction.Id)
 }
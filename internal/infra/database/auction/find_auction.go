@@ -2,14 +2,19 @@ package auction
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/pagination"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func (ar *AuctionRepository) FindAuctionById(
@@ -18,67 +23,185 @@ func (ar *AuctionRepository) FindAuctionById(
 
 	var auctionEntityMongo AuctionEntityMongo
 	if err := ar.Collection.FindOne(ctx, filter).Decode(&auctionEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Auction not found with this id = %s", id))
+		}
+
 		logger.Error(fmt.Sprintf("Error trying to find auction by id = %s", id), err)
 		return nil, internal_error.NewInternalServerError("Error trying to find auction by id")
 	}
 
-	return &auction_entity.Auction{
-		Id:          auctionEntityMongo.Id,
-		ProductName: auctionEntityMongo.ProductName,
-		Category:    auctionEntityMongo.Category,
-		Description: auctionEntityMongo.Description,
-		Condition:   auctionEntityMongo.Condition,
-		Status:      auctionEntityMongo.Status,
-		Timestamp:   time.Unix(auctionEntityMongo.Timestamp, 0),
-		EndTime:     time.Unix(auctionEntityMongo.EndTime, 0),
-	}, nil
+	return auctionEntityMongo.toAuctionEntity(), nil
+}
+
+// toAuctionEntity returns the auction with its stored bid summary
+func (am *AuctionEntityMongo) toAuctionEntity() *auction_entity.Auction {
+	auctionEntity := &auction_entity.Auction{
+		Id:            am.Id,
+		ProductName:   am.ProductName,
+		Category:      am.Category,
+		Description:   am.Description,
+		Condition:     am.Condition,
+		Currency:      am.currency(),
+		SellerId:      am.SellerId,
+		Status:        am.Status,
+		Timestamp:     time.Unix(am.Timestamp, 0),
+		EndTime:       time.Unix(am.EndTime, 0),
+		Attributes:    am.Attributes,
+		Images:        am.images(),
+		ReservePrice:  am.toMoney(am.ReservePrice),
+		HighBidId:     am.HighBidId,
+		HighBidUserId: am.HighBidUserId,
+		HighBidAmount: am.toMoney(am.HighBidAmount),
+		BidCount:      am.BidCount,
+		BidderCount:   am.BidderCount,
+		WinningBidId:  am.WinningBidId,
+		WinnerUserId:  am.WinnerUserId,
+		FinalPrice:    am.toMoney(am.FinalPrice),
+	}
+
+	if am.ClosedAt != 0 {
+		auctionEntity.ClosedAt = time.Unix(am.ClosedAt, 0)
+	}
+
+	return auctionEntity
+}
+
+// FindAuctionState returns the status and end time of the auction, served from the
+// state cache when possible
+func (ar *AuctionRepository) FindAuctionState(
+	ctx context.Context, id string) (*auction_entity.AuctionState, *internal_error.InternalError) {
+	if state, ok := ar.StateCache.Get(ctx, id); ok {
+		return state, nil
+	}
+
+	auctionEntity, err := ar.FindAuctionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	state := auction_entity.AuctionState{
+		Status:   auctionEntity.Status,
+		EndTime:  auctionEntity.EndTime,
+		Currency: auctionEntity.Currency,
+	}
+	ar.StateCache.Set(ctx, id, state)
+
+	return &state, nil
+}
+
+func (ar *AuctionRepository) CountAuctionsByCategory(
+	ctx context.Context, category string) (int64, *internal_error.InternalError) {
+	count, err := ar.Collection.CountDocuments(ctx, bson.M{"category": category})
+	if err != nil {
+		logger.Error("Error trying to count auctions by category", err)
+		return 0, internal_error.NewInternalServerError("Error trying to count auctions by category")
+	}
+
+	return count, nil
+}
+
+// auctionSortKeys are the fields each auction sort is based on
+var auctionSortKeys = map[auction_entity.AuctionSort]pagination.SortKey{
+	auction_entity.SortEndingSoonest: {Field: "end_time"},
+	auction_entity.SortNewest:        {Field: "timestamp", Descending: true},
+	auction_entity.SortHighestPrice:  {Field: "high_bid_amount", Descending: true},
+	auction_entity.SortMostBids:      {Field: "bid_count", Descending: true},
+}
+
+// sortValue returns the value the auction is sorted by, nil for auctions without bids when
+// sorting by price
+func (am *AuctionEntityMongo) sortValue(sort auction_entity.AuctionSort) any {
+	switch sort {
+	case auction_entity.SortEndingSoonest:
+		return am.EndTime
+	case auction_entity.SortHighestPrice:
+		if am.HighBidAmount.IsZero() {
+			return nil
+		}
+		return am.HighBidAmount
+	case auction_entity.SortMostBids:
+		return am.BidCount
+	default:
+		return am.Timestamp
+	}
 }
 
 func (repo *AuctionRepository) FindAuctions(
 	ctx context.Context,
 	status auction_entity.AuctionStatus,
-	category string,
-	productName string) ([]auction_entity.Auction, *internal_error.InternalError) {
+	categories []string,
+	productName string,
+	currency string,
+	sort auction_entity.AuctionSort,
+	page pagination_entity.PageRequest) ([]auction_entity.Auction, string, *internal_error.InternalError) {
 	filter := bson.M{}
 
 	if status != 0 {
 		filter["status"] = status
 	}
 
-	if category != "" {
-		filter["category"] = category
+	if len(categories) > 0 {
+		filter["category"] = bson.M{"$in": categories}
 	}
 
 	if productName != "" {
-		filter["productName"] = primitive.Regex{Pattern: productName, Options: "i"}
+		filter["product_name"] = primitive.Regex{Pattern: regexp.QuoteMeta(productName), Options: "i"}
+	}
+
+	if currency != "" {
+		filter["currency"] = currencyFilter(currency)
+	}
+
+	return repo.findAuctionPage(ctx, filter, sort, page)
+}
+
+// findAuctionPage returns the page of the auctions matching the filter in the order of the sort,
+// and the cursor of the next page
+func (repo *AuctionRepository) findAuctionPage(
+	ctx context.Context,
+	filter bson.M,
+	sort auction_entity.AuctionSort,
+	page pagination_entity.PageRequest) ([]auction_entity.Auction, string, *internal_error.InternalError) {
+	sortKey, ok := auctionSortKeys[sort]
+	if !ok {
+		return nil, "", internal_error.NewBadRequestError("Invalid sort option")
 	}
 
-	cursor, err := repo.Collection.Find(ctx, filter)
+	afterCursor, err := sortKey.AfterCursor(page)
 	if err != nil {
-		logger.Error("Error finding auctions", err)
-		return nil, internal_error.NewInternalServerError("Error finding auctions")
+		return nil, "", err
+	}
+	if afterCursor != nil {
+		and, _ := filter["$and"].(bson.A)
+		filter["$and"] = append(and, afterCursor)
+	}
+
+	cursor, findErr := repo.Collection.Find(ctx, filter, sortKey.FindOptions(page))
+	if findErr != nil {
+		logger.Error("Error finding auctions", findErr)
+		return nil, "", internal_error.NewInternalServerError("Error finding auctions")
 	}
 	defer cursor.Close(ctx)
 
 	var auctionsMongo []AuctionEntityMongo
 	if err := cursor.All(ctx, &auctionsMongo); err != nil {
 		logger.Error("Error decoding auctions", err)
-		return nil, internal_error.NewInternalServerError("Error decoding auctions")
+		return nil, "", internal_error.NewInternalServerError("Error decoding auctions")
+	}
+
+	var nextCursor string
+	if int64(len(auctionsMongo)) > page.Limit {
+		auctionsMongo = auctionsMongo[:page.Limit]
+		last := auctionsMongo[len(auctionsMongo)-1]
+		nextCursor = pagination.EncodeCursor(last.sortValue(sort), last.Id)
 	}
 
 	var auctionsEntity []auction_entity.Auction
 	for _, auction := range auctionsMongo {
-		auctionsEntity = append(auctionsEntity, auction_entity.Auction{
-			Id:          auction.Id,
-			ProductName: auction.ProductName,
-			Category:    auction.Category,
-			Status:      auction.Status,
-			Description: auction.Description,
-			Condition:   auction.Condition,
-			Timestamp:   time.Unix(auction.Timestamp, 0),
-			EndTime:     time.Unix(auction.EndTime, 0),
-		})
-	}
-
-	return auctionsEntity, nil
+		auctionsEntity = append(auctionsEntity, *auction.toAuctionEntity())
+	}
+
+	return auctionsEntity, nextCursor, nil
 }
@@ -0,0 +1,163 @@
+package auction
+
+import (
+	"context"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// searchFilter builds the filter of the search. The text is matched against the text index over
+// the product name and description
+func searchFilter(search auction_entity.AuctionSearch) bson.M {
+	filter := bson.M{}
+	and := bson.A{}
+
+	if search.Text != "" {
+		filter["$text"] = bson.M{"$search": search.Text}
+	}
+
+	if search.Status != nil {
+		filter["status"] = *search.Status
+	}
+
+	if len(search.Categories) > 0 {
+		filter["category"] = bson.M{"$in": search.Categories}
+	}
+
+	if search.Condition != 0 {
+		filter["condition"] = search.Condition
+	}
+
+	if search.SellerId != "" {
+		filter["seller_id"] = search.SellerId
+	}
+
+	if search.Currency != "" {
+		filter["currency"] = currencyFilter(search.Currency)
+	}
+
+	if !search.MinPrice.IsZero() {
+		and = append(and, bson.M{"high_bid_amount": bson.M{"$gte": money.ToDecimal128(search.MinPrice)}})
+	}
+
+	// Auctions without bids have no price yet, so they are below any max price
+	if !search.MaxPrice.IsZero() {
+		and = append(and, bson.M{"$or": bson.A{
+			bson.M{"high_bid_amount": bson.M{"$lte": money.ToDecimal128(search.MaxPrice)}},
+			bson.M{"high_bid_amount": bson.M{"$exists": false}},
+		}})
+	}
+
+	endTime := bson.M{}
+	if !search.EndingAfter.IsZero() {
+		endTime["$gte"] = search.EndingAfter.Unix()
+	}
+	if !search.EndingBefore.IsZero() {
+		endTime["$lte"] = search.EndingBefore.Unix()
+	}
+	if len(endTime) > 0 {
+		filter["end_time"] = endTime
+	}
+
+	if search.NoBids {
+		filter["bid_count"] = 0
+	}
+
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+
+	return filter
+}
+
+// currencyFilter matches the auctions in the currency. Auctions stored before auctions had a
+// currency are in the default one
+func currencyFilter(currency string) bson.M {
+	currencies := bson.A{currency}
+	if currency == money_entity.DefaultCurrency() {
+		currencies = append(currencies, nil)
+	}
+
+	return bson.M{"$in": currencies}
+}
+
+func (repo *AuctionRepository) SearchAuctions(
+	ctx context.Context,
+	search auction_entity.AuctionSearch,
+	sort auction_entity.AuctionSort,
+	page pagination_entity.PageRequest) ([]auction_entity.Auction, string, *internal_error.InternalError) {
+	return repo.findAuctionPage(ctx, searchFilter(search), sort, page)
+}
+
+// FindAuctionFacets counts the auctions matching the search per category and condition in a
+// single aggregation
+func (repo *AuctionRepository) FindAuctionFacets(
+	ctx context.Context,
+	search auction_entity.AuctionSearch) (*auction_entity.AuctionFacets, *internal_error.InternalError) {
+	countBy := func(field string) bson.A {
+		return bson.A{
+			bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: searchFilter(search)}},
+		{{Key: "$facet", Value: bson.M{
+			"categories": countBy("category"),
+			"conditions": countBy("condition"),
+		}}},
+	}
+
+	cursor, err := repo.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error("Error trying to count auction facets", err)
+		return nil, internal_error.NewInternalServerError("Error trying to count auction facets")
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Categories []struct {
+			Category string `bson:"_id"`
+			Count    int64  `bson:"count"`
+		} `bson:"categories"`
+		Conditions []struct {
+			Condition auction_entity.ProductCondition `bson:"_id"`
+			Count     int64                           `bson:"count"`
+		} `bson:"conditions"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.Error("Error trying to decode auction facets", err)
+		return nil, internal_error.NewInternalServerError("Error trying to decode auction facets")
+	}
+
+	facets := &auction_entity.AuctionFacets{
+		Categories: []auction_entity.CategoryFacet{},
+		Conditions: []auction_entity.ConditionFacet{},
+	}
+	if len(results) == 0 {
+		return facets, nil
+	}
+
+	for _, category := range results[0].Categories {
+		facets.Categories = append(facets.Categories, auction_entity.CategoryFacet{
+			Category: category.Category,
+			Count:    category.Count,
+		})
+	}
+	for _, condition := range results[0].Conditions {
+		facets.Conditions = append(facets.Conditions, auction_entity.ConditionFacet{
+			Condition: condition.Condition,
+			Count:     condition.Count,
+		})
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,51 @@
+package auction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestSearchFilter tests that each search field becomes its filter and empty fields are left out
+func TestSearchFilter(t *testing.T) {
+	if filter := searchFilter(auction_entity.AuctionSearch{}); len(filter) != 0 {
+		t.Errorf("Busca vazia não deveria filtrar, recebido %v", filter)
+	}
+
+	active := auction_entity.Active
+	endingBefore := time.Unix(2000, 0)
+	filter := searchFilter(auction_entity.AuctionSearch{
+		Text:         "notebook",
+		Status:       &active,
+		Condition:    auction_entity.Used,
+		Currency:     "USD",
+		MaxPrice:     money_entity.New(10000, "USD"),
+		EndingBefore: endingBefore,
+		NoBids:       true,
+	})
+
+	if filter["$text"] == nil {
+		t.Error("Esperado filtro de texto")
+	}
+	if filter["status"] != auction_entity.Active {
+		t.Errorf("Esperado filtro de leilões ativos, recebido %v", filter["status"])
+	}
+	if filter["condition"] != auction_entity.Used {
+		t.Errorf("Esperado filtro de condição, recebido %v", filter["condition"])
+	}
+	if filter["currency"] == nil {
+		t.Error("Esperado filtro de moeda")
+	}
+	if endTime, ok := filter["end_time"].(bson.M); !ok || endTime["$lte"] != endingBefore.Unix() || endTime["$gte"] != nil {
+		t.Errorf("Esperado apenas o limite superior do fim, recebido %v", filter["end_time"])
+	}
+	if filter["bid_count"] != 0 {
+		t.Errorf("Esperado filtro de leilões sem lances, recebido %v", filter["bid_count"])
+	}
+	if and, ok := filter["$and"].(bson.A); !ok || len(and) != 1 {
+		t.Errorf("Esperado apenas o preço máximo, recebido %v", filter["$and"])
+	}
+}
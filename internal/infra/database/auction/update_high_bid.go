@@ -0,0 +1,139 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpdateHighBid makes the bid the current high bid of the auction with a single conditional
+// update, so concurrent bids coming from any number of API replicas are serialized by the
+// database. A bid takes the lead when it is higher than the stored one or, on a tie, when it
+// was placed earlier (the bid id breaks exact timestamp ties). Bids for closed auctions or
+// placed after the end time never take the lead. The end time is stored in seconds, so the bid
+// is compared by the second it was placed in, rounded up.
+// It returns whether the bid took the lead and the bid it displaced, if any
+func (ar *AuctionRepository) UpdateHighBid(
+	ctx context.Context, bid bid_entity.Bid) (bool, *bid_entity.Bid, *internal_error.InternalError) {
+	bidTimestamp := bid.Timestamp.UnixMilli()
+	bidAmount := money.ToDecimal128(bid.Amount)
+
+	bidSecond := bid.Timestamp.Unix()
+	if bid.Timestamp.Nanosecond() > 0 {
+		bidSecond++
+	}
+
+	filter := bson.M{
+		"_id":      bid.AuctionId,
+		"status":   auction_entity.Active,
+		"end_time": bson.M{"$gte": bidSecond},
+		"$or": bson.A{
+			bson.M{"high_bid_id": bson.M{"$exists": false}},
+			bson.M{"high_bid_amount": bson.M{"$lt": bidAmount}},
+			bson.M{"high_bid_amount": bidAmount, "high_bid_timestamp": bson.M{"$gt": bidTimestamp}},
+			bson.M{"high_bid_amount": bidAmount, "high_bid_timestamp": bidTimestamp, "high_bid_id": bson.M{"$gt": bid.Id}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"high_bid_id":        bid.Id,
+			"high_bid_user_id":   bid.UserId,
+			"high_bid_amount":    bidAmount,
+			"high_bid_timestamp": bidTimestamp,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+	var previousMongo AuctionEntityMongo
+	if err := ar.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previousMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil, nil
+		}
+
+		logger.Error("Error trying to update the auction high bid", err)
+		return false, nil, internal_error.NewInternalServerError("Error trying to update the auction high bid")
+	}
+
+	if previousMongo.HighBidId == "" {
+		return true, nil, nil
+	}
+
+	return true, &bid_entity.Bid{
+		Id:        previousMongo.HighBidId,
+		UserId:    previousMongo.HighBidUserId,
+		AuctionId: previousMongo.Id,
+		Amount:    previousMongo.toMoney(previousMongo.HighBidAmount),
+		Timestamp: time.UnixMilli(previousMongo.HighBidTimestamp),
+	}, nil
+}
+
+// AddBids adds the newly stored bids to the bid count of the auction and their users to its
+// bidders. Bidders are kept in the "auction_bidders" collection, whose unique index on the
+// auction and user counts each user once, so the auction document stays the same size however
+// many users bid on it
+func (ar *AuctionRepository) AddBids(
+	ctx context.Context, auctionId string, bidderIds []string, count int) *internal_error.InternalError {
+	newBidders, err := ar.addBidders(ctx, auctionId, bidderIds)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$inc": bson.M{"bid_count": count, "bidder_count": newBidders}}
+	if _, err := ar.Collection.UpdateOne(ctx, bson.M{"_id": auctionId}, update); err != nil {
+		logger.Error("Error trying to update the auction bid summary", err)
+		return internal_error.NewInternalServerError("Error trying to update the auction bid summary")
+	}
+
+	return nil
+}
+
+// addBidders stores the users who bid on the auction and returns how many of them had not bid
+// on it before
+func (ar *AuctionRepository) addBidders(
+	ctx context.Context, auctionId string, bidderIds []string) (int, *internal_error.InternalError) {
+	bidders := make([]any, 0, len(bidderIds))
+	seen := make(map[string]bool, len(bidderIds))
+	for _, bidderId := range bidderIds {
+		if seen[bidderId] {
+			continue
+		}
+		seen[bidderId] = true
+		bidders = append(bidders, AuctionBidderMongo{AuctionId: auctionId, UserId: bidderId})
+	}
+	if len(bidders) == 0 {
+		return 0, nil
+	}
+
+	_, err := ar.BidderCollection.InsertMany(ctx, bidders, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return len(bidders), nil
+	}
+
+	var bulkWriteException mongo.BulkWriteException
+	if !errors.As(err, &bulkWriteException) || bulkWriteException.WriteConcernError != nil {
+		logger.Error("Error trying to insert auction bidders", err)
+		return 0, internal_error.NewInternalServerError("Error trying to insert auction bidders")
+	}
+
+	for _, writeError := range bulkWriteException.WriteErrors {
+		// A duplicate key means the user had already bid on the auction
+		if !mongo.IsDuplicateKeyError(writeError) {
+			logger.Error("Error trying to insert auction bidder", writeError,
+				zap.String("auctionId", auctionId))
+		}
+	}
+
+	return len(bidders) - len(bulkWriteException.WriteErrors), nil
+}
@@ -0,0 +1,21 @@
+package bid
+
+import (
+	"context"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"go.uber.org/zap"
+)
+
+// releaseHold gives back the funds held for the bid when it was placed. The hold only belongs to
+// the user's highest bid on the auction, so releasing a lower bid leaves it in place
+func (bd *BidRepository) releaseHold(ctx context.Context, bidValue bid_entity.Bid) {
+	if bd.WalletRepository == nil {
+		return
+	}
+
+	if err := bd.WalletRepository.ReleaseHold(ctx, bidValue.UserId, bidValue.AuctionId, bidValue.Id); err != nil {
+		logger.Error("Error trying to release bid hold", err, zap.String("bidId", bidValue.Id))
+	}
+}
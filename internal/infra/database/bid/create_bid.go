@@ -2,121 +2,288 @@ package bid
 
 import (
 	"context"
-	"os"
+	"errors"
+	"fmt"
 	"sync"
-	"time"
 
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/auction"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const duplicateKeyErrorCode = 11000
+
 type BidEntityMongo struct {
-	Id        string  `bson:"_id"`
-	UserId    string  `bson:"user_id"`
-	AuctionId string  `bson:"auction_id"`
-	Amount    float64 `bson:"amount"`
-	Timestamp int64   `bson:"timestamp"`
+	Id        string               `bson:"_id"`
+	UserId    string               `bson:"user_id"`
+	AuctionId string               `bson:"auction_id"`
+	Amount    primitive.Decimal128 `bson:"amount"`
+	Currency  string               `bson:"currency"`
+	// Timestamp is in milliseconds, the precision bids are ranked with. It is read with
+	// timestamp.FromMillis, since older bids are stored in seconds
+	Timestamp int64 `bson:"timestamp"`
+}
+
+// amount reads the stored amount back into minor units of the bid currency
+func (bm *BidEntityMongo) amount() money_entity.Money {
+	amount, err := money.FromDecimal128(bm.Amount, bm.Currency)
+	if err != nil {
+		logger.Error("Error trying to read bid amount", err, zap.String("bidId", bm.Id))
+		return money_entity.Money{}
+	}
+
+	return amount
 }
 
 type BidRepository struct {
-	Collection            *mongo.Collection
-	AuctionRepository     *auction.AuctionRepository
-	auctionInterval       time.Duration
-	auctionStatusMap      map[string]auction_entity.AuctionStatus
-	auctionEndTimeMap     map[string]time.Time
-	auctionStatusMapMutex *sync.Mutex
-	auctionEndTimeMutex   *sync.Mutex
+	Collection        *mongo.Collection
+	AuctionRepository *auction.AuctionRepository
+	Notifier          notification_entity.NotificationDispatcherInterface
+	// WalletRepository releases the funds held when a bid was placed once it can't take the
+	// lead. Holds are ignored when nil
+	WalletRepository wallet_entity.WalletRepositoryInterface
 }
 
 func NewBidRepository(database *mongo.Database, auctionRepository *auction.AuctionRepository) *BidRepository {
 	return &BidRepository{
-		auctionInterval:       getAuctionInterval(),
-		auctionStatusMap:      make(map[string]auction_entity.AuctionStatus),
-		auctionEndTimeMap:     make(map[string]time.Time),
-		auctionStatusMapMutex: &sync.Mutex{},
-		auctionEndTimeMutex:   &sync.Mutex{},
-		Collection:            database.Collection("bids"),
-		AuctionRepository:     auctionRepository,
+		Collection:        database.Collection("bids"),
+		AuctionRepository: auctionRepository,
 	}
 }
 
+// CreateBid persists the batch with one unordered InsertMany per auction and reports the outcome
+// of every bid. Bids that were already inserted by a previous attempt are reported as duplicated,
+// and it fails when any bid could not be stored, so the caller keeps those for a retry
 func (bd *BidRepository) CreateBid(
 	ctx context.Context,
-	bidEntities []bid_entity.Bid) *internal_error.InternalError {
-	var wg sync.WaitGroup
+	bidEntities []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	bidsByAuction := make(map[string][]bid_entity.Bid)
 	for _, bid := range bidEntities {
+		bidsByAuction[bid.AuctionId] = append(bidsByAuction[bid.AuctionId], bid)
+	}
+
+	var mutex sync.Mutex
+	var results []bid_entity.BidResult
+	var wg sync.WaitGroup
+	for _, auctionBids := range bidsByAuction {
 		wg.Add(1)
-		go func(bidValue bid_entity.Bid) {
+		go func(auctionBids []bid_entity.Bid) {
 			defer wg.Done()
 
-			bd.auctionStatusMapMutex.Lock()
-			auctionStatus, okStatus := bd.auctionStatusMap[bidValue.AuctionId]
-			bd.auctionStatusMapMutex.Unlock()
+			auctionResults := bd.createAuctionBids(ctx, auctionBids)
 
-			bd.auctionEndTimeMutex.Lock()
-			auctionEndTime, okEndTime := bd.auctionEndTimeMap[bidValue.AuctionId]
-			bd.auctionEndTimeMutex.Unlock()
+			mutex.Lock()
+			results = append(results, auctionResults...)
+			mutex.Unlock()
+		}(auctionBids)
+	}
+	wg.Wait()
 
-			bidEntityMongo := &BidEntityMongo{
-				Id:        bidValue.Id,
-				UserId:    bidValue.UserId,
-				AuctionId: bidValue.AuctionId,
-				Amount:    bidValue.Amount,
-				Timestamp: bidValue.Timestamp.Unix(),
-			}
+	for _, result := range results {
+		if result.Outcome == bid_entity.BidFailed {
+			return results, internal_error.NewInternalServerError("Error trying to insert bids")
+		}
+	}
+
+	return results, nil
+}
 
-			if okEndTime && okStatus {
-				now := time.Now()
-				if auctionStatus == auction_entity.Completed || now.After(auctionEndTime) {
-					return
-				}
+// createAuctionBids validates the bids of a single auction against its state, inserts the valid
+// ones at once and offers the best of them as the new high bid
+func (bd *BidRepository) createAuctionBids(
+	ctx context.Context, auctionBids []bid_entity.Bid) []bid_entity.BidResult {
+	results := make([]bid_entity.BidResult, 0, len(auctionBids))
 
-				if _, err := bd.Collection.InsertOne(ctx, bidEntityMongo); err != nil {
-					logger.Error("Error trying to insert bid", err)
-					return
-				}
+	auctionState, err := bd.AuctionRepository.FindAuctionState(ctx, auctionBids[0].AuctionId)
+	if err != nil {
+		logger.Error("Error trying to find auction by id", err)
+		for _, bid := range auctionBids {
+			results = append(results, bid_entity.BidResult{
+				BidId: bid.Id, Outcome: bid_entity.BidFailed, Err: err})
+		}
+		return results
+	}
 
-				return
-			}
+	auctionCurrency := auctionState.Currency
+	if auctionCurrency == "" {
+		auctionCurrency = money_entity.DefaultCurrency()
+	}
 
-			auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
-			if err != nil {
-				logger.Error("Error trying to find auction by id", err)
-				return
-			}
-			if auctionEntity.Status == auction_entity.Completed {
-				return
-			}
+	var validBids []bid_entity.Bid
+	var bidEntitiesMongo []any
+	for _, bid := range auctionBids {
+		// Amounts in different currencies can't be ranked against each other
+		if bid.Amount.Currency != auctionCurrency {
+			bd.releaseHold(ctx, bid)
+			results = append(results, bid_entity.BidResult{
+				BidId:   bid.Id,
+				Outcome: bid_entity.BidRejected,
+				Err: internal_error.NewBadRequestError(
+					fmt.Sprintf("Bids on this auction must be placed in %s", auctionCurrency)),
+			})
+			continue
+		}
+
+		// Replayed bids are judged by the moment they were placed, not by when they are persisted
+		if auctionState.Status == auction_entity.Completed || bid.Timestamp.After(auctionState.EndTime) {
+			bd.releaseHold(ctx, bid)
+			results = append(results, bid_entity.BidResult{
+				BidId:   bid.Id,
+				Outcome: bid_entity.BidRejected,
+				Err:     internal_error.NewBadRequestError("Auction is already closed"),
+			})
+			continue
+		}
+
+		validBids = append(validBids, bid)
+	}
+
+	if len(validBids) == 0 {
+		return results
+	}
+
+	for _, bid := range validBids {
+		bidEntitiesMongo = append(bidEntitiesMongo, &BidEntityMongo{
+			Id:        bid.Id,
+			UserId:    bid.UserId,
+			AuctionId: bid.AuctionId,
+			Amount:    money.ToDecimal128(bid.Amount),
+			Currency:  bid.Amount.Currency,
+			Timestamp: bid.Timestamp.UnixMilli(),
+		})
+	}
+
+	outcomes := bd.insertBids(ctx, bidEntitiesMongo)
 
-			bd.auctionStatusMapMutex.Lock()
-			bd.auctionStatusMap[bidValue.AuctionId] = auctionEntity.Status
-			bd.auctionStatusMapMutex.Unlock()
+	// Bids duplicated by a retry were counted by the attempt that stored them
+	insertedCount := 0
+	var bidderIds []string
+	var bestBid *bid_entity.Bid
+	for i, bid := range validBids {
+		results = append(results, outcomes[i])
 
-			bd.auctionEndTimeMutex.Lock()
-			bd.auctionEndTimeMap[bidValue.AuctionId] = auctionEntity.Timestamp.Add(bd.auctionInterval)
-			bd.auctionEndTimeMutex.Unlock()
+		if outcomes[i].Outcome == bid_entity.BidInserted {
+			insertedCount++
+			bidderIds = append(bidderIds, bid.UserId)
+		}
 
-			if _, err := bd.Collection.InsertOne(ctx, bidEntityMongo); err != nil {
-				logger.Error("Error trying to insert bid", err)
-				return
+		if outcomes[i].Outcome == bid_entity.BidFailed {
+			continue
+		}
+		if bestBid == nil || bid.Outranks(*bestBid) {
+			bestBid = &validBids[i]
+		}
+	}
+
+	if insertedCount > 0 {
+		// The summary only orders and describes listings, so a failed update doesn't fail the bids
+		_ = bd.AuctionRepository.AddBids(ctx, validBids[0].AuctionId, bidderIds, insertedCount)
+	}
+
+	if bestBid == nil {
+		return results
+	}
+
+	// A bid that could not be offered is reported as failed, so it stays in the pending bid log
+	// with its funds held and is offered again when the batch is retried. The other bids keep
+	// their holds until the auction closes, since the best bid may never displace their users
+	if err := bd.offerHighBid(ctx, *bestBid); err != nil {
+		for i := range results {
+			if results[i].BidId == bestBid.Id {
+				results[i].Outcome = bid_entity.BidFailed
+				results[i].Err = err
 			}
-		}(bid)
+		}
+		return results
 	}
-	wg.Wait()
-	return nil
+
+	// Bids below the best one of the batch can't take the lead
+	for i, bid := range validBids {
+		if bid.Id != bestBid.Id && outcomes[i].Outcome != bid_entity.BidFailed {
+			bd.releaseHold(ctx, bid)
+		}
+	}
+
+	return results
 }
 
-func getAuctionInterval() time.Duration {
-	auctionInterval := os.Getenv("AUCTION_INTERVAL")
-	duration, err := time.ParseDuration(auctionInterval)
+// insertBids writes the documents with an unordered InsertMany, so one failing bid doesn't stop
+// the others, and maps every write error back to the bid at its index
+func (bd *BidRepository) insertBids(
+	ctx context.Context, bidEntitiesMongo []any) []bid_entity.BidResult {
+	results := make([]bid_entity.BidResult, len(bidEntitiesMongo))
+	for i, bidEntityMongo := range bidEntitiesMongo {
+		results[i] = bid_entity.BidResult{
+			BidId:   bidEntityMongo.(*BidEntityMongo).Id,
+			Outcome: bid_entity.BidInserted,
+		}
+	}
+
+	_, err := bd.Collection.InsertMany(ctx, bidEntitiesMongo, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return results
+	}
+
+	var bulkWriteException mongo.BulkWriteException
+	if !errors.As(err, &bulkWriteException) || bulkWriteException.WriteConcernError != nil {
+		logger.Error("Error trying to insert bids", err)
+		for i := range results {
+			results[i].Outcome = bid_entity.BidFailed
+			results[i].Err = internal_error.NewInternalServerError("Error trying to insert bid")
+		}
+		return results
+	}
+
+	for _, writeError := range bulkWriteException.WriteErrors {
+		// A duplicate key means the bid was stored before a crash
+		if writeError.HasErrorCode(duplicateKeyErrorCode) {
+			results[writeError.Index].Outcome = bid_entity.BidDuplicated
+			continue
+		}
+
+		logger.Error("Error trying to insert bid", writeError,
+			zap.String("bidId", results[writeError.Index].BidId))
+		results[writeError.Index].Outcome = bid_entity.BidFailed
+		results[writeError.Index].Err = internal_error.NewInternalServerError("Error trying to insert bid")
+	}
+
+	return results
+}
+
+// offerHighBid offers the bid as the new high bid of the auction. When it takes the lead, the
+// funds held for the previous high bid are released and its user is notified. Otherwise the
+// funds held for the bid itself are released. When the offer fails the hold is kept, since the
+// bid may still be the leader
+func (bd *BidRepository) offerHighBid(
+	ctx context.Context, bidValue bid_entity.Bid) *internal_error.InternalError {
+	tookLead, previousHighBid, err := bd.AuctionRepository.UpdateHighBid(ctx, bidValue)
 	if err != nil {
-		return time.Minute * 5
+		return err
 	}
 
-	return duration
+	if !tookLead {
+		bd.releaseHold(ctx, bidValue)
+		return nil
+	}
+
+	if previousHighBid != nil {
+		bd.releaseHold(ctx, *previousHighBid)
+	}
+
+	if previousHighBid != nil && bd.Notifier != nil {
+		bd.Notifier.NotifyOutbid(ctx, *previousHighBid, bidValue)
+	}
+
+	return nil
 }
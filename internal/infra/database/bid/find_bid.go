@@ -2,25 +2,61 @@ package bid
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/pagination"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/timestamp"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// bidSortKeys are the fields each bid sort is based on
+var bidSortKeys = map[bid_entity.BidSort]pagination.SortKey{
+	bid_entity.SortNewest:        {Field: "timestamp", Descending: true},
+	bid_entity.SortHighestAmount: {Field: "amount", Descending: true},
+}
+
+// sortValue returns the value the bid is sorted by
+func (bm *BidEntityMongo) sortValue(sort bid_entity.BidSort) any {
+	if sort == bid_entity.SortHighestAmount {
+		return bm.Amount
+	}
+
+	return bm.Timestamp
+}
+
 func (bd *BidRepository) FindBidByAuctionId(
-	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
-	filter := bson.M{"auctionId": auctionId}
+	ctx context.Context,
+	auctionId string,
+	sort bid_entity.BidSort,
+	page pagination_entity.PageRequest) ([]bid_entity.Bid, string, *internal_error.InternalError) {
+	filter := bson.M{"auction_id": auctionId}
 
-	cursor, err := bd.Collection.Find(ctx, filter)
+	sortKey, ok := bidSortKeys[sort]
+	if !ok {
+		return nil, "", internal_error.NewBadRequestError("Invalid sort option")
+	}
+
+	afterCursor, cursorErr := sortKey.AfterCursor(page)
+	if cursorErr != nil {
+		return nil, "", cursorErr
+	}
+	if afterCursor != nil {
+		filter["$and"] = bson.A{afterCursor}
+	}
+
+	cursor, err := bd.Collection.Find(ctx, filter, sortKey.FindOptions(page))
 	if err != nil {
 		logger.Error(
 			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
-		return nil, internal_error.NewInternalServerError(
+		return nil, "", internal_error.NewInternalServerError(
 			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
 	}
 
@@ -28,31 +64,110 @@ func (bd *BidRepository) FindBidByAuctionId(
 	if err := cursor.All(ctx, &bidEntitiesMongo); err != nil {
 		logger.Error(
 			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
-		return nil, internal_error.NewInternalServerError(
+		return nil, "", internal_error.NewInternalServerError(
 			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
 	}
 
+	var nextCursor string
+	if int64(len(bidEntitiesMongo)) > page.Limit {
+		bidEntitiesMongo = bidEntitiesMongo[:page.Limit]
+		last := bidEntitiesMongo[len(bidEntitiesMongo)-1]
+		nextCursor = pagination.EncodeCursor(last.sortValue(sort), last.Id)
+	}
+
+	var bidEntities []bid_entity.Bid
+	for _, bidEntityMongo := range bidEntitiesMongo {
+		bidEntities = append(bidEntities, bid_entity.Bid{
+			Id:        bidEntityMongo.Id,
+			UserId:    bidEntityMongo.UserId,
+			AuctionId: bidEntityMongo.AuctionId,
+			Amount:    bidEntityMongo.amount(),
+			Timestamp: timestamp.FromMillis(bidEntityMongo.Timestamp),
+		})
+	}
+
+	return bidEntities, nextCursor, nil
+}
+
+// FindRankedBidsByAuctionId returns the best bid of each bidder of the auction, ranked the way
+// bids compete for the lead: highest amount first and, on a tie, the earliest bid
+func (bd *BidRepository) FindRankedBidsByAuctionId(
+	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	filter := bson.M{"auction_id": auctionId}
+	opts := options.Find().SetSort(bson.D{
+		{Key: "amount", Value: -1},
+		{Key: "timestamp", Value: 1},
+		{Key: "_id", Value: 1},
+	})
+
+	cursor, err := bd.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error(
+			fmt.Sprintf("Error trying to find ranked bids by auctionId %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to find ranked bids by auctionId %s", auctionId))
+	}
+
+	var bidEntitiesMongo []BidEntityMongo
+	if err := cursor.All(ctx, &bidEntitiesMongo); err != nil {
+		logger.Error(
+			fmt.Sprintf("Error trying to find ranked bids by auctionId %s", auctionId), err)
+		return nil, internal_error.NewInternalServerError(
+			fmt.Sprintf("Error trying to find ranked bids by auctionId %s", auctionId))
+	}
+
+	rankedUsers := make(map[string]bool)
 	var bidEntities []bid_entity.Bid
 	for _, bidEntityMongo := range bidEntitiesMongo {
+		if rankedUsers[bidEntityMongo.UserId] {
+			continue
+		}
+		rankedUsers[bidEntityMongo.UserId] = true
+
 		bidEntities = append(bidEntities, bid_entity.Bid{
 			Id:        bidEntityMongo.Id,
 			UserId:    bidEntityMongo.UserId,
 			AuctionId: bidEntityMongo.AuctionId,
-			Amount:    bidEntityMongo.Amount,
-			Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
+			Amount:    bidEntityMongo.amount(),
+			Timestamp: timestamp.FromMillis(bidEntityMongo.Timestamp),
 		})
 	}
 
 	return bidEntities, nil
 }
 
+// FindWinningBidByAuctionId returns the result stored when the auction was closed. While the
+// auction is still active it returns the current high bid
 func (bd *BidRepository) FindWinningBidByAuctionId(
 	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
-	filter := bson.M{"auction_id": auctionId}
+	auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	bidId := auctionEntity.HighBidId
+	if auctionEntity.Status == auction_entity.Completed {
+		bidId = auctionEntity.WinningBidId
+	}
+
+	if bidId == "" {
+		if auctionEntity.Status == auction_entity.Completed {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Auction %s was closed without bids", auctionId))
+		}
+
+		// Auctions whose high bid was not tracked yet fall back to sorting their bids
+		highestBid := bd.findHighestBid(ctx, auctionId)
+		if highestBid == nil {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("No bids found for auction %s", auctionId))
+		}
+
+		return highestBid, nil
+	}
 
 	var bidEntityMongo BidEntityMongo
-	opts := options.FindOne().SetSort(bson.D{{Key: "amount", Value: -1}})
-	if err := bd.Collection.FindOne(ctx, filter, opts).Decode(&bidEntityMongo); err != nil {
+	if err := bd.Collection.FindOne(ctx, bson.M{"_id": bidId}).Decode(&bidEntityMongo); err != nil {
 		logger.Error("Error trying to find the auction winner", err)
 		return nil, internal_error.NewInternalServerError("Error trying to find the auction winner")
 	}
@@ -61,7 +176,29 @@ func (bd *BidRepository) FindWinningBidByAuctionId(
 		Id:        bidEntityMongo.Id,
 		UserId:    bidEntityMongo.UserId,
 		AuctionId: bidEntityMongo.AuctionId,
-		Amount:    bidEntityMongo.Amount,
-		Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
+		Amount:    bidEntityMongo.amount(),
+		Timestamp: timestamp.FromMillis(bidEntityMongo.Timestamp),
 	}, nil
 }
+
+// findHighestBid returns the current highest bid of the auction, or nil when it has no bids yet
+func (bd *BidRepository) findHighestBid(ctx context.Context, auctionId string) *bid_entity.Bid {
+	filter := bson.M{"auction_id": auctionId}
+
+	var bidEntityMongo BidEntityMongo
+	opts := options.FindOne().SetSort(bson.D{{Key: "amount", Value: -1}, {Key: "timestamp", Value: 1}})
+	if err := bd.Collection.FindOne(ctx, filter, opts).Decode(&bidEntityMongo); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Error("Error trying to find the highest bid", err)
+		}
+		return nil
+	}
+
+	return &bid_entity.Bid{
+		Id:        bidEntityMongo.Id,
+		UserId:    bidEntityMongo.UserId,
+		AuctionId: bidEntityMongo.AuctionId,
+		Amount:    bidEntityMongo.amount(),
+		Timestamp: timestamp.FromMillis(bidEntityMongo.Timestamp),
+	}
+}
@@ -0,0 +1,175 @@
+package bid
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PendingBidEntityMongo is a bid that was acknowledged but not yet persisted by the batch processor
+type PendingBidEntityMongo struct {
+	Id         string               `bson:"_id"`
+	UserId     string               `bson:"user_id"`
+	AuctionId  string               `bson:"auction_id"`
+	Amount     primitive.Decimal128 `bson:"amount"`
+	Currency   string               `bson:"currency"`
+	Timestamp  int64                `bson:"timestamp"`
+	InstanceId string               `bson:"instance_id"`
+	ReceivedAt int64                `bson:"received_at"`
+	// Status is empty while the bid is pending and deadLetterStatus once it was given up
+	Status         string `bson:"status,omitempty"`
+	DeadLetteredAt int64  `bson:"dead_lettered_at,omitempty"`
+}
+
+const deadLetterStatus = "dead_letter"
+
+type PendingBidRepository struct {
+	Collection *mongo.Collection
+	instanceId string
+	staleAfter time.Duration
+}
+
+func NewPendingBidRepository(database *mongo.Database) *PendingBidRepository {
+	return &PendingBidRepository{
+		Collection: database.Collection("pending_bids"),
+		instanceId: getInstanceId(),
+		staleAfter: getPendingBidStaleAfter(),
+	}
+}
+
+// getInstanceId identifies the process across restarts, so it can replay the bids it acknowledged
+// before stopping. Containers keep their hostname when they are restarted
+func getInstanceId() string {
+	if instanceId := os.Getenv("INSTANCE_ID"); instanceId != "" {
+		return instanceId
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+
+	return hostname
+}
+
+// getPendingBidStaleAfter returns how old a pending bid of another instance must be before
+// this instance adopts it, e.g. because that instance was scaled down after crashing
+func getPendingBidStaleAfter() time.Duration {
+	staleAfter := os.Getenv("PENDING_BID_STALE_AFTER")
+	duration, err := time.ParseDuration(staleAfter)
+	if err != nil || duration <= 0 {
+		return 10 * time.Minute
+	}
+
+	return duration
+}
+
+func (pr *PendingBidRepository) AppendPendingBid(
+	ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	pendingBidEntityMongo := &PendingBidEntityMongo{
+		Id:         bidEntity.Id,
+		UserId:     bidEntity.UserId,
+		AuctionId:  bidEntity.AuctionId,
+		Amount:     money.ToDecimal128(bidEntity.Amount),
+		Currency:   bidEntity.Amount.Currency,
+		Timestamp:  bidEntity.Timestamp.UnixMilli(),
+		InstanceId: pr.instanceId,
+		ReceivedAt: time.Now().UnixMilli(),
+	}
+
+	if _, err := pr.Collection.InsertOne(ctx, pendingBidEntityMongo); err != nil {
+		logger.Error("Error trying to append pending bid", err)
+		return internal_error.NewInternalServerError("Error trying to append pending bid")
+	}
+
+	return nil
+}
+
+// FindUnprocessedPendingBids returns the bids this instance acknowledged but did not persist,
+// along with the stale ones left behind by other instances. Dead-lettered bids are left out
+func (pr *PendingBidRepository) FindUnprocessedPendingBids(
+	ctx context.Context) ([]bid_entity.Bid, *internal_error.InternalError) {
+	filter := bson.M{
+		"status": bson.M{"$ne": deadLetterStatus},
+		"$or": bson.A{
+			bson.M{"instance_id": pr.instanceId},
+			bson.M{"received_at": bson.M{"$lt": time.Now().Add(-pr.staleAfter).UnixMilli()}},
+		},
+	}
+
+	cursor, err := pr.Collection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to find pending bids", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find pending bids")
+	}
+	defer cursor.Close(ctx)
+
+	var pendingBidEntitiesMongo []PendingBidEntityMongo
+	if err := cursor.All(ctx, &pendingBidEntitiesMongo); err != nil {
+		logger.Error("Error trying to decode pending bids", err)
+		return nil, internal_error.NewInternalServerError("Error trying to decode pending bids")
+	}
+
+	var bidEntities []bid_entity.Bid
+	for _, pendingBidEntityMongo := range pendingBidEntitiesMongo {
+		amount, err := money.FromDecimal128(pendingBidEntityMongo.Amount, pendingBidEntityMongo.Currency)
+		if err != nil {
+			logger.Error("Error trying to read pending bid amount", err)
+			continue
+		}
+
+		bidEntities = append(bidEntities, bid_entity.Bid{
+			Id:        pendingBidEntityMongo.Id,
+			UserId:    pendingBidEntityMongo.UserId,
+			AuctionId: pendingBidEntityMongo.AuctionId,
+			Amount:    amount,
+			Timestamp: time.UnixMilli(pendingBidEntityMongo.Timestamp),
+		})
+	}
+
+	return bidEntities, nil
+}
+
+func (pr *PendingBidRepository) RemovePendingBids(
+	ctx context.Context, bidIds []string) *internal_error.InternalError {
+	if len(bidIds) == 0 {
+		return nil
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": bidIds}}
+	if _, err := pr.Collection.DeleteMany(ctx, filter); err != nil {
+		logger.Error("Error trying to remove pending bids", err)
+		return internal_error.NewInternalServerError("Error trying to remove pending bids")
+	}
+
+	return nil
+}
+
+// DeadLetterPendingBids keeps the bids in the log for inspection but stops them from being replayed
+func (pr *PendingBidRepository) DeadLetterPendingBids(
+	ctx context.Context, bidIds []string) *internal_error.InternalError {
+	if len(bidIds) == 0 {
+		return nil
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": bidIds}}
+	update := bson.M{"$set": bson.M{
+		"status":           deadLetterStatus,
+		"dead_lettered_at": time.Now().UnixMilli(),
+	}}
+	if _, err := pr.Collection.UpdateMany(ctx, filter, update); err != nil {
+		logger.Error("Error trying to dead letter pending bids", err)
+		return internal_error.NewInternalServerError("Error trying to dead letter pending bids")
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/category_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AttributeMongo struct {
+	Name     string   `bson:"name"`
+	Type     string   `bson:"type,omitempty"`
+	Values   []string `bson:"values,omitempty"`
+	Required bool     `bson:"required"`
+}
+
+type CategoryEntityMongo struct {
+	Id         string           `bson:"_id"`
+	Name       string           `bson:"name"`
+	ParentId   string           `bson:"parent_id,omitempty"`
+	Ancestors  []string         `bson:"ancestors"`
+	Attributes []AttributeMongo `bson:"attributes"`
+	Timestamp  int64            `bson:"timestamp"`
+}
+
+type CategoryRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewCategoryRepository(database *mongo.Database) *CategoryRepository {
+	return &CategoryRepository{
+		Collection: database.Collection("categories"),
+	}
+}
+
+func toCategoryMongo(category *category_entity.Category) CategoryEntityMongo {
+	attributes := make([]AttributeMongo, 0, len(category.Attributes))
+	for _, attribute := range category.Attributes {
+		attributes = append(attributes, AttributeMongo{
+			Name:     attribute.Name,
+			Type:     string(attribute.Type),
+			Values:   attribute.Values,
+			Required: attribute.Required,
+		})
+	}
+
+	return CategoryEntityMongo{
+		Id:         category.Id,
+		Name:       category.Name,
+		ParentId:   category.ParentId,
+		Ancestors:  category.Ancestors,
+		Attributes: attributes,
+		Timestamp:  category.Timestamp.Unix(),
+	}
+}
+
+func (cm *CategoryEntityMongo) toEntity() category_entity.Category {
+	attributes := make([]category_entity.Attribute, 0, len(cm.Attributes))
+	for _, attribute := range cm.Attributes {
+		// Attributes stored before attributes had a type are text
+		attributeType := category_entity.AttributeType(attribute.Type)
+		if attributeType == "" {
+			attributeType = category_entity.TextAttribute
+		}
+
+		attributes = append(attributes, category_entity.Attribute{
+			Name:     attribute.Name,
+			Type:     attributeType,
+			Values:   attribute.Values,
+			Required: attribute.Required,
+		})
+	}
+
+	ancestors := cm.Ancestors
+	if ancestors == nil {
+		ancestors = []string{}
+	}
+
+	return category_entity.Category{
+		Id:         cm.Id,
+		Name:       cm.Name,
+		ParentId:   cm.ParentId,
+		Ancestors:  ancestors,
+		Attributes: attributes,
+		Timestamp:  time.Unix(cm.Timestamp, 0),
+	}
+}
+
+func (cr *CategoryRepository) CreateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	if _, err := cr.Collection.InsertOne(ctx, toCategoryMongo(category)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("Category %s already exists", category.Id))
+		}
+
+		logger.Error("Error trying to create category", err)
+		return internal_error.NewInternalServerError("Error trying to create category")
+	}
+
+	return nil
+}
+
+// UpdateCategory replaces the name and attributes of the category. Its place in the tree is
+// kept, since moving it would change the ancestors of all its descendants
+func (cr *CategoryRepository) UpdateCategory(
+	ctx context.Context, category *category_entity.Category) *internal_error.InternalError {
+	categoryMongo := toCategoryMongo(category)
+	update := bson.M{"$set": bson.M{
+		"name":       categoryMongo.Name,
+		"attributes": categoryMongo.Attributes,
+	}}
+
+	result, err := cr.Collection.UpdateByID(ctx, category.Id, update)
+	if err != nil {
+		logger.Error("Error trying to update category", err)
+		return internal_error.NewInternalServerError("Error trying to update category")
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError(fmt.Sprintf("Category not found with this id = %s", category.Id))
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) DeleteCategory(
+	ctx context.Context, id string) *internal_error.InternalError {
+	result, err := cr.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		logger.Error("Error trying to delete category", err)
+		return internal_error.NewInternalServerError("Error trying to delete category")
+	}
+
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError(fmt.Sprintf("Category not found with this id = %s", id))
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) FindCategoryById(
+	ctx context.Context, id string) (*category_entity.Category, *internal_error.InternalError) {
+	var categoryMongo CategoryEntityMongo
+	if err := cr.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&categoryMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(fmt.Sprintf("Category not found with this id = %s", id))
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find category by id = %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find category by id")
+	}
+
+	category := categoryMongo.toEntity()
+	return &category, nil
+}
+
+func (cr *CategoryRepository) FindCategoryPath(
+	ctx context.Context, id string) ([]category_entity.Category, *internal_error.InternalError) {
+	category, err := cr.FindCategoryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(category.Ancestors) == 0 {
+		return []category_entity.Category{*category}, nil
+	}
+
+	ancestors, err := cr.findCategories(ctx, bson.M{"_id": bson.M{"$in": category.Ancestors}})
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]category_entity.Category, len(ancestors))
+	for _, ancestor := range ancestors {
+		byId[ancestor.Id] = ancestor
+	}
+
+	path := make([]category_entity.Category, 0, len(category.Ancestors)+1)
+	for _, ancestorId := range category.Ancestors {
+		if ancestor, ok := byId[ancestorId]; ok {
+			path = append(path, ancestor)
+		}
+	}
+
+	return append(path, *category), nil
+}
+
+func (cr *CategoryRepository) FindCategories(
+	ctx context.Context) ([]category_entity.Category, *internal_error.InternalError) {
+	return cr.findCategories(ctx, bson.M{})
+}
+
+func (cr *CategoryRepository) FindDescendantIds(
+	ctx context.Context, id string) ([]string, *internal_error.InternalError) {
+	categories, err := cr.findCategories(ctx, bson.M{"$or": bson.A{
+		bson.M{"_id": id},
+		bson.M{"ancestors": id},
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(categories))
+	for _, category := range categories {
+		ids = append(ids, category.Id)
+	}
+
+	return ids, nil
+}
+
+func (cr *CategoryRepository) CountChildren(
+	ctx context.Context, id string) (int64, *internal_error.InternalError) {
+	count, err := cr.Collection.CountDocuments(ctx, bson.M{"parent_id": id})
+	if err != nil {
+		logger.Error("Error trying to count subcategories", err)
+		return 0, internal_error.NewInternalServerError("Error trying to count subcategories")
+	}
+
+	return count, nil
+}
+
+// findCategories returns the categories matching the filter ordered by id
+func (cr *CategoryRepository) findCategories(
+	ctx context.Context, filter bson.M) ([]category_entity.Category, *internal_error.InternalError) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := cr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Error trying to find categories", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find categories")
+	}
+	defer cursor.Close(ctx)
+
+	var categoriesMongo []CategoryEntityMongo
+	if err := cursor.All(ctx, &categoriesMongo); err != nil {
+		logger.Error("Error decoding categories", err)
+		return nil, internal_error.NewInternalServerError("Error decoding categories")
+	}
+
+	categories := make([]category_entity.Category, 0, len(categoriesMongo))
+	for _, categoryMongo := range categoriesMongo {
+		categories = append(categories, categoryMongo.toEntity())
+	}
+
+	return categories, nil
+}
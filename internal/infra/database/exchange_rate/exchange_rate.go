@@ -0,0 +1,88 @@
+package exchange_rate
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/exchange_rate_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const currentRateTableId = "current"
+
+// ExchangeRateEntityMongo keeps the rates as exact fractions, e.g. "51/10"
+type ExchangeRateEntityMongo struct {
+	Id        string            `bson:"_id"`
+	Base      string            `bson:"base"`
+	Rates     map[string]string `bson:"rates"`
+	UpdatedAt time.Time         `bson:"updated_at"`
+}
+
+type ExchangeRateRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewExchangeRateRepository(database *mongo.Database) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		Collection: database.Collection("exchange_rates"),
+	}
+}
+
+func (er *ExchangeRateRepository) FindRateTable(
+	ctx context.Context) (*exchange_rate_entity.RateTable, *internal_error.InternalError) {
+	var rateTableMongo ExchangeRateEntityMongo
+	if err := er.Collection.FindOne(ctx, bson.M{"_id": currentRateTableId}).Decode(&rateTableMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError("No exchange rates were loaded")
+		}
+
+		logger.Error("Error trying to find exchange rates", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find exchange rates")
+	}
+
+	rates := make(map[string]*big.Rat, len(rateTableMongo.Rates))
+	for currency, value := range rateTableMongo.Rates {
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok {
+			logger.Error("Error trying to read exchange rate of "+currency, nil)
+			return nil, internal_error.NewInternalServerError("Error trying to find exchange rates")
+		}
+		rates[currency] = rate
+	}
+
+	return &exchange_rate_entity.RateTable{
+		Base:      rateTableMongo.Base,
+		Rates:     rates,
+		UpdatedAt: rateTableMongo.UpdatedAt,
+	}, nil
+}
+
+func (er *ExchangeRateRepository) UpdateRateTable(
+	ctx context.Context, rateTable *exchange_rate_entity.RateTable) *internal_error.InternalError {
+	rates := make(map[string]string, len(rateTable.Rates))
+	for currency, rate := range rateTable.Rates {
+		rates[currency] = rate.RatString()
+	}
+
+	rateTableMongo := &ExchangeRateEntityMongo{
+		Id:        currentRateTableId,
+		Base:      rateTable.Base,
+		Rates:     rates,
+		UpdatedAt: rateTable.UpdatedAt,
+	}
+
+	filter := bson.M{"_id": currentRateTableId}
+	if _, err := er.Collection.ReplaceOne(ctx, filter, rateTableMongo, options.Replace().SetUpsert(true)); err != nil {
+		logger.Error("Error trying to update exchange rates", err)
+		return internal_error.NewInternalServerError("Error trying to update exchange rates")
+	}
+
+	return nil
+}
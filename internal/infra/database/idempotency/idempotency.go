@@ -0,0 +1,165 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/idempotency_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IdempotencyEntityMongo struct {
+	Key          string                          `bson:"_id"`
+	RequestHash  string                          `bson:"request_hash"`
+	Status       idempotency_entity.RecordStatus `bson:"status"`
+	StatusCode   int                             `bson:"status_code,omitempty"`
+	ContentType  string                          `bson:"content_type,omitempty"`
+	ResponseBody []byte                          `bson:"response_body,omitempty"`
+	// ExpiresAt is a BSON date, as required by the TTL index
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type IdempotencyRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewIdempotencyRepository(database *mongo.Database) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		Collection: database.Collection("idempotency_keys"),
+	}
+}
+
+// GetIdempotencyKeyTTL returns how long a key keeps replaying its original response
+func GetIdempotencyKeyTTL() time.Duration {
+	keyTTL := os.Getenv("IDEMPOTENCY_KEY_TTL")
+	duration, err := time.ParseDuration(keyTTL)
+	if err != nil || duration <= 0 {
+		return 24 * time.Hour
+	}
+
+	return duration
+}
+
+// EnsureIndexes creates the TTL index that lets MongoDB delete expired keys. Each record carries
+// its own expiration, so changing IDEMPOTENCY_KEY_TTL doesn't require rebuilding the index
+func (ir *IdempotencyRepository) EnsureIndexes(ctx context.Context) *internal_error.InternalError {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	if _, err := ir.Collection.Indexes().CreateOne(ctx, index); err != nil {
+		logger.Error("Error trying to create idempotency key index", err)
+		return internal_error.NewInternalServerError("Error trying to create idempotency key index")
+	}
+
+	return nil
+}
+
+func (ir *IdempotencyRepository) ReserveKey(
+	ctx context.Context,
+	record idempotency_entity.Record) (*idempotency_entity.Record, *internal_error.InternalError) {
+	recordMongo := &IdempotencyEntityMongo{
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		Status:      idempotency_entity.InProgress,
+		ExpiresAt:   record.ExpiresAt,
+	}
+
+	// The TTL monitor only runs periodically, so an expired record may still be around once
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err := ir.Collection.InsertOne(ctx, recordMongo)
+		if err == nil {
+			return nil, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			logger.Error("Error trying to reserve idempotency key", err)
+			return nil, internal_error.NewInternalServerError("Error trying to reserve idempotency key")
+		}
+
+		existing, findErr := ir.findRecord(ctx, record.Key)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if existing != nil && existing.ExpiresAt.After(time.Now()) {
+			return existing, nil
+		}
+
+		filter := bson.M{"_id": record.Key, "expires_at": bson.M{"$lte": time.Now()}}
+		if _, err := ir.Collection.DeleteOne(ctx, filter); err != nil {
+			logger.Error("Error trying to remove expired idempotency key", err)
+			return nil, internal_error.NewInternalServerError("Error trying to reserve idempotency key")
+		}
+	}
+
+	return nil, internal_error.NewInternalServerError("Error trying to reserve idempotency key")
+}
+
+func (ir *IdempotencyRepository) findRecord(
+	ctx context.Context, key string) (*idempotency_entity.Record, *internal_error.InternalError) {
+	var recordMongo IdempotencyEntityMongo
+	if err := ir.Collection.FindOne(ctx, bson.M{"_id": key}).Decode(&recordMongo); err != nil {
+		// Expired and removed in between
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.Error("Error trying to find idempotency key", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find idempotency key")
+	}
+
+	return &idempotency_entity.Record{
+		Key:          recordMongo.Key,
+		RequestHash:  recordMongo.RequestHash,
+		Status:       recordMongo.Status,
+		StatusCode:   recordMongo.StatusCode,
+		ContentType:  recordMongo.ContentType,
+		ResponseBody: recordMongo.ResponseBody,
+		ExpiresAt:    recordMongo.ExpiresAt,
+	}, nil
+}
+
+func (ir *IdempotencyRepository) CompleteKey(
+	ctx context.Context,
+	key string,
+	statusCode int,
+	contentType string,
+	responseBody []byte,
+	expiresAt time.Time) *internal_error.InternalError {
+	filter := bson.M{"_id": key}
+	update := bson.M{
+		"$set": bson.M{
+			"status":        idempotency_entity.Completed,
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": responseBody,
+			"expires_at":    expiresAt,
+		},
+	}
+
+	if _, err := ir.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.Error("Error trying to complete idempotency key", err)
+		return internal_error.NewInternalServerError("Error trying to complete idempotency key")
+	}
+
+	return nil
+}
+
+func (ir *IdempotencyRepository) ReleaseKey(
+	ctx context.Context, key string) *internal_error.InternalError {
+	filter := bson.M{"_id": key, "status": idempotency_entity.InProgress}
+
+	if _, err := ir.Collection.DeleteOne(ctx, filter); err != nil {
+		logger.Error("Error trying to release idempotency key", err)
+		return internal_error.NewInternalServerError("Error trying to release idempotency key")
+	}
+
+	return nil
+}
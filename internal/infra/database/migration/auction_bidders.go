@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrateAuctionBidders indexes the "auction_bidders" collection, which holds each user who bid
+// on an auction once, and fills it in for the auctions stored before auctions kept their bidder
+// count. The bidders are rebuilt from the stored bids and the bidder count is set from the
+// collection, so running it again gives the same result
+func migrateAuctionBidders(ctx context.Context, database *mongo.Database) error {
+	auctions := database.Collection("auctions")
+	bidders := database.Collection("auction_bidders")
+	bids := database.Collection("bids")
+
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "auction_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	if _, err := bidders.Indexes().CreateOne(ctx, index); err != nil {
+		return err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$auction_id"},
+			{Key: "bidder_ids", Value: bson.M{"$addToSet": "$user_id"}},
+		}}},
+	}
+
+	cursor, err := bids.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var auctionBidders struct {
+			AuctionId string   `bson:"_id"`
+			BidderIds []string `bson:"bidder_ids"`
+		}
+		if err := cursor.Decode(&auctionBidders); err != nil {
+			return err
+		}
+
+		documents := make([]any, 0, len(auctionBidders.BidderIds))
+		for _, bidderId := range auctionBidders.BidderIds {
+			documents = append(documents, bson.M{"auction_id": auctionBidders.AuctionId, "user_id": bidderId})
+		}
+		if err := insertIgnoringDuplicates(ctx, bidders, documents); err != nil {
+			return err
+		}
+
+		count, err := bidders.CountDocuments(ctx, bson.M{"auction_id": auctionBidders.AuctionId})
+		if err != nil {
+			return err
+		}
+
+		update := bson.M{"$set": bson.M{"bidder_count": count}}
+		if _, err := auctions.UpdateOne(ctx, bson.M{"_id": auctionBidders.AuctionId}, update); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	filter := bson.M{"bidder_count": bson.M{"$exists": false}}
+	_, err = auctions.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"bidder_count": 0}})
+	return err
+}
+
+// insertIgnoringDuplicates inserts the documents, skipping the ones that are already stored
+func insertIgnoringDuplicates(ctx context.Context, collection *mongo.Collection, documents []any) error {
+	if len(documents) == 0 {
+		return nil
+	}
+
+	_, err := collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	var bulkWriteException mongo.BulkWriteException
+	if err == nil || !errors.As(err, &bulkWriteException) || bulkWriteException.WriteConcernError != nil {
+		return err
+	}
+
+	for _, writeError := range bulkWriteException.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeError) {
+			return writeError
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrateAuctionSearch creates the text index the auction search matches the product name and
+// description against, and the indexes of its filters. Product names weigh more than
+// descriptions, and no language is set since auctions are listed in more than one
+func migrateAuctionSearch(ctx context.Context, database *mongo.Database) error {
+	auctions := database.Collection("auctions")
+
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "product_name", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().
+				SetName("auction_search_text").
+				SetWeights(bson.M{"product_name": 5, "description": 1}).
+				SetDefaultLanguage("none"),
+		},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "end_time", Value: 1}}},
+		{Keys: bson.D{{Key: "seller_id", Value: 1}, {Key: "end_time", Value: 1}}},
+		{Keys: bson.D{{Key: "condition", Value: 1}}},
+	}
+
+	_, err := auctions.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrateAuctionSettlement indexes orders by bid and marks the auctions closed before settlement
+// was recorded as settled when they have no winner or the winning bid already has an order.
+// Only the auctions whose settlement failed are left to the settlement retries
+func migrateAuctionSettlement(ctx context.Context, database *mongo.Database) error {
+	auctions := database.Collection("auctions")
+	orders := database.Collection("orders")
+
+	index := mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "settled_at", Value: 1}, {Key: "closed_at", Value: 1}},
+	}
+	if _, err := auctions.Indexes().CreateOne(ctx, index); err != nil {
+		return err
+	}
+
+	// Orders are created with an upsert on the bid, so concurrent settlements need the unique
+	// index to store a single order
+	orderIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "bid_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	if _, err := orders.Indexes().CreateOne(ctx, orderIndex); err != nil {
+		return err
+	}
+
+	filter := bson.M{"status": auction_entity.Completed, "settled_at": bson.M{"$exists": false}}
+	cursor, err := auctions.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var auction struct {
+			Id           string `bson:"_id"`
+			WinningBidId string `bson:"winning_bid_id"`
+			ClosedAt     int64  `bson:"closed_at"`
+		}
+		if err := cursor.Decode(&auction); err != nil {
+			return err
+		}
+
+		if auction.WinningBidId != "" {
+			count, err := orders.CountDocuments(ctx, bson.M{"bid_id": auction.WinningBidId})
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				continue
+			}
+		}
+
+		update := bson.M{"$set": bson.M{"settled_at": auction.ClosedAt}}
+		if _, err := auctions.UpdateOne(ctx, bson.M{"_id": auction.Id}, update); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
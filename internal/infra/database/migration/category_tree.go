@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrateCategoryTree creates the indexes the category tree is walked by, and keeps the free-form
+// categories of existing auctions valid by adding them as root categories. Their ids are kept as
+// they were, even when they don't follow the format of new category ids
+func migrateCategoryTree(ctx context.Context, database *mongo.Database) error {
+	categories := database.Collection("categories")
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "parent_id", Value: 1}}},
+		{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+	}
+	if _, err := categories.Indexes().CreateMany(ctx, indexes); err != nil {
+		return err
+	}
+
+	names, err := database.Collection("auctions").Distinct(ctx, "category", bson.M{})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		category, ok := name.(string)
+		if !ok || category == "" {
+			continue
+		}
+
+		update := bson.M{"$setOnInsert": bson.M{
+			"name":       category,
+			"ancestors":  bson.A{},
+			"attributes": bson.A{},
+			"timestamp":  time.Now().Unix(),
+		}}
+		opts := options.Update().SetUpsert(true)
+		if _, err := categories.UpdateByID(ctx, category, update, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// migrateListingPagination counts the bids of the auctions stored before auctions kept their bid
+// count, and creates the indexes the listing sorts and cursors page through
+func migrateListingPagination(ctx context.Context, database *mongo.Database) error {
+	auctions := database.Collection("auctions")
+	bids := database.Collection("bids")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$auction_id"},
+			{Key: "count", Value: bson.M{"$sum": 1}},
+		}}},
+	}
+
+	cursor, err := bids.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var bidCount struct {
+			AuctionId string `bson:"_id"`
+			Count     int64  `bson:"count"`
+		}
+		if err := cursor.Decode(&bidCount); err != nil {
+			return err
+		}
+
+		filter := bson.M{"_id": bidCount.AuctionId, "bid_count": bson.M{"$exists": false}}
+		if _, err := auctions.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"bid_count": bidCount.Count}}); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	filter := bson.M{"bid_count": bson.M{"$exists": false}}
+	if _, err := auctions.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"bid_count": 0}}); err != nil {
+		return err
+	}
+
+	auctionIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "end_time", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "high_bid_amount", Value: -1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "bid_count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	if _, err := auctions.Indexes().CreateMany(ctx, auctionIndexes); err != nil {
+		return err
+	}
+
+	bidIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "auction_id", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "auction_id", Value: 1}, {Key: "amount", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	_, err = bids.Indexes().CreateMany(ctx, bidIndexes)
+	return err
+}
@@ -0,0 +1,67 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migration changes stored documents to match the current code. Migrations must be idempotent,
+// since two instances starting together may both run one before it is recorded
+type Migration struct {
+	Id string
+	Up func(ctx context.Context, database *mongo.Database) error
+}
+
+// migrations run in order, each one once per database
+var migrations = []Migration{
+	{Id: "0001_money_decimal128", Up: migrateMoneyToDecimal128},
+	{Id: "0002_listing_pagination", Up: migrateListingPagination},
+	{Id: "0003_auction_search", Up: migrateAuctionSearch},
+	{Id: "0004_category_tree", Up: migrateCategoryTree},
+	{Id: "0005_auction_bidders", Up: migrateAuctionBidders},
+	{Id: "0006_auction_settlement", Up: migrateAuctionSettlement},
+	{Id: "0007_second_chance_offers", Up: migrateSecondChanceOffers},
+}
+
+type MigrationEntityMongo struct {
+	Id        string `bson:"_id"`
+	AppliedAt int64  `bson:"applied_at"`
+}
+
+// Run applies the migrations that were not recorded in the "schema_migrations" collection yet
+func Run(ctx context.Context, database *mongo.Database) *internal_error.InternalError {
+	collection := database.Collection("schema_migrations")
+
+	for _, migration := range migrations {
+		err := collection.FindOne(ctx, bson.M{"_id": migration.Id}).Err()
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logger.Error("Error trying to find applied migrations", err)
+			return internal_error.NewInternalServerError("Error trying to find applied migrations")
+		}
+
+		logger.Info("Applying migration", zap.String("migration", migration.Id))
+		if err := migration.Up(ctx, database); err != nil {
+			logger.Error("Error trying to apply migration", err, zap.String("migration", migration.Id))
+			return internal_error.NewInternalServerError("Error trying to apply migration " + migration.Id)
+		}
+
+		record := MigrationEntityMongo{Id: migration.Id, AppliedAt: time.Now().Unix()}
+		if _, err := collection.InsertOne(ctx, record); err != nil && !mongo.IsDuplicateKeyError(err) {
+			logger.Error("Error trying to record migration", err, zap.String("migration", migration.Id))
+			return internal_error.NewInternalServerError("Error trying to record migration " + migration.Id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// numericTypes are the BSON types amounts were stored with before Decimal128
+var numericTypes = bson.A{"double", "int", "long"}
+
+// migrateMoneyToDecimal128 converts the amounts stored as doubles into Decimal128 rounded to the
+// minor units of the currency. Documents stored before currencies existed get the default one
+func migrateMoneyToDecimal128(ctx context.Context, database *mongo.Database) error {
+	currency := money_entity.DefaultCurrency()
+	exponent, _ := money_entity.Exponent(currency)
+
+	toDecimal := func(field string) bson.M {
+		return bson.M{"$round": bson.A{bson.M{"$toDecimal": "$" + field}, exponent}}
+	}
+
+	for _, collection := range []string{"bids", "pending_bids"} {
+		filter := bson.M{"amount": bson.M{"$type": numericTypes}}
+		update := mongo.Pipeline{
+			{{Key: "$set", Value: bson.D{
+				{Key: "amount", Value: toDecimal("amount")},
+				{Key: "currency", Value: bson.M{"$ifNull": bson.A{"$currency", currency}}},
+			}}},
+		}
+
+		if _, err := database.Collection(collection).UpdateMany(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+
+	auctions := database.Collection("auctions")
+
+	filter := bson.M{"currency": bson.M{"$exists": false}}
+	if _, err := auctions.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"currency": currency}}); err != nil {
+		return err
+	}
+
+	for _, field := range []string{"high_bid_amount", "final_price"} {
+		filter := bson.M{field: bson.M{"$type": numericTypes}}
+		update := mongo.Pipeline{
+			{{Key: "$set", Value: bson.D{{Key: field, Value: toDecimal(field)}}}},
+		}
+
+		if _, err := auctions.UpdateMany(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/offer_entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrateSecondChanceOffers indexes the offers so each bid gets a single offer and each auction
+// has at most one pending offer, however many instances offer the item at once
+func migrateSecondChanceOffers(ctx context.Context, database *mongo.Database) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "bid_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "auction_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": offer_entity.Pending}),
+		},
+	}
+
+	_, err := database.Collection("second_chance_offers").Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,58 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ToDecimal128 stores the amount in major units as a Decimal128, so MongoDB sorts and compares
+// amounts numerically without the rounding of doubles
+func ToDecimal128(money money_entity.Money) primitive.Decimal128 {
+	exponent, _ := money_entity.Exponent(money.Currency)
+
+	decimal, _ := primitive.ParseDecimal128FromBigInt(big.NewInt(money.Amount), -exponent)
+	return decimal
+}
+
+// FromDecimal128 reads an amount in major units back into minor units of the currency. It fails
+// when the value has more decimal places than the currency
+func FromDecimal128(value primitive.Decimal128, currency string) (money_entity.Money, error) {
+	exponent, ok := money_entity.Exponent(currency)
+	if !ok {
+		return money_entity.Money{}, fmt.Errorf("currency %s is not supported", currency)
+	}
+
+	coefficient, valueExponent, err := value.BigInt()
+	if err != nil {
+		return money_entity.Money{}, err
+	}
+
+	// value = coefficient * 10^valueExponent, and the minor units are value * 10^exponent
+	shift := valueExponent + exponent
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil)
+	if shift >= 0 {
+		coefficient.Mul(coefficient, scale)
+	} else {
+		remainder := new(big.Int)
+		coefficient.QuoRem(coefficient, scale, remainder)
+		if remainder.Sign() != 0 {
+			return money_entity.Money{}, fmt.Errorf("amount %s has more decimal places than %s", value, currency)
+		}
+	}
+
+	if !coefficient.IsInt64() {
+		return money_entity.Money{}, fmt.Errorf("amount %s is out of range", value)
+	}
+
+	return money_entity.New(coefficient.Int64(), currency), nil
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
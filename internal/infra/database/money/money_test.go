@@ -0,0 +1,41 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestDecimal128RoundTrip tests that amounts survive the conversion to and from Decimal128
+func TestDecimal128RoundTrip(t *testing.T) {
+	for _, amount := range []money_entity.Money{
+		money_entity.New(1050, "BRL"),
+		money_entity.New(1, "BRL"),
+		money_entity.New(1500, "JPY"),
+		money_entity.New(1234, "KWD"),
+	} {
+		decimal := ToDecimal128(amount)
+		if decimal.String() != amount.String() {
+			t.Errorf("Decimal128 esperado: %s, recebido: %s", amount.String(), decimal.String())
+		}
+
+		converted, err := FromDecimal128(decimal, amount.Currency)
+		if err != nil || converted != amount {
+			t.Errorf("Valor esperado: %v, recebido: %v (erro: %v)", amount, converted, err)
+		}
+	}
+}
+
+// TestFromDecimal128Scale tests values stored with a different scale, e.g. by the migration
+func TestFromDecimal128Scale(t *testing.T) {
+	decimal, _ := primitive.ParseDecimal128("10.500")
+	if converted, err := FromDecimal128(decimal, "BRL"); err != nil || converted.Amount != 1050 {
+		t.Errorf("Valor esperado: 1050, recebido: %d (erro: %v)", converted.Amount, err)
+	}
+
+	decimal, _ = primitive.ParseDecimal128("10.505")
+	if _, err := FromDecimal128(decimal, "BRL"); err == nil {
+		t.Error("Valores com mais casas decimais que a moeda deveriam falhar")
+	}
+}
@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PreferenceEntityMongo struct {
+	UserId        string   `bson:"_id"`
+	Email         string   `bson:"email"`
+	Channels      []string `bson:"channels"`
+	OutbidAlerts  bool     `bson:"outbid_alerts"`
+	WinnerAlerts  bool     `bson:"winner_alerts"`
+	ClosingAlerts bool     `bson:"closing_alerts"`
+}
+
+type PreferenceRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewPreferenceRepository(database *mongo.Database) *PreferenceRepository {
+	return &PreferenceRepository{
+		Collection: database.Collection("notification_preferences"),
+	}
+}
+
+func (pr *PreferenceRepository) FindPreferenceByUserId(
+	ctx context.Context, userId string) (*notification_entity.Preference, *internal_error.InternalError) {
+	filter := bson.M{"_id": userId}
+
+	var preferenceMongo PreferenceEntityMongo
+	if err := pr.Collection.FindOne(ctx, filter).Decode(&preferenceMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Notification preferences not found for user = %s", userId))
+		}
+
+		logger.Error("Error trying to find notification preferences", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find notification preferences")
+	}
+
+	channels := make([]notification_entity.Channel, 0, len(preferenceMongo.Channels))
+	for _, channel := range preferenceMongo.Channels {
+		channels = append(channels, notification_entity.Channel(channel))
+	}
+
+	return &notification_entity.Preference{
+		UserId:        preferenceMongo.UserId,
+		Email:         preferenceMongo.Email,
+		Channels:      channels,
+		OutbidAlerts:  preferenceMongo.OutbidAlerts,
+		WinnerAlerts:  preferenceMongo.WinnerAlerts,
+		ClosingAlerts: preferenceMongo.ClosingAlerts,
+	}, nil
+}
+
+func (pr *PreferenceRepository) UpsertPreference(
+	ctx context.Context, preference *notification_entity.Preference) *internal_error.InternalError {
+	channels := make([]string, 0, len(preference.Channels))
+	for _, channel := range preference.Channels {
+		channels = append(channels, string(channel))
+	}
+
+	preferenceMongo := &PreferenceEntityMongo{
+		UserId:        preference.UserId,
+		Email:         preference.Email,
+		Channels:      channels,
+		OutbidAlerts:  preference.OutbidAlerts,
+		WinnerAlerts:  preference.WinnerAlerts,
+		ClosingAlerts: preference.ClosingAlerts,
+	}
+
+	filter := bson.M{"_id": preference.UserId}
+	opts := options.Replace().SetUpsert(true)
+	if _, err := pr.Collection.ReplaceOne(ctx, filter, preferenceMongo, opts); err != nil {
+		logger.Error("Error trying to save notification preferences", err)
+		return internal_error.NewInternalServerError("Error trying to save notification preferences")
+	}
+
+	return nil
+}
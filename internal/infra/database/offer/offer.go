@@ -0,0 +1,179 @@
+package offer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/offer_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OfferEntityMongo struct {
+	Id          string                   `bson:"_id"`
+	AuctionId   string                   `bson:"auction_id"`
+	BidId       string                   `bson:"bid_id"`
+	UserId      string                   `bson:"user_id"`
+	Amount      primitive.Decimal128     `bson:"amount"`
+	Currency    string                   `bson:"currency"`
+	Status      offer_entity.OfferStatus `bson:"status"`
+	ExpiresAt   int64                    `bson:"expires_at"`
+	CreatedAt   int64                    `bson:"created_at"`
+	RespondedAt int64                    `bson:"responded_at,omitempty"`
+}
+
+type OfferRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewOfferRepository(database *mongo.Database) *OfferRepository {
+	return &OfferRepository{
+		Collection: database.Collection("second_chance_offers"),
+	}
+}
+
+func (om *OfferEntityMongo) toOffer() (*offer_entity.Offer, *internal_error.InternalError) {
+	amount, err := money.FromDecimal128(om.Amount, om.Currency)
+	if err != nil {
+		logger.Error("Error trying to read offer amount", err, zap.String("offerId", om.Id))
+		return nil, internal_error.NewInternalServerError("Error trying to read offer")
+	}
+
+	offer := &offer_entity.Offer{
+		Id:        om.Id,
+		AuctionId: om.AuctionId,
+		BidId:     om.BidId,
+		UserId:    om.UserId,
+		Amount:    amount,
+		Status:    om.Status,
+		ExpiresAt: time.Unix(om.ExpiresAt, 0),
+		CreatedAt: time.Unix(om.CreatedAt, 0),
+	}
+	if om.RespondedAt != 0 {
+		offer.RespondedAt = time.Unix(om.RespondedAt, 0)
+	}
+
+	return offer, nil
+}
+
+// CreateOffer inserts the offer unless the bid already has one, so the same bidder isn't
+// offered the item twice. The unique indexes on the bid and on the pending offer of each
+// auction keep concurrent calls from storing a second offer
+func (or *OfferRepository) CreateOffer(
+	ctx context.Context, offer *offer_entity.Offer) (*offer_entity.Offer, *internal_error.InternalError) {
+	offerMongo := &OfferEntityMongo{
+		Id:        offer.Id,
+		AuctionId: offer.AuctionId,
+		BidId:     offer.BidId,
+		UserId:    offer.UserId,
+		Amount:    money.ToDecimal128(offer.Amount),
+		Currency:  offer.Amount.Currency,
+		Status:    offer.Status,
+		ExpiresAt: offer.ExpiresAt.Unix(),
+		CreatedAt: offer.CreatedAt.Unix(),
+	}
+
+	filter := bson.M{"bid_id": offer.BidId}
+	update := bson.M{"$setOnInsert": offerMongo}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var storedMongo OfferEntityMongo
+	err := or.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&storedMongo)
+	if mongo.IsDuplicateKeyError(err) {
+		// Either a concurrent call stored the offer of the bid first, or another offer of the
+		// auction is pending
+		err = or.Collection.FindOne(ctx, filter).Decode(&storedMongo)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewBadRequestError("The item is already offered to another bidder")
+		}
+	}
+	if err != nil {
+		logger.Error("Error trying to create offer", err, zap.String("bidId", offer.BidId))
+		return nil, internal_error.NewInternalServerError("Error trying to create offer")
+	}
+
+	return storedMongo.toOffer()
+}
+
+func (or *OfferRepository) FindOfferById(
+	ctx context.Context, id string) (*offer_entity.Offer, *internal_error.InternalError) {
+	var offerMongo OfferEntityMongo
+	if err := or.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&offerMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Offer not found with this id = %s", id))
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find offer by id = %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find offer by id")
+	}
+
+	return offerMongo.toOffer()
+}
+
+func (or *OfferRepository) FindOffersByAuctionId(
+	ctx context.Context, auctionId string) ([]offer_entity.Offer, *internal_error.InternalError) {
+	return or.findOffers(ctx, bson.M{"auction_id": auctionId})
+}
+
+func (or *OfferRepository) FindOffersByUserId(
+	ctx context.Context, userId string) ([]offer_entity.Offer, *internal_error.InternalError) {
+	return or.findOffers(ctx, bson.M{"user_id": userId})
+}
+
+func (or *OfferRepository) FindPendingOffersExpiredBefore(
+	ctx context.Context, before time.Time) ([]offer_entity.Offer, *internal_error.InternalError) {
+	return or.findOffers(ctx, bson.M{"status": offer_entity.Pending, "expires_at": bson.M{"$lt": before.Unix()}})
+}
+
+func (or *OfferRepository) findOffers(
+	ctx context.Context, filter bson.M) ([]offer_entity.Offer, *internal_error.InternalError) {
+	cursor, err := or.Collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		logger.Error("Error trying to find offers", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find offers")
+	}
+	defer cursor.Close(ctx)
+
+	var offersMongo []OfferEntityMongo
+	if err := cursor.All(ctx, &offersMongo); err != nil {
+		logger.Error("Error trying to find offers", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find offers")
+	}
+
+	offers := make([]offer_entity.Offer, 0, len(offersMongo))
+	for _, offerMongo := range offersMongo {
+		offer, err := offerMongo.toOffer()
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, *offer)
+	}
+
+	return offers, nil
+}
+
+func (or *OfferRepository) UpdateOfferStatus(
+	ctx context.Context,
+	id string,
+	from, to offer_entity.OfferStatus,
+	at time.Time) (bool, *internal_error.InternalError) {
+	update := bson.M{"$set": bson.M{"status": to, "responded_at": at.Unix()}}
+
+	result, err := or.Collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, update)
+	if err != nil {
+		logger.Error("Error trying to update offer status", err, zap.String("offerId", id))
+		return false, internal_error.NewInternalServerError("Error trying to update offer status")
+	}
+
+	return result.ModifiedCount > 0, nil
+}
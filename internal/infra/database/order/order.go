@@ -0,0 +1,221 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OrderEntityMongo struct {
+	Id               string                   `bson:"_id"`
+	AuctionId        string                   `bson:"auction_id"`
+	BidId            string                   `bson:"bid_id"`
+	BuyerId          string                   `bson:"buyer_id"`
+	SellerId         string                   `bson:"seller_id,omitempty"`
+	ProductName      string                   `bson:"product_name"`
+	FinalPrice       primitive.Decimal128     `bson:"final_price"`
+	SellerFee        primitive.Decimal128     `bson:"seller_fee"`
+	BuyerPremium     primitive.Decimal128     `bson:"buyer_premium"`
+	Prepaid          primitive.Decimal128     `bson:"prepaid"`
+	Currency         string                   `bson:"currency"`
+	Status           order_entity.OrderStatus `bson:"status"`
+	PaymentReference string                   `bson:"payment_reference,omitempty"`
+	DueAt            int64                    `bson:"due_at"`
+	CreatedAt        int64                    `bson:"created_at"`
+	PaidAt           int64                    `bson:"paid_at,omitempty"`
+}
+
+type OrderRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewOrderRepository(database *mongo.Database) *OrderRepository {
+	return &OrderRepository{
+		Collection: database.Collection("orders"),
+	}
+}
+
+func (om *OrderEntityMongo) toOrder() (*order_entity.Order, *internal_error.InternalError) {
+	order := &order_entity.Order{
+		Id:               om.Id,
+		AuctionId:        om.AuctionId,
+		BidId:            om.BidId,
+		BuyerId:          om.BuyerId,
+		SellerId:         om.SellerId,
+		ProductName:      om.ProductName,
+		Status:           om.Status,
+		PaymentReference: om.PaymentReference,
+		DueAt:            time.Unix(om.DueAt, 0),
+		CreatedAt:        time.Unix(om.CreatedAt, 0),
+	}
+	if om.PaidAt != 0 {
+		order.PaidAt = time.Unix(om.PaidAt, 0)
+	}
+
+	for _, field := range []struct {
+		value  primitive.Decimal128
+		target *money_entity.Money
+	}{
+		{om.FinalPrice, &order.FinalPrice},
+		{om.SellerFee, &order.SellerFee},
+		{om.BuyerPremium, &order.BuyerPremium},
+		{om.Prepaid, &order.Prepaid},
+	} {
+		amount, err := money.FromDecimal128(field.value, om.Currency)
+		if err != nil {
+			logger.Error("Error trying to read order amount", err, zap.String("orderId", om.Id))
+			return nil, internal_error.NewInternalServerError("Error trying to read order")
+		}
+		*field.target = amount
+	}
+
+	return order, nil
+}
+
+// CreateOrder inserts the order unless the bid already has one, so settling an auction twice
+// doesn't sell the item twice. The unique index on bid_id makes concurrent settlements of the
+// same bid return the order stored by the first one
+func (or *OrderRepository) CreateOrder(
+	ctx context.Context, order *order_entity.Order) (*order_entity.Order, *internal_error.InternalError) {
+	orderMongo := &OrderEntityMongo{
+		Id:               order.Id,
+		AuctionId:        order.AuctionId,
+		BidId:            order.BidId,
+		BuyerId:          order.BuyerId,
+		SellerId:         order.SellerId,
+		ProductName:      order.ProductName,
+		FinalPrice:       money.ToDecimal128(order.FinalPrice),
+		SellerFee:        money.ToDecimal128(order.SellerFee),
+		BuyerPremium:     money.ToDecimal128(order.BuyerPremium),
+		Prepaid:          money.ToDecimal128(order.Prepaid),
+		Currency:         order.FinalPrice.Currency,
+		Status:           order.Status,
+		PaymentReference: order.PaymentReference,
+		DueAt:            order.DueAt.Unix(),
+		CreatedAt:        order.CreatedAt.Unix(),
+	}
+	if !order.PaidAt.IsZero() {
+		orderMongo.PaidAt = order.PaidAt.Unix()
+	}
+
+	filter := bson.M{"bid_id": order.BidId}
+	update := bson.M{"$setOnInsert": orderMongo}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var storedMongo OrderEntityMongo
+	err := or.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&storedMongo)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent settlement inserted the order first
+		err = or.Collection.FindOne(ctx, filter).Decode(&storedMongo)
+	}
+	if err != nil {
+		logger.Error("Error trying to create order", err, zap.String("bidId", order.BidId))
+		return nil, internal_error.NewInternalServerError("Error trying to create order")
+	}
+
+	return storedMongo.toOrder()
+}
+
+func (or *OrderRepository) FindOrderById(
+	ctx context.Context, id string) (*order_entity.Order, *internal_error.InternalError) {
+	var orderMongo OrderEntityMongo
+	if err := or.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&orderMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Order not found with this id = %s", id))
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find order by id = %s", id), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find order by id")
+	}
+
+	return orderMongo.toOrder()
+}
+
+func (or *OrderRepository) FindOrdersByBuyerId(
+	ctx context.Context, buyerId string) ([]order_entity.Order, *internal_error.InternalError) {
+	return or.findOrders(ctx, bson.M{"buyer_id": buyerId})
+}
+
+func (or *OrderRepository) FindOrdersByAuctionId(
+	ctx context.Context, auctionId string) ([]order_entity.Order, *internal_error.InternalError) {
+	return or.findOrders(ctx, bson.M{"auction_id": auctionId})
+}
+
+func (or *OrderRepository) FindOrdersDueBefore(
+	ctx context.Context,
+	status order_entity.OrderStatus,
+	before time.Time) ([]order_entity.Order, *internal_error.InternalError) {
+	return or.findOrders(ctx, bson.M{"status": status, "due_at": bson.M{"$lt": before.Unix()}})
+}
+
+func (or *OrderRepository) findOrders(
+	ctx context.Context, filter bson.M) ([]order_entity.Order, *internal_error.InternalError) {
+	cursor, err := or.Collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		logger.Error("Error trying to find orders", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find orders")
+	}
+	defer cursor.Close(ctx)
+
+	var ordersMongo []OrderEntityMongo
+	if err := cursor.All(ctx, &ordersMongo); err != nil {
+		logger.Error("Error trying to find orders", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find orders")
+	}
+
+	orders := make([]order_entity.Order, 0, len(ordersMongo))
+	for _, orderMongo := range ordersMongo {
+		order, err := orderMongo.toOrder()
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+
+	return orders, nil
+}
+
+func (or *OrderRepository) UpdateOrderStatus(
+	ctx context.Context,
+	id string,
+	from, to order_entity.OrderStatus,
+	at time.Time) (bool, *internal_error.InternalError) {
+	set := bson.M{"status": to}
+	if to == order_entity.Paid {
+		set["paid_at"] = at.Unix()
+	}
+
+	result, err := or.Collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, bson.M{"$set": set})
+	if err != nil {
+		logger.Error("Error trying to update order status", err, zap.String("orderId", id))
+		return false, internal_error.NewInternalServerError("Error trying to update order status")
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+func (or *OrderRepository) UpdatePaymentReference(
+	ctx context.Context, id, paymentReference string) *internal_error.InternalError {
+	update := bson.M{"$set": bson.M{"payment_reference": paymentReference}}
+
+	if _, err := or.Collection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		logger.Error("Error trying to update order payment reference", err, zap.String("orderId", id))
+		return internal_error.NewInternalServerError("Error trying to update order payment reference")
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package pagination
+
+import (
+	"encoding/base64"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SortKey is the field a listing is sorted by. Documents with the same value are sorted by _id,
+// so every document has a single position and pages never skip or repeat one
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+type cursorMongo struct {
+	Value bson.RawValue `bson:"v"`
+	Id    string        `bson:"id"`
+}
+
+// FindOptions sorts the listing and fetches one document past the page, which tells whether
+// there is a next page
+func (k SortKey) FindOptions(page pagination_entity.PageRequest) *options.FindOptions {
+	direction := 1
+	if k.Descending {
+		direction = -1
+	}
+
+	return options.Find().
+		SetSort(bson.D{{Key: k.Field, Value: direction}, {Key: "_id", Value: 1}}).
+		SetLimit(page.Limit + 1)
+}
+
+// AfterCursor returns the filter of the documents sorted after the cursor, or nil for the first
+// page. Documents missing the field sort first in ascending order and last in descending order
+func (k SortKey) AfterCursor(page pagination_entity.PageRequest) (bson.M, *internal_error.InternalError) {
+	if page.Cursor == "" {
+		return nil, nil
+	}
+
+	cursor, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	sameValueAfterId := bson.M{k.Field: cursor.Value, "_id": bson.M{"$gt": cursor.Id}}
+	if cursor.Value.Type == bson.TypeNull {
+		sameValueAfterId[k.Field] = nil
+		if k.Descending {
+			return sameValueAfterId, nil
+		}
+
+		return bson.M{"$or": bson.A{sameValueAfterId, bson.M{k.Field: bson.M{"$ne": nil}}}}, nil
+	}
+
+	operator := "$gt"
+	if k.Descending {
+		operator = "$lt"
+	}
+
+	after := bson.A{bson.M{k.Field: bson.M{operator: cursor.Value}}, sameValueAfterId}
+	if k.Descending {
+		after = append(after, bson.M{k.Field: nil})
+	}
+
+	return bson.M{"$or": after}, nil
+}
+
+// EncodeCursor returns the cursor of the page starting after the document with the given sort
+// value and id. A nil value stands for a document missing the field
+func EncodeCursor(value any, id string) string {
+	content, err := bson.Marshal(bson.D{{Key: "v", Value: value}, {Key: "id", Value: id}})
+	if err != nil {
+		logger.Error("Error trying to encode page cursor", err)
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+func decodeCursor(token string) (*cursorMongo, *internal_error.InternalError) {
+	content, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, internal_error.NewBadRequestError("Invalid page cursor")
+	}
+
+	var cursor cursorMongo
+	if err := bson.Unmarshal(content, &cursor); err != nil || cursor.Id == "" || !cursorValueTypes[cursor.Value.Type] {
+		return nil, internal_error.NewBadRequestError("Invalid page cursor")
+	}
+
+	return &cursor, nil
+}
+
+// cursorValueTypes are the types of the values listings are sorted by. Any other value, such as
+// a document holding query operators, is rejected before it reaches a filter
+var cursorValueTypes = map[bsontype.Type]bool{
+	bson.TypeInt64:      true,
+	bson.TypeString:     true,
+	bson.TypeDecimal128: true,
+	bson.TypeNull:       true,
+}
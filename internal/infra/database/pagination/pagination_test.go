@@ -0,0 +1,55 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestAfterCursor tests that cursors resume after the last document, including the documents
+// with the same value and the ones missing the field
+func TestAfterCursor(t *testing.T) {
+	descending := SortKey{Field: "bid_count", Descending: true}
+
+	filter, err := descending.AfterCursor(pagination_entity.NewPageRequest(10, ""))
+	if err != nil || filter != nil {
+		t.Fatalf("Primeira página não deveria ter filtro: %v", err)
+	}
+
+	filter, err = descending.AfterCursor(pagination_entity.NewPageRequest(10, EncodeCursor(int64(3), "b")))
+	if err != nil {
+		t.Fatalf("Erro ao ler cursor: %v", err)
+	}
+	if after, ok := filter["$or"].(bson.A); !ok || len(after) != 3 {
+		t.Errorf("Esperado filtro por menor valor, mesmo valor com id maior ou campo ausente, recebido %v", filter)
+	}
+
+	filter, err = descending.AfterCursor(pagination_entity.NewPageRequest(10, EncodeCursor(nil, "b")))
+	if err != nil {
+		t.Fatalf("Erro ao ler cursor: %v", err)
+	}
+	if filter["bid_count"] != nil || filter["_id"] == nil {
+		t.Errorf("Cursor sem valor deveria continuar entre os documentos sem o campo, recebido %v", filter)
+	}
+
+	if _, err := descending.AfterCursor(pagination_entity.NewPageRequest(10, "not-a-cursor")); err == nil {
+		t.Error("Cursor inválido deveria ser rejeitado")
+	}
+
+	operatorCursor := EncodeCursor(bson.M{"$ne": nil}, "b")
+	if _, err := descending.AfterCursor(pagination_entity.NewPageRequest(10, operatorCursor)); err == nil {
+		t.Error("Cursor com valor que não é de ordenação deveria ser rejeitado")
+	}
+}
+
+// TestNewPageRequest tests the default and maximum page sizes
+func TestNewPageRequest(t *testing.T) {
+	if page := pagination_entity.NewPageRequest(0, ""); page.Limit != pagination_entity.DefaultLimit {
+		t.Errorf("Esperado limite padrão %d, recebido %d", pagination_entity.DefaultLimit, page.Limit)
+	}
+	if page := pagination_entity.NewPageRequest(1000, ""); page.Limit != pagination_entity.MaxLimit {
+		t.Errorf("Esperado limite máximo %d, recebido %d", pagination_entity.MaxLimit, page.Limit)
+	}
+}
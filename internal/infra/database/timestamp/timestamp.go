@@ -0,0 +1,18 @@
+package timestamp
+
+import "time"
+
+// secondsLimit tells the timestamps stored in seconds apart from the ones in milliseconds: it is
+// in the year 5138 in seconds and in 1973 in milliseconds
+const secondsLimit = 100_000_000_000
+
+// FromMillis reads a bid timestamp stored in milliseconds. Bids stored before bids were ranked
+// by the millisecond keep their timestamp in seconds, which are always lower than any timestamp
+// in milliseconds, so sorting by the stored value still puts them first
+func FromMillis(value int64) time.Time {
+	if value < secondsLimit {
+		return time.Unix(value, 0)
+	}
+
+	return time.UnixMilli(value)
+}
@@ -0,0 +1,21 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFromMillis tests that timestamps are read in milliseconds and the ones stored in seconds
+// are still read correctly
+func TestFromMillis(t *testing.T) {
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 250*int(time.Millisecond), time.UTC)
+
+	if read := FromMillis(placedAt.UnixMilli()); !read.Equal(placedAt) {
+		t.Errorf("Esperado %v, recebido %v", placedAt, read)
+	}
+
+	placedAtSecond := placedAt.Truncate(time.Second)
+	if read := FromMillis(placedAtSecond.Unix()); !read.Equal(placedAtSecond) {
+		t.Errorf("Esperado %v para timestamp em segundos, recebido %v", placedAtSecond, read)
+	}
+}
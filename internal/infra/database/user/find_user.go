@@ -14,8 +14,9 @@ import (
 )
 
 type UserEntityMongo struct {
-	Id   string `bson:"_id"`
-	Name string `bson:"name"`
+	Id   string               `bson:"_id"`
+	Name string               `bson:"name"`
+	Role user_entity.UserRole `bson:"role,omitempty"`
 }
 
 type UserRepository struct {
@@ -48,6 +49,12 @@ func (ur *UserRepository) FindUserById(
 	userEntity := &user_entity.User{
 		Id:   userEntityMongo.Id,
 		Name: userEntityMongo.Name,
+		Role: userEntityMongo.Role,
+	}
+
+	// Users stored before roles existed are regular users
+	if userEntity.Role == "" {
+		userEntity.Role = user_entity.RegularRole
 	}
 
 	return userEntity, nil
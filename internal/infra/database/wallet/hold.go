@@ -0,0 +1,311 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HoldEntityMongo is the hold of a user on an auction, identified by both ids
+type HoldEntityMongo struct {
+	Id        string                   `bson:"_id"`
+	UserId    string                   `bson:"user_id"`
+	AuctionId string                   `bson:"auction_id"`
+	BidId     string                   `bson:"bid_id"`
+	Amount    primitive.Decimal128     `bson:"amount"`
+	Currency  string                   `bson:"currency"`
+	Status    wallet_entity.HoldStatus `bson:"status"`
+	CreatedAt int64                    `bson:"created_at"`
+	UpdatedAt int64                    `bson:"updated_at"`
+}
+
+func holdId(userId, auctionId string) string {
+	return auctionId + ":" + userId
+}
+
+func (hm *HoldEntityMongo) toHold() (*wallet_entity.Hold, *internal_error.InternalError) {
+	amount, err := money.FromDecimal128(hm.Amount, hm.Currency)
+	if err != nil {
+		logger.Error("Error trying to read hold amount", err, zap.String("holdId", hm.Id))
+		return nil, internal_error.NewInternalServerError("Error trying to read hold")
+	}
+
+	return &wallet_entity.Hold{
+		UserId:    hm.UserId,
+		AuctionId: hm.AuctionId,
+		BidId:     hm.BidId,
+		Amount:    amount,
+		Status:    hm.Status,
+		CreatedAt: time.Unix(hm.CreatedAt, 0),
+		UpdatedAt: time.Unix(hm.UpdatedAt, 0),
+	}, nil
+}
+
+func (wr *WalletRepository) FindHold(
+	ctx context.Context, userId, auctionId string) (*wallet_entity.Hold, *internal_error.InternalError) {
+	filter := bson.M{"_id": holdId(userId, auctionId), "status": wallet_entity.HoldActive}
+
+	var holdMongo HoldEntityMongo
+	if err := wr.HoldCollection.FindOne(ctx, filter).Decode(&holdMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.Error("Error trying to find hold", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find hold")
+	}
+
+	return holdMongo.toHold()
+}
+
+// HoldFunds first reserves the difference to the current hold in the wallet, with a single
+// conditional update, and then moves the hold to the new bid. Without a multi-document
+// transaction the hold only moves while it is still the one that was read, otherwise the
+// reserved credit is given back
+func (wr *WalletRepository) HoldFunds(
+	ctx context.Context, hold wallet_entity.Hold) *internal_error.InternalError {
+	currentHold, err := wr.FindHold(ctx, hold.UserId, hold.AuctionId)
+	if err != nil {
+		return err
+	}
+
+	difference := hold.Amount
+	if currentHold != nil {
+		difference = hold.Amount.Subtract(currentHold.Amount)
+	}
+	if !difference.IsPositive() {
+		return nil
+	}
+
+	if err := wr.reserveCredit(ctx, hold.UserId, difference); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	holdFilter := bson.M{"_id": holdId(hold.UserId, hold.AuctionId)}
+	if currentHold != nil {
+		holdFilter["status"] = wallet_entity.HoldActive
+		holdFilter["bid_id"] = currentHold.BidId
+	} else {
+		holdFilter["status"] = bson.M{"$ne": wallet_entity.HoldActive}
+	}
+	holdUpdate := bson.M{
+		"$set": bson.M{
+			"user_id":    hold.UserId,
+			"auction_id": hold.AuctionId,
+			"bid_id":     hold.BidId,
+			"amount":     money.ToDecimal128(hold.Amount),
+			"currency":   hold.Amount.Currency,
+			"status":     wallet_entity.HoldActive,
+			"created_at": now,
+			"updated_at": now,
+		},
+	}
+
+	// A concurrent change makes the upsert collide with the active hold on the same id
+	result, updateErr := wr.HoldCollection.UpdateOne(ctx, holdFilter, holdUpdate, options.Update().SetUpsert(true))
+	if updateErr == nil && (result.MatchedCount > 0 || result.UpsertedCount > 0) {
+		return nil
+	}
+	if updateErr != nil && !mongo.IsDuplicateKeyError(updateErr) {
+		logger.Error("Error trying to hold funds", updateErr, zap.String("bidId", hold.BidId))
+	}
+
+	zero := money_entity.New(0, difference.Currency)
+	if err := wr.adjustWallet(ctx, hold.UserId, negate(difference), zero); err != nil {
+		return err
+	}
+
+	return internal_error.NewInternalServerError("Error trying to hold funds")
+}
+
+// reserveCredit adds the amount to the held funds only when the available credit covers it
+func (wr *WalletRepository) reserveCredit(
+	ctx context.Context, userId string, amount money_entity.Money) *internal_error.InternalError {
+	filter := bson.M{
+		"_id": walletId(userId, amount.Currency),
+		"$expr": bson.M{
+			"$gte": bson.A{
+				bson.M{"$subtract": bson.A{bson.M{"$add": bson.A{"$balance", "$credit_limit"}}, "$held"}},
+				money.ToDecimal128(amount),
+			},
+		},
+	}
+	update := bson.M{
+		"$inc": bson.M{"held": money.ToDecimal128(amount)},
+		"$set": bson.M{"updated_at": time.Now().Unix()},
+	}
+
+	result, err := wr.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error("Error trying to reserve credit", err, zap.String("userId", userId))
+		return internal_error.NewInternalServerError("Error trying to reserve credit")
+	}
+
+	if result.MatchedCount == 0 {
+		return wallet_entity.NewInsufficientCreditError()
+	}
+
+	return nil
+}
+
+// adjustWallet increments the held funds and the balance of the wallet
+func (wr *WalletRepository) adjustWallet(
+	ctx context.Context, userId string, held, balance money_entity.Money) *internal_error.InternalError {
+	filter := bson.M{"_id": walletId(userId, held.Currency)}
+	update := bson.M{
+		"$inc": bson.M{
+			"held":    money.ToDecimal128(held),
+			"balance": money.ToDecimal128(balance),
+		},
+		"$set": bson.M{"updated_at": time.Now().Unix()},
+	}
+
+	if _, err := wr.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.Error("Error trying to update wallet", err, zap.String("userId", userId))
+		return internal_error.NewInternalServerError("Error trying to update wallet")
+	}
+
+	return nil
+}
+
+func negate(amount money_entity.Money) money_entity.Money {
+	return money_entity.New(-amount.Amount, amount.Currency)
+}
+
+// moveHold moves a hold in the from status to the given status, returning it as it was. It
+// returns nil when no hold in the from status matched the filter
+func (wr *WalletRepository) moveHold(
+	ctx context.Context,
+	filter bson.M,
+	from, status wallet_entity.HoldStatus) (*wallet_entity.Hold, *internal_error.InternalError) {
+	filter["status"] = from
+	update := bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"updated_at": time.Now().Unix(),
+		},
+	}
+
+	var holdMongo HoldEntityMongo
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	if err := wr.HoldCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&holdMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.Error("Error trying to update hold", err)
+		return nil, internal_error.NewInternalServerError("Error trying to update hold")
+	}
+
+	return holdMongo.toHold()
+}
+
+func (wr *WalletRepository) ReleaseHold(
+	ctx context.Context, userId, auctionId, bidId string) *internal_error.InternalError {
+	hold, err := wr.moveHold(ctx, bson.M{"_id": holdId(userId, auctionId), "bid_id": bidId},
+		wallet_entity.HoldActive, wallet_entity.HoldReleased)
+	if err != nil || hold == nil {
+		return err
+	}
+
+	return wr.adjustWallet(ctx, userId, negate(hold.Amount), money_entity.New(0, hold.Amount.Currency))
+}
+
+// SettleHold takes the held amount out of the balance and records the settlement
+func (wr *WalletRepository) SettleHold(
+	ctx context.Context, userId, auctionId string) (*wallet_entity.Hold, *internal_error.InternalError) {
+	hold, err := wr.moveHold(ctx, bson.M{"_id": holdId(userId, auctionId)},
+		wallet_entity.HoldActive, wallet_entity.HoldSettled)
+	if err != nil || hold == nil {
+		return nil, err
+	}
+
+	if err := wr.adjustWallet(ctx, userId, negate(hold.Amount), negate(hold.Amount)); err != nil {
+		return nil, err
+	}
+
+	if err := wr.recordTransaction(ctx, wallet_entity.Transaction{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		Type:      wallet_entity.SettlementTransaction,
+		Amount:    hold.Amount,
+		Reference: auctionId,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	hold.Status = wallet_entity.HoldSettled
+	return hold, nil
+}
+
+// RefundSettledHold gives the settled amount back to the balance and records the refund. The
+// hold moves from settled to refunded first, so the amount is refunded once
+func (wr *WalletRepository) RefundSettledHold(
+	ctx context.Context, userId, auctionId string) (*wallet_entity.Hold, *internal_error.InternalError) {
+	hold, err := wr.moveHold(ctx, bson.M{"_id": holdId(userId, auctionId)},
+		wallet_entity.HoldSettled, wallet_entity.HoldRefunded)
+	if err != nil || hold == nil {
+		return nil, err
+	}
+
+	if err := wr.adjustWallet(ctx, userId, money_entity.New(0, hold.Amount.Currency), hold.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := wr.recordTransaction(ctx, wallet_entity.Transaction{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		Type:      wallet_entity.RefundTransaction,
+		Amount:    hold.Amount,
+		Reference: auctionId,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	hold.Status = wallet_entity.HoldRefunded
+	return hold, nil
+}
+
+// ReleaseAuctionHolds releases every hold still active on the auction, e.g. the ones left by
+// bidders that didn't win it
+func (wr *WalletRepository) ReleaseAuctionHolds(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	filter := bson.M{"auction_id": auctionId, "status": wallet_entity.HoldActive}
+
+	cursor, err := wr.HoldCollection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to find auction holds", err)
+		return internal_error.NewInternalServerError("Error trying to find auction holds")
+	}
+	defer cursor.Close(ctx)
+
+	var holdsMongo []HoldEntityMongo
+	if err := cursor.All(ctx, &holdsMongo); err != nil {
+		logger.Error("Error trying to find auction holds", err)
+		return internal_error.NewInternalServerError("Error trying to find auction holds")
+	}
+
+	for _, holdMongo := range holdsMongo {
+		if err := wr.ReleaseHold(ctx, holdMongo.UserId, auctionId, holdMongo.BidId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
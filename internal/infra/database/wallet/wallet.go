@@ -0,0 +1,206 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/database/money"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WalletEntityMongo is the wallet of a user in one currency. Balance, credit limit and held
+// amounts are always present, so conditional updates can compute the available credit
+type WalletEntityMongo struct {
+	Id          string               `bson:"_id"`
+	UserId      string               `bson:"user_id"`
+	Currency    string               `bson:"currency"`
+	Balance     primitive.Decimal128 `bson:"balance"`
+	CreditLimit primitive.Decimal128 `bson:"credit_limit"`
+	Held        primitive.Decimal128 `bson:"held"`
+	UpdatedAt   int64                `bson:"updated_at"`
+}
+
+type TransactionEntityMongo struct {
+	Id        string                        `bson:"_id"`
+	UserId    string                        `bson:"user_id"`
+	Type      wallet_entity.TransactionType `bson:"type"`
+	Amount    primitive.Decimal128          `bson:"amount"`
+	Currency  string                        `bson:"currency"`
+	Reference string                        `bson:"reference,omitempty"`
+	CreatedAt int64                         `bson:"created_at"`
+}
+
+type WalletRepository struct {
+	Collection             *mongo.Collection
+	HoldCollection         *mongo.Collection
+	TransactionsCollection *mongo.Collection
+}
+
+func NewWalletRepository(database *mongo.Database) *WalletRepository {
+	return &WalletRepository{
+		Collection:             database.Collection("wallets"),
+		HoldCollection:         database.Collection("wallet_holds"),
+		TransactionsCollection: database.Collection("wallet_transactions"),
+	}
+}
+
+func walletId(userId, currency string) string {
+	return userId + ":" + currency
+}
+
+func (wm *WalletEntityMongo) toWallet() (*wallet_entity.Wallet, *internal_error.InternalError) {
+	wallet := wallet_entity.NewWallet(wm.UserId, wm.Currency)
+	wallet.UpdatedAt = time.Unix(wm.UpdatedAt, 0)
+
+	for _, field := range []struct {
+		value  primitive.Decimal128
+		target *money_entity.Money
+	}{
+		{wm.Balance, &wallet.Balance},
+		{wm.CreditLimit, &wallet.CreditLimit},
+		{wm.Held, &wallet.Held},
+	} {
+		amount, err := money.FromDecimal128(field.value, wm.Currency)
+		if err != nil {
+			logger.Error("Error trying to read wallet amount", err, zap.String("walletId", wm.Id))
+			return nil, internal_error.NewInternalServerError("Error trying to read wallet")
+		}
+		*field.target = amount
+	}
+
+	return wallet, nil
+}
+
+func (wr *WalletRepository) FindWallet(
+	ctx context.Context, userId, currency string) (*wallet_entity.Wallet, *internal_error.InternalError) {
+	var walletMongo WalletEntityMongo
+	if err := wr.Collection.FindOne(ctx, bson.M{"_id": walletId(userId, currency)}).Decode(&walletMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return wallet_entity.NewWallet(userId, currency), nil
+		}
+
+		logger.Error("Error trying to find wallet", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find wallet")
+	}
+
+	return walletMongo.toWallet()
+}
+
+func (wr *WalletRepository) FindWallets(
+	ctx context.Context, userId string) ([]wallet_entity.Wallet, *internal_error.InternalError) {
+	cursor, err := wr.Collection.Find(ctx, bson.M{"user_id": userId}, options.Find().SetSort(bson.M{"currency": 1}))
+	if err != nil {
+		logger.Error("Error trying to find wallets", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find wallets")
+	}
+	defer cursor.Close(ctx)
+
+	var walletsMongo []WalletEntityMongo
+	if err := cursor.All(ctx, &walletsMongo); err != nil {
+		logger.Error("Error trying to find wallets", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find wallets")
+	}
+
+	wallets := make([]wallet_entity.Wallet, 0, len(walletsMongo))
+	for _, walletMongo := range walletsMongo {
+		wallet, err := walletMongo.toWallet()
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, *wallet)
+	}
+
+	return wallets, nil
+}
+
+// Deposit credits the wallet and records the transaction
+func (wr *WalletRepository) Deposit(
+	ctx context.Context, transaction wallet_entity.Transaction) *internal_error.InternalError {
+	if err := wr.updateWallet(ctx, transaction.UserId, transaction.Amount.Currency, bson.M{
+		"$inc": bson.M{"balance": money.ToDecimal128(transaction.Amount)},
+	}); err != nil {
+		return err
+	}
+
+	return wr.recordTransaction(ctx, transaction)
+}
+
+func (wr *WalletRepository) UpdateCreditLimit(
+	ctx context.Context,
+	userId string,
+	creditLimit money_entity.Money) *internal_error.InternalError {
+	return wr.updateWallet(ctx, userId, creditLimit.Currency, bson.M{
+		"$set": bson.M{"credit_limit": money.ToDecimal128(creditLimit)},
+	})
+}
+
+// updateWallet applies the update, creating the wallet with zeroed amounts when needed
+func (wr *WalletRepository) updateWallet(
+	ctx context.Context, userId, currency string, update bson.M) *internal_error.InternalError {
+	zero := money.ToDecimal128(money_entity.New(0, currency))
+
+	setOnInsert := bson.M{"user_id": userId, "currency": currency}
+	for _, field := range []string{"balance", "credit_limit", "held"} {
+		if !updatesField(update, field) {
+			setOnInsert[field] = zero
+		}
+	}
+
+	update["$setOnInsert"] = setOnInsert
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+	}
+	set["updated_at"] = time.Now().Unix()
+	update["$set"] = set
+
+	filter := bson.M{"_id": walletId(userId, currency)}
+	if _, err := wr.Collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		logger.Error("Error trying to update wallet", err, zap.String("userId", userId))
+		return internal_error.NewInternalServerError("Error trying to update wallet")
+	}
+
+	return nil
+}
+
+func updatesField(update bson.M, field string) bool {
+	for _, operator := range []string{"$inc", "$set"} {
+		if fields, ok := update[operator].(bson.M); ok {
+			if _, ok := fields[field]; ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (wr *WalletRepository) recordTransaction(
+	ctx context.Context, transaction wallet_entity.Transaction) *internal_error.InternalError {
+	transactionMongo := &TransactionEntityMongo{
+		Id:        transaction.Id,
+		UserId:    transaction.UserId,
+		Type:      transaction.Type,
+		Amount:    money.ToDecimal128(transaction.Amount),
+		Currency:  transaction.Amount.Currency,
+		Reference: transaction.Reference,
+		CreatedAt: transaction.CreatedAt.Unix(),
+	}
+
+	if _, err := wr.TransactionsCollection.InsertOne(ctx, transactionMongo); err != nil {
+		logger.Error("Error trying to record wallet transaction", err, zap.String("userId", transaction.UserId))
+		return internal_error.NewInternalServerError("Error trying to record wallet transaction")
+	}
+
+	return nil
+}
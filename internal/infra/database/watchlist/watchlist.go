@@ -0,0 +1,142 @@
+package watchlist
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/watchlist_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WatchlistEntityMongo struct {
+	Id        string `bson:"_id"`
+	UserId    string `bson:"user_id"`
+	AuctionId string `bson:"auction_id"`
+	Timestamp int64  `bson:"timestamp"`
+}
+
+type WatchlistRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewWatchlistRepository(database *mongo.Database) *WatchlistRepository {
+	return &WatchlistRepository{
+		Collection: database.Collection("watchlists"),
+	}
+}
+
+// watchlistItemId builds a deterministic id so watching the same auction twice is a no-op
+func watchlistItemId(userId, auctionId string) string {
+	return fmt.Sprintf("%s:%s", userId, auctionId)
+}
+
+func (wr *WatchlistRepository) AddWatchedAuction(
+	ctx context.Context, item *watchlist_entity.WatchlistItem) *internal_error.InternalError {
+	filter := bson.M{"_id": watchlistItemId(item.UserId, item.AuctionId)}
+	update := bson.M{
+		"$setOnInsert": WatchlistEntityMongo{
+			Id:        watchlistItemId(item.UserId, item.AuctionId),
+			UserId:    item.UserId,
+			AuctionId: item.AuctionId,
+			Timestamp: item.Timestamp.Unix(),
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := wr.Collection.UpdateOne(ctx, filter, update, opts); err != nil {
+		logger.Error("Error trying to add auction to watchlist", err)
+		return internal_error.NewInternalServerError("Error trying to add auction to watchlist")
+	}
+
+	return nil
+}
+
+func (wr *WatchlistRepository) RemoveWatchedAuction(
+	ctx context.Context, userId, auctionId string) *internal_error.InternalError {
+	filter := bson.M{"_id": watchlistItemId(userId, auctionId)}
+
+	result, err := wr.Collection.DeleteOne(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to remove auction from watchlist", err)
+		return internal_error.NewInternalServerError("Error trying to remove auction from watchlist")
+	}
+
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Auction %s is not in the watchlist of user %s", auctionId, userId))
+	}
+
+	return nil
+}
+
+func (wr *WatchlistRepository) FindWatchedAuctionsByUserId(
+	ctx context.Context, userId string) ([]watchlist_entity.WatchlistItem, *internal_error.InternalError) {
+	filter := bson.M{"user_id": userId}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	cursor, err := wr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Error trying to find watchlist by userId", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find watchlist by userId")
+	}
+	defer cursor.Close(ctx)
+
+	var itemsMongo []WatchlistEntityMongo
+	if err := cursor.All(ctx, &itemsMongo); err != nil {
+		logger.Error("Error decoding watchlist", err)
+		return nil, internal_error.NewInternalServerError("Error decoding watchlist")
+	}
+
+	var items []watchlist_entity.WatchlistItem
+	for _, itemMongo := range itemsMongo {
+		items = append(items, watchlist_entity.WatchlistItem{
+			UserId:    itemMongo.UserId,
+			AuctionId: itemMongo.AuctionId,
+			Timestamp: time.Unix(itemMongo.Timestamp, 0),
+		})
+	}
+
+	return items, nil
+}
+
+func (wr *WatchlistRepository) FindWatcherIdsByAuctionId(
+	ctx context.Context, auctionId string) ([]string, *internal_error.InternalError) {
+	filter := bson.M{"auction_id": auctionId}
+
+	cursor, err := wr.Collection.Find(ctx, filter)
+	if err != nil {
+		logger.Error("Error trying to find watchers by auctionId", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find watchers by auctionId")
+	}
+	defer cursor.Close(ctx)
+
+	var itemsMongo []WatchlistEntityMongo
+	if err := cursor.All(ctx, &itemsMongo); err != nil {
+		logger.Error("Error decoding watchers", err)
+		return nil, internal_error.NewInternalServerError("Error decoding watchers")
+	}
+
+	var userIds []string
+	for _, itemMongo := range itemsMongo {
+		userIds = append(userIds, itemMongo.UserId)
+	}
+
+	return userIds, nil
+}
+
+func (wr *WatchlistRepository) CountWatchersByAuctionId(
+	ctx context.Context, auctionId string) (int64, *internal_error.InternalError) {
+	count, err := wr.Collection.CountDocuments(ctx, bson.M{"auction_id": auctionId})
+	if err != nil {
+		logger.Error("Error trying to count watchers by auctionId", err)
+		return 0, internal_error.NewInternalServerError("Error trying to count watchers by auctionId")
+	}
+
+	return count, nil
+}
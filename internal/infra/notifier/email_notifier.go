@@ -0,0 +1,145 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+const (
+	SMTP_HOST     = "SMTP_HOST"
+	SMTP_PORT     = "SMTP_PORT"
+	SMTP_USERNAME = "SMTP_USERNAME"
+	SMTP_PASSWORD = "SMTP_PASSWORD"
+	SMTP_FROM     = "SMTP_FROM"
+)
+
+type EmailNotifier struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+	timeout  time.Duration
+}
+
+func NewEmailNotifier(host, port, username, password, from string) *EmailNotifier {
+	return &EmailNotifier{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+		timeout:  10 * time.Second,
+	}
+}
+
+// NewEmailNotifierFromEnv builds the email notifier from the SMTP_* environment
+// variables, returning nil when no SMTP server is configured
+func NewEmailNotifierFromEnv() *EmailNotifier {
+	host := os.Getenv(SMTP_HOST)
+	if host == "" {
+		return nil
+	}
+
+	port := os.Getenv(SMTP_PORT)
+	if port == "" {
+		port = "25"
+	}
+
+	return NewEmailNotifier(
+		host, port, os.Getenv(SMTP_USERNAME), os.Getenv(SMTP_PASSWORD), os.Getenv(SMTP_FROM))
+}
+
+func (en *EmailNotifier) Notify(
+	ctx context.Context,
+	preference notification_entity.Preference,
+	notification notification_entity.Notification) *internal_error.InternalError {
+	if preference.Email == "" {
+		return internal_error.NewBadRequestError("user has no email configured for notifications")
+	}
+
+	if err := en.send(ctx, preference.Email, notification); err != nil {
+		logger.Error("Error trying to send notification email", err)
+		return internal_error.NewInternalServerError("Error trying to send notification email")
+	}
+
+	return nil
+}
+
+func (en *EmailNotifier) send(
+	ctx context.Context, to string, notification notification_entity.Notification) error {
+	dialer := &net.Dialer{Timeout: en.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(en.host, en.port))
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(en.timeout))
+
+	client, err := smtp.NewClient(conn, en.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: en.host}); err != nil {
+			return err
+		}
+	}
+
+	if en.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", en.username, en.password, en.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(en.from); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(en.buildMessage(to, notification)); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (en *EmailNotifier) buildMessage(to string, notification notification_entity.Notification) []byte {
+	var message strings.Builder
+
+	fmt.Fprintf(&message, "From: %s\r\n", en.from)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", notification.Subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", notification.Timestamp.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(notification.Message)
+	message.WriteString("\r\n")
+
+	return []byte(message.String())
+}
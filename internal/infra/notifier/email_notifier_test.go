@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+)
+
+// fakeSMTPMessage holds what the fake SMTP server received
+type fakeSMTPMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTPServer starts a minimal SMTP server on a random local port that
+// accepts a single message and publishes it on the returned channel
+func startFakeSMTPServer(t *testing.T) (string, string, <-chan fakeSMTPMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Erro ao iniciar servidor SMTP fake: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan fakeSMTPMessage, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var message fakeSMTPMessage
+		reply("220 localhost ESMTP fake")
+
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				message.from = line[len("MAIL FROM:"):]
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				message.to = append(message.to, line[len("RCPT TO:"):])
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				message.data = data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 Bye")
+				messages <- message
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port, messages
+}
+
+// TestEmailNotifierSendsMessage tests that the email notifier delivers the notification through SMTP
+func TestEmailNotifierSendsMessage(t *testing.T) {
+	host, port, messages := startFakeSMTPServer(t)
+
+	notifier := NewEmailNotifier(host, port, "", "", "leiloes@example.com")
+
+	preference := notification_entity.Preference{
+		UserId:   "user-id",
+		Email:    "comprador@example.com",
+		Channels: []notification_entity.Channel{notification_entity.EmailChannel},
+	}
+	notification := notification_entity.CreateNotification(
+		"user-id", "auction-id", notification_entity.Outbid,
+		"You have been outbid", "Someone placed a higher bid")
+
+	if err := notifier.Notify(context.Background(), preference, notification); err != nil {
+		t.Fatalf("Erro inesperado ao enviar notificação: %v", err)
+	}
+
+	message := <-messages
+
+	if message.from != "<leiloes@example.com>" {
+		t.Errorf("Remetente esperado: %s, recebido: %s", "<leiloes@example.com>", message.from)
+	}
+	if len(message.to) != 1 || message.to[0] != "<comprador@example.com>" {
+		t.Errorf("Destinatário esperado: %s, recebido: %v", "<comprador@example.com>", message.to)
+	}
+	if !strings.Contains(message.data, "Subject: You have been outbid") {
+		t.Errorf("Mensagem deveria conter o assunto, recebido: %s", message.data)
+	}
+	if !strings.Contains(message.data, "Someone placed a higher bid") {
+		t.Errorf("Mensagem deveria conter o corpo, recebido: %s", message.data)
+	}
+}
+
+// TestEmailNotifierWithoutEmail tests that users without an email address are rejected
+func TestEmailNotifierWithoutEmail(t *testing.T) {
+	notifier := NewEmailNotifier("127.0.0.1", "25", "", "", "leiloes@example.com")
+
+	notification := notification_entity.CreateNotification(
+		"user-id", "auction-id", notification_entity.AuctionWon,
+		"You won", "Congratulations")
+
+	err := notifier.Notify(context.Background(), notification_entity.Preference{UserId: "user-id"}, notification)
+	if err == nil {
+		t.Fatal("Deveria retornar erro para usuário sem email")
+	}
+	if err.Err != "bad_request" {
+		t.Errorf("Tipo de erro esperado: %s, recebido: %s", "bad_request", err.Err)
+	}
+}
@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (ln *LogNotifier) Notify(
+	ctx context.Context,
+	preference notification_entity.Preference,
+	notification notification_entity.Notification) *internal_error.InternalError {
+	logger.Info("Notification sent",
+		zap.String("userId", notification.UserId),
+		zap.String("auctionId", notification.AuctionId),
+		zap.String("kind", string(notification.Kind)),
+		zap.String("subject", notification.Subject),
+		zap.String("message", notification.Message))
+
+	return nil
+}
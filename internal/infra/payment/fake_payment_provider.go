@@ -0,0 +1,58 @@
+package payment
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// FakePaymentProvider keeps payments in memory. Every payment reports the default status until
+// another one is set for it, which lets tests and local runs drive the payment flow
+type FakePaymentProvider struct {
+	mutex         sync.Mutex
+	payments      map[string]order_entity.PaymentStatus
+	defaultStatus order_entity.PaymentStatus
+}
+
+func NewFakePaymentProvider(defaultStatus order_entity.PaymentStatus) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		payments:      make(map[string]order_entity.PaymentStatus),
+		defaultStatus: defaultStatus,
+	}
+}
+
+func (fp *FakePaymentProvider) CreatePayment(
+	ctx context.Context, order order_entity.Order) (string, *internal_error.InternalError) {
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+
+	paymentReference := "fake_" + uuid.New().String()
+	fp.payments[paymentReference] = fp.defaultStatus
+
+	return paymentReference, nil
+}
+
+func (fp *FakePaymentProvider) ConfirmPayment(
+	ctx context.Context, paymentReference string) (order_entity.PaymentStatus, *internal_error.InternalError) {
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+
+	status, ok := fp.payments[paymentReference]
+	if !ok {
+		return "", internal_error.NewNotFoundError("Payment not found")
+	}
+
+	return status, nil
+}
+
+// SetPaymentStatus changes what the payment reports from now on
+func (fp *FakePaymentProvider) SetPaymentStatus(
+	paymentReference string, status order_entity.PaymentStatus) {
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+
+	fp.payments[paymentReference] = status
+}
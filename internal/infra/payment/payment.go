@@ -0,0 +1,27 @@
+package payment
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+)
+
+const PAYMENT_PROVIDER = "PAYMENT_PROVIDER"
+
+const fakePaymentProvider = "fake"
+
+// NewPaymentProviderFromEnv builds the provider chosen in PAYMENT_PROVIDER. No real provider is
+// integrated yet, so the only option is "fake", which confirms every payment and is meant for
+// tests and local runs. It must be chosen explicitly, so a deployment without a provider
+// refuses to start instead of accepting unpaid orders
+func NewPaymentProviderFromEnv() (order_entity.PaymentProviderInterface, error) {
+	switch os.Getenv(PAYMENT_PROVIDER) {
+	case fakePaymentProvider:
+		return NewFakePaymentProvider(order_entity.PaymentConfirmed), nil
+	case "":
+		return nil, fmt.Errorf("%s must be set, the only provider available is %q", PAYMENT_PROVIDER, fakePaymentProvider)
+	default:
+		return nil, fmt.Errorf("payment provider %q is not supported", os.Getenv(PAYMENT_PROVIDER))
+	}
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/rate_limit_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// MemoryRateLimiter keeps the token buckets in process, so each replica enforces its own limits
+type MemoryRateLimiter struct {
+	mutex       sync.Mutex
+	buckets     map[string]*tokenBucket
+	calls       int
+	now         func() time.Time
+	sweepEveryN int
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		buckets:     make(map[string]*tokenBucket),
+		now:         time.Now,
+		sweepEveryN: 1024,
+	}
+}
+
+func (ml *MemoryRateLimiter) Allow(
+	ctx context.Context,
+	key string,
+	limit rate_limit_entity.Limit) (bool, time.Duration, *internal_error.InternalError) {
+	limited, retryAfter, err := ml.AllowAll(ctx, []rate_limit_entity.Bucket{{Key: key, Limit: limit}})
+	return limited < 0, retryAfter, err
+}
+
+func (ml *MemoryRateLimiter) AllowAll(
+	ctx context.Context,
+	buckets []rate_limit_entity.Bucket) (int, time.Duration, *internal_error.InternalError) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+
+	now := ml.now()
+	ml.sweep(now)
+
+	limited := -1
+	var retryAfter time.Duration
+	tokenBuckets := make([]*tokenBucket, len(buckets))
+	for i, limitedBucket := range buckets {
+		limit := limitedBucket.Limit
+		if limit.Unlimited() {
+			continue
+		}
+
+		bucket, ok := ml.buckets[limitedBucket.Key]
+		if !ok {
+			bucket = &tokenBucket{tokens: float64(limit.Burst), updatedAt: now}
+			ml.buckets[limitedBucket.Key] = bucket
+		}
+
+		elapsed := now.Sub(bucket.updatedAt).Seconds()
+		bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.Rate)
+		bucket.updatedAt = now
+		tokenBuckets[i] = bucket
+
+		if bucket.tokens < 1 {
+			if limited < 0 {
+				limited = i
+			}
+			retryAfter = max(retryAfter, time.Duration((1-bucket.tokens)/limit.Rate*float64(time.Second)))
+		}
+	}
+
+	if limited >= 0 {
+		return limited, retryAfter, nil
+	}
+
+	for i, bucket := range tokenBuckets {
+		if bucket == nil {
+			continue
+		}
+
+		limit := buckets[i].Limit
+		bucket.tokens--
+		bucket.fullAt = now.Add(time.Duration((float64(limit.Burst) - bucket.tokens) / limit.Rate * float64(time.Second)))
+	}
+
+	return -1, 0, nil
+}
+
+// sweep drops, every so often, the buckets that refilled completely, since a new bucket starts full
+func (ml *MemoryRateLimiter) sweep(now time.Time) {
+	ml.calls++
+	if ml.calls < ml.sweepEveryN {
+		return
+	}
+	ml.calls = 0
+
+	for key, bucket := range ml.buckets {
+		if !now.Before(bucket.fullAt) {
+			delete(ml.buckets, key)
+		}
+	}
+}
+
+// Len returns the number of buckets currently held
+func (ml *MemoryRateLimiter) Len() int {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+
+	return len(ml.buckets)
+}
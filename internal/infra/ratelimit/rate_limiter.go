@@ -0,0 +1,16 @@
+package ratelimit
+
+import (
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/rate_limit_entity"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRateLimiter returns the Redis backed limiter when a client is given, so every replica
+// shares the buckets, and the in-memory limiter otherwise
+func NewRateLimiter(redisClient *redis.Client) rate_limit_entity.RateLimiterInterface {
+	if redisClient != nil {
+		return NewRedisRateLimiter(redisClient)
+	}
+
+	return NewMemoryRateLimiter()
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/rate_limit_entity"
+	"github.com/redis/go-redis/v9"
+)
+
+// assertTokenBucket tests the burst, the rejection with its retry delay and the refill of a limiter
+func assertTokenBucket(t *testing.T, limiter rate_limit_entity.RateLimiterInterface, advance func(time.Duration)) {
+	ctx := context.Background()
+	limit := rate_limit_entity.Limit{Rate: 2, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if allowed, _, err := limiter.Allow(ctx, "user", limit); err != nil || !allowed {
+			t.Fatalf("Lance %d deveria ser permitido dentro do burst (erro: %v)", i+1, err)
+		}
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "user", limit)
+	if err != nil || allowed {
+		t.Fatalf("Lance acima do burst deveria ser rejeitado (erro: %v)", err)
+	}
+	if retryAfter <= 0 || retryAfter > 500*time.Millisecond {
+		t.Errorf("Espera esperada de até 500ms, recebida: %v", retryAfter)
+	}
+
+	// Other keys have their own bucket
+	if allowed, _, _ := limiter.Allow(ctx, "other-user", limit); !allowed {
+		t.Error("Outra chave não deveria ser afetada pelo limite")
+	}
+
+	advance(500 * time.Millisecond)
+	if allowed, _, _ := limiter.Allow(ctx, "user", limit); !allowed {
+		t.Error("Um token deveria ter sido reposto após 500ms")
+	}
+	if allowed, _, _ := limiter.Allow(ctx, "user", limit); allowed {
+		t.Error("Apenas um token deveria ter sido reposto")
+	}
+}
+
+// assertAllowAll tests that a request rejected by one bucket doesn't consume the others
+func assertAllowAll(t *testing.T, limiter rate_limit_entity.RateLimiterInterface, advance func(time.Duration)) {
+	ctx := context.Background()
+	user := rate_limit_entity.Bucket{Key: "user", Limit: rate_limit_entity.Limit{Rate: 1, Burst: 1}}
+	auction := rate_limit_entity.Bucket{Key: "auction", Limit: rate_limit_entity.Limit{Rate: 2, Burst: 1}}
+
+	if allowed, _, _ := limiter.Allow(ctx, auction.Key, auction.Limit); !allowed {
+		t.Fatal("O primeiro lance no leilão deveria ser permitido")
+	}
+
+	limited, retryAfter, err := limiter.AllowAll(ctx,
+		[]rate_limit_entity.Bucket{user, auction, {Key: "unlimited"}})
+	if err != nil || limited != 1 {
+		t.Fatalf("Bucket do leilão deveria rejeitar o lance, recebido: %d (erro: %v)", limited, err)
+	}
+	if retryAfter <= 0 || retryAfter > 500*time.Millisecond {
+		t.Errorf("Espera esperada de até 500ms, recebida: %v", retryAfter)
+	}
+
+	// The user token wasn't taken by the rejected request
+	if allowed, _, _ := limiter.Allow(ctx, user.Key, user.Limit); !allowed {
+		t.Error("O token do usuário não deveria ter sido consumido")
+	}
+
+	// With both buckets empty the wait covers the slowest one
+	_, retryAfter, _ = limiter.AllowAll(ctx, []rate_limit_entity.Bucket{user, auction})
+	if retryAfter <= 500*time.Millisecond || retryAfter > time.Second {
+		t.Errorf("Espera esperada entre 500ms e 1s, recebida: %v", retryAfter)
+	}
+
+	advance(time.Second)
+	if limited, _, _ := limiter.AllowAll(ctx, []rate_limit_entity.Bucket{user, auction}); limited != -1 {
+		t.Errorf("Lance deveria ser permitido após a reposição, rejeitado pelo bucket %d", limited)
+	}
+}
+
+// TestMemoryRateLimiter tests the in-memory token bucket
+func TestMemoryRateLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	assertTokenBucket(t, limiter, func(duration time.Duration) { now = now.Add(duration) })
+}
+
+// TestMemoryRateLimiterAllowAll tests the in-memory limiter across several buckets
+func TestMemoryRateLimiterAllowAll(t *testing.T) {
+	now := time.Now()
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	assertAllowAll(t, limiter, func(duration time.Duration) { now = now.Add(duration) })
+}
+
+// TestMemoryRateLimiterSweep tests that buckets that refilled completely are dropped
+func TestMemoryRateLimiterSweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+	limiter.sweepEveryN = 2
+
+	limit := rate_limit_entity.Limit{Rate: 1, Burst: 1}
+	limiter.Allow(ctx, "user", limit)
+
+	now = now.Add(2 * time.Second)
+	limiter.Allow(ctx, "other-user", limit)
+
+	if limiter.Len() != 1 {
+		t.Errorf("Apenas o bucket em uso deveria permanecer, tamanho: %d", limiter.Len())
+	}
+}
+
+// TestRedisRateLimiter tests the token bucket shared through Redis
+func TestRedisRateLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	now := time.Now()
+	limiter := NewRedisRateLimiter(client)
+	limiter.now = func() time.Time { return now }
+
+	assertTokenBucket(t, limiter, func(duration time.Duration) {
+		now = now.Add(duration)
+		server.FastForward(duration)
+	})
+}
+
+// TestRedisRateLimiterAllowAll tests the Redis limiter across several buckets
+func TestRedisRateLimiterAllowAll(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	now := time.Now()
+	limiter := NewRedisRateLimiter(client)
+	limiter.now = func() time.Time { return now }
+
+	assertAllowAll(t, limiter, func(duration time.Duration) {
+		now = now.Add(duration)
+		server.FastForward(duration)
+	})
+}
+
+// TestUnlimitedRateLimit tests that a limit without rate doesn't reject anything
+func TestUnlimitedRateLimit(t *testing.T) {
+	limiter := NewMemoryRateLimiter()
+
+	for i := 0; i < 100; i++ {
+		if allowed, _, _ := limiter.Allow(context.Background(), "user", rate_limit_entity.Limit{}); !allowed {
+			t.Fatal("Limite vazio não deveria rejeitar lances")
+		}
+	}
+}
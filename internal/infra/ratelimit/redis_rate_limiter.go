@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/rate_limit_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills the buckets and takes a token from each of them atomically, only
+// when all of them have one, so every replica shares the same buckets. ARGV holds the current
+// time followed by the rate and burst of each key. A bucket expires once it would be full again,
+// since a missing bucket starts full. It returns the position of the first empty bucket, 0 when
+// the tokens were taken, and how long until every bucket has a token
+var tokenBucketScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+
+local rates = {}
+local bursts = {}
+local tokens = {}
+local limited = 0
+local retry_after = 0
+for i, key in ipairs(KEYS) do
+	local rate = tonumber(ARGV[i * 2])
+	local burst = tonumber(ARGV[i * 2 + 1])
+
+	local bucket = redis.call("HMGET", key, "tokens", "updated_at")
+	local current = tonumber(bucket[1])
+	local updated_at = tonumber(bucket[2])
+	if current == nil or updated_at == nil then
+		current = burst
+		updated_at = now
+	end
+
+	current = math.min(burst, current + math.max(0, now - updated_at) / 1000 * rate)
+	if current < 1 then
+		if limited == 0 then
+			limited = i
+		end
+		retry_after = math.max(retry_after, math.ceil((1 - current) / rate * 1000))
+	end
+
+	rates[i] = rate
+	bursts[i] = burst
+	tokens[i] = current
+end
+
+for i, key in ipairs(KEYS) do
+	if limited == 0 then
+		tokens[i] = tokens[i] - 1
+	end
+
+	redis.call("HSET", key, "tokens", tostring(tokens[i]), "updated_at", tostring(now))
+	redis.call("PEXPIRE", key, math.ceil((bursts[i] - tokens[i]) / rates[i] * 1000) + 1000)
+end
+
+return {limited, retry_after}
+`)
+
+// RedisRateLimiter keeps the token buckets in Redis, so the limits hold across replicas
+type RedisRateLimiter struct {
+	client    *redis.Client
+	keyPrefix string
+	now       func() time.Time
+}
+
+func NewRedisRateLimiter(client *redis.Client) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client:    client,
+		keyPrefix: "ratelimit:",
+		now:       time.Now,
+	}
+}
+
+func (rl *RedisRateLimiter) Allow(
+	ctx context.Context,
+	key string,
+	limit rate_limit_entity.Limit) (bool, time.Duration, *internal_error.InternalError) {
+	limited, retryAfter, err := rl.AllowAll(ctx, []rate_limit_entity.Bucket{{Key: key, Limit: limit}})
+	return limited < 0, retryAfter, err
+}
+
+func (rl *RedisRateLimiter) AllowAll(
+	ctx context.Context,
+	buckets []rate_limit_entity.Bucket) (int, time.Duration, *internal_error.InternalError) {
+	// Unlimited buckets are left out of the script, which reports positions among the others
+	var keys []string
+	var positions []int
+	args := []any{rl.now().UnixMilli()}
+	for i, bucket := range buckets {
+		if bucket.Limit.Unlimited() {
+			continue
+		}
+
+		keys = append(keys, rl.keyPrefix+bucket.Key)
+		positions = append(positions, i)
+		args = append(args, bucket.Limit.Rate, bucket.Limit.Burst)
+	}
+
+	if len(keys) == 0 {
+		return -1, 0, nil
+	}
+
+	result, err := tokenBucketScript.Run(ctx, rl.client, keys, args...).Int64Slice()
+	if err != nil {
+		logger.Error("Error trying to apply rate limit in redis", err)
+		return -1, 0, internal_error.NewInternalServerError("Error trying to apply rate limit")
+	}
+
+	if result[0] == 0 {
+		return -1, 0, nil
+	}
+
+	return positions[result[0]-1], time.Duration(result[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+// LocalBlobStorage keeps the files in a directory served by the application under baseURL
+type LocalBlobStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalBlobStorage(dir, baseURL string) *LocalBlobStorage {
+	return &LocalBlobStorage{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Dir returns the directory the files are kept in
+func (ls *LocalBlobStorage) Dir() string {
+	return ls.dir
+}
+
+// BaseURL returns the address the files are served from. When it is a path, the application
+// serves the directory itself
+func (ls *LocalBlobStorage) BaseURL() string {
+	return ls.baseURL
+}
+
+// path resolves the key inside the storage directory, refusing keys that would leave it
+func (ls *LocalBlobStorage) path(key string) (string, *internal_error.InternalError) {
+	path := filepath.Join(ls.dir, filepath.FromSlash(key))
+
+	relative, err := filepath.Rel(ls.dir, path)
+	if err != nil || relative == "." || strings.HasPrefix(relative, "..") {
+		return "", internal_error.NewBadRequestError("Invalid file key")
+	}
+
+	return path, nil
+}
+
+// Put writes the file to a temporary file first, so a failed upload never leaves a partial
+// file behind the key
+func (ls *LocalBlobStorage) Put(
+	ctx context.Context,
+	key, contentType string,
+	body io.Reader,
+	size int64) *internal_error.InternalError {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		logger.Error("Error trying to create storage directory", err, zap.String("key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	file, createErr := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if createErr != nil {
+		logger.Error("Error trying to create file", createErr, zap.String("key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+	defer os.Remove(file.Name())
+
+	written, copyErr := io.Copy(file, body)
+	closeErr := file.Close()
+	if copyErr != nil || closeErr != nil || written != size {
+		logger.Error("Error trying to write file", errors.Join(copyErr, closeErr), zap.String("key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		logger.Error("Error trying to move file into place", err, zap.String("key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+
+	return nil
+}
+
+func (ls *LocalBlobStorage) Delete(
+	ctx context.Context, key string) *internal_error.InternalError {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Error("Error trying to delete file", err, zap.String("key", key))
+		return internal_error.NewInternalServerError("Error trying to delete file")
+	}
+
+	return nil
+}
+
+func (ls *LocalBlobStorage) URL(key string) string {
+	return ls.baseURL + "/" + key
+}
@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+// unsignedPayload lets uploads be streamed without hashing the body before sending it
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3BlobStorage keeps the files in a bucket of an S3 compatible service, such as AWS S3 or
+// MinIO. Buckets are addressed by path, e.g. http://localhost:9000/auction/key, which every
+// S3 compatible service supports
+type S3BlobStorage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3BlobStorage builds the storage of the bucket. Files are served from publicURL, the
+// bucket address in the endpoint when empty
+func NewS3BlobStorage(
+	endpoint, region, bucket, accessKey, secretKey, publicURL string) (*S3BlobStorage, error) {
+	endpointURL, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || endpointURL.Scheme == "" || endpointURL.Host == "" {
+		return nil, fmt.Errorf("S3 endpoint %q is not a valid URL", endpoint)
+	}
+
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is not set")
+	}
+
+	if publicURL == "" {
+		publicURL = endpointURL.String() + "/" + bucket
+	}
+
+	return &S3BlobStorage{
+		endpoint:  endpointURL,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		client:    &http.Client{Timeout: time.Minute},
+		now:       time.Now,
+	}, nil
+}
+
+func (s3 *S3BlobStorage) objectURL(key string) string {
+	return s3.endpoint.String() + "/" + s3.bucket + "/" + key
+}
+
+func (s3 *S3BlobStorage) Put(
+	ctx context.Context,
+	key, contentType string,
+	body io.Reader,
+	size int64) *internal_error.InternalError {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, s3.objectURL(key), body)
+	if err != nil {
+		logger.Error("Error trying to build upload request", err, zap.String("key", key))
+		return internal_error.NewInternalServerError("Error trying to store file")
+	}
+	request.ContentLength = size
+	request.Header.Set("Content-Type", contentType)
+
+	return s3.do(request, key, "Error trying to store file")
+}
+
+func (s3 *S3BlobStorage) Delete(
+	ctx context.Context, key string) *internal_error.InternalError {
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, s3.objectURL(key), nil)
+	if err != nil {
+		logger.Error("Error trying to build delete request", err, zap.String("key", key))
+		return internal_error.NewInternalServerError("Error trying to delete file")
+	}
+
+	return s3.do(request, key, "Error trying to delete file")
+}
+
+func (s3 *S3BlobStorage) URL(key string) string {
+	return s3.publicURL + "/" + key
+}
+
+// do signs and sends the request. Deleting a file that does not exist succeeds, as in S3
+func (s3 *S3BlobStorage) do(request *http.Request, key, message string) *internal_error.InternalError {
+	request.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	signRequest(request, unsignedPayload, s3.accessKey, s3.secretKey, s3.region, s3.now())
+
+	response, err := s3.client.Do(request)
+	if err != nil {
+		logger.Error(message, err, zap.String("key", key))
+		return internal_error.NewInternalServerError(message)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		logger.Error(message, fmt.Errorf("S3 responded %s: %s", response.Status, responseBody),
+			zap.String("key", key))
+		return internal_error.NewInternalServerError(message)
+	}
+
+	return nil
+}
+
+// signRequest adds the AWS Signature Version 4 authorization to the request, signing the host
+// and every header already set on it
+func signRequest(
+	request *http.Request,
+	payloadHash, accessKey, secretKey, region string,
+	now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	request.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		encodePath(request.URL.Path),
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// encodePath escapes every byte of the path but the unreserved characters and the slashes
+func encodePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func uriEncode(value string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+			continue
+		}
+
+		fmt.Fprintf(&encoded, "%%%02X", b)
+	}
+
+	return encoded.String()
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/image_entity"
+)
+
+const (
+	BLOB_STORAGE      = "BLOB_STORAGE"
+	LOCAL_STORAGE_DIR = "LOCAL_STORAGE_DIR"
+	LOCAL_STORAGE_URL = "LOCAL_STORAGE_URL"
+	S3_ENDPOINT       = "S3_ENDPOINT"
+	S3_REGION         = "S3_REGION"
+	S3_BUCKET         = "S3_BUCKET"
+	S3_ACCESS_KEY     = "S3_ACCESS_KEY"
+	S3_SECRET_KEY     = "S3_SECRET_KEY"
+	S3_PUBLIC_URL     = "S3_PUBLIC_URL"
+)
+
+const (
+	localBlobStorage    = "local"
+	s3CompatibleStorage = "s3"
+	defaultLocalDir     = "uploads"
+	defaultLocalURL     = "/files"
+	defaultS3Region     = "us-east-1"
+)
+
+// NewBlobStorageFromEnv builds the storage chosen in BLOB_STORAGE: "local" (the default) keeps
+// the files in LOCAL_STORAGE_DIR, and "s3" in the S3_BUCKET of an S3 compatible service
+func NewBlobStorageFromEnv() (image_entity.BlobStorageInterface, error) {
+	switch os.Getenv(BLOB_STORAGE) {
+	case "", localBlobStorage:
+		return NewLocalBlobStorage(getEnv(LOCAL_STORAGE_DIR, defaultLocalDir), getEnv(LOCAL_STORAGE_URL, defaultLocalURL)), nil
+	case s3CompatibleStorage:
+		return NewS3BlobStorage(
+			os.Getenv(S3_ENDPOINT),
+			getEnv(S3_REGION, defaultS3Region),
+			os.Getenv(S3_BUCKET),
+			os.Getenv(S3_ACCESS_KEY),
+			os.Getenv(S3_SECRET_KEY),
+			os.Getenv(S3_PUBLIC_URL))
+	default:
+		return nil, fmt.Errorf("blob storage %q is not supported", os.Getenv(BLOB_STORAGE))
+	}
+}
+
+func getEnv(name, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
@@ -1,8 +1,12 @@
 package internal_error
 
+import "time"
+
 type InternalError struct {
 	Message string
 	Err     string
+	// RetryAfter is how long the client should wait before retrying, when known
+	RetryAfter time.Duration
 }
 
 func (ie *InternalError) Error() string {
@@ -23,6 +27,21 @@ func NewInternalServerError(message string) *InternalError {
 	}
 }
 
+func NewServiceUnavailableError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "service_unavailable",
+	}
+}
+
+func NewTooManyRequestsError(message string, retryAfter time.Duration) *InternalError {
+	return &InternalError{
+		Message:    message,
+		Err:        "too_many_requests",
+		RetryAfter: retryAfter,
+	}
+}
+
 func NewBadRequestError(message string) *InternalError {
 	return &InternalError{
 		Message: message,
@@ -0,0 +1,195 @@
+package auction_usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/image_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+// sniffLength is the number of bytes the content type of an image is detected from
+const sniffLength = 512
+
+// ImageInputDTO identifies the seller changing the images of the auction. There are no user
+// accounts yet, so the image routes are admin routes and the seller id only guards an admin
+// against changing the images of the wrong auction
+type ImageInputDTO struct {
+	SellerId string `form:"seller_id" binding:"required,uuid"`
+}
+
+// ImageUpload is an image file received for an auction. Its content type is detected from the
+// file itself, not taken from the request
+type ImageUpload struct {
+	Body io.Reader
+	Size int64
+}
+
+type ImageOutputDTO struct {
+	Id          string `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// GetMaxImageSize returns the largest image accepted, in bytes, from MAX_IMAGE_SIZE. Defaults
+// to 5 MiB
+func GetMaxImageSize() int64 {
+	maxImageSize, err := strconv.ParseInt(os.Getenv("MAX_IMAGE_SIZE"), 10, 64)
+	if err != nil || maxImageSize <= 0 {
+		return 5 << 20
+	}
+
+	return maxImageSize
+}
+
+// AddAuctionImages validates every upload before storing any of them, and attaches the stored
+// files in a single update, so a request either attaches all its images or none. Files stored
+// for a request that failed are deleted
+func (au *AuctionUseCase) AddAuctionImages(
+	ctx context.Context,
+	auctionId string,
+	imageInput ImageInputDTO,
+	uploads []ImageUpload) ([]ImageOutputDTO, *internal_error.InternalError) {
+	auction, err := au.findSellerAuction(ctx, auctionId, imageInput.SellerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(uploads) == 0 {
+		return nil, internal_error.NewBadRequestError("No image was sent")
+	}
+
+	if len(auction.Images)+len(uploads) > image_entity.MaxImagesPerAuction {
+		return nil, internal_error.NewBadRequestError(
+			fmt.Sprintf("Auction can have up to %d images", image_entity.MaxImagesPerAuction))
+	}
+
+	images := make([]*image_entity.Image, 0, len(uploads))
+	bodies := make([]io.Reader, 0, len(uploads))
+	for _, upload := range uploads {
+		head := make([]byte, sniffLength)
+		read, readErr := io.ReadFull(upload.Body, head)
+		if readErr != nil && readErr != io.ErrUnexpectedEOF && readErr != io.EOF {
+			logger.Error("Error trying to read uploaded image", readErr)
+			return nil, internal_error.NewBadRequestError("Error trying to read image")
+		}
+		head = head[:read]
+
+		image, err := image_entity.CreateImage(
+			auction.Id, http.DetectContentType(head), upload.Size, GetMaxImageSize())
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, image)
+		bodies = append(bodies, io.MultiReader(bytes.NewReader(head), upload.Body))
+	}
+
+	storedImages := make([]image_entity.Image, 0, len(images))
+	deleteStoredFiles := func() {
+		for _, image := range storedImages {
+			au.deleteImageFile(ctx, image.Key)
+		}
+	}
+
+	for i, image := range images {
+		if err := au.blobStorage.Put(ctx, image.Key, image.ContentType, bodies[i], image.Size); err != nil {
+			deleteStoredFiles()
+			return nil, err
+		}
+
+		storedImages = append(storedImages, *image)
+	}
+
+	if err := au.auctionRepositoryInterface.AddAuctionImages(ctx, auction.Id, storedImages); err != nil {
+		deleteStoredFiles()
+		return nil, err
+	}
+
+	imageOutputs := make([]ImageOutputDTO, 0, len(storedImages))
+	for _, image := range storedImages {
+		imageOutputs = append(imageOutputs, au.toImageOutput(image))
+	}
+
+	return imageOutputs, nil
+}
+
+func (au *AuctionUseCase) RemoveAuctionImage(
+	ctx context.Context,
+	auctionId, imageId string,
+	imageInput ImageInputDTO) *internal_error.InternalError {
+	auction, err := au.findSellerAuction(ctx, auctionId, imageInput.SellerId)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range auction.Images {
+		if image.Id != imageId {
+			continue
+		}
+
+		if err := au.auctionRepositoryInterface.RemoveAuctionImage(ctx, auction.Id, image.Id); err != nil {
+			return err
+		}
+
+		au.deleteImageFile(ctx, image.Key)
+		return nil
+	}
+
+	return internal_error.NewNotFoundError(
+		fmt.Sprintf("Image %s not found in auction %s", imageId, auctionId))
+}
+
+// findSellerAuction returns the auction when it is still active and sellerId is its seller
+func (au *AuctionUseCase) findSellerAuction(
+	ctx context.Context, auctionId, sellerId string) (*auction_entity.Auction, *internal_error.InternalError) {
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction.SellerId == "" || auction.SellerId != sellerId {
+		return nil, internal_error.NewBadRequestError("Only the seller of the auction can change its images")
+	}
+
+	if auction.Status != auction_entity.Active {
+		return nil, internal_error.NewBadRequestError("Images can only be changed while the auction is active")
+	}
+
+	return auction, nil
+}
+
+// deleteImageFile removes a file no longer attached to any auction. Failures only leave an
+// unused file behind, so they are logged instead of failing the request
+func (au *AuctionUseCase) deleteImageFile(ctx context.Context, key string) {
+	if err := au.blobStorage.Delete(ctx, key); err != nil {
+		logger.Error("Error trying to delete image file", err, zap.String("key", key))
+	}
+}
+
+func (au *AuctionUseCase) toImageOutput(image image_entity.Image) ImageOutputDTO {
+	return ImageOutputDTO{
+		Id:          image.Id,
+		URL:         au.blobStorage.URL(image.Key),
+		ContentType: image.ContentType,
+		Size:        image.Size,
+	}
+}
+
+func (au *AuctionUseCase) toImageOutputs(images []image_entity.Image) []ImageOutputDTO {
+	var imageOutputs []ImageOutputDTO
+	for _, image := range images {
+		imageOutputs = append(imageOutputs, au.toImageOutput(image))
+	}
+
+	return imageOutputs
+}
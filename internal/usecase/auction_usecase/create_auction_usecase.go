@@ -2,42 +2,96 @@ package auction_usecase
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/category_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/exchange_rate_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/image_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/watchlist_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/bid_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/settlement_usecase"
 )
 
 type AuctionInputDTO struct {
 	ProductName string           `json:"product_name" binding:"required,min=1"`
-	Category    string           `json:"category" binding:"required,min=2"`
+	Category    string           `json:"category" binding:"required"`
 	Description string           `json:"description" binding:"required,min=10,max=200"`
 	Condition   ProductCondition `json:"condition" binding:"oneof=0 1 2"`
+	Currency    string           `json:"currency"`
+	SellerId    string           `json:"seller_id" binding:"omitempty,uuid"`
+	// Attributes must include the required attributes of the category
+	Attributes map[string]string `json:"attributes"`
+	// ReservePrice is kept private: responses only tell whether the high bid reached it
+	ReservePrice json.Number `json:"reserve_price"`
 }
 
 type AuctionOutputDTO struct {
-	Id          string           `json:"id"`
-	ProductName string           `json:"product_name"`
-	Category    string           `json:"category"`
-	Description string           `json:"description"`
-	Condition   ProductCondition `json:"condition"`
-	Status      AuctionStatus    `json:"status"`
-	Timestamp   time.Time        `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	Id           string            `json:"id"`
+	ProductName  string            `json:"product_name"`
+	Category     string            `json:"category"`
+	Description  string            `json:"description"`
+	Condition    ProductCondition  `json:"condition"`
+	Currency     string            `json:"currency"`
+	SellerId     string            `json:"seller_id,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Images       []ImageOutputDTO  `json:"images,omitempty"`
+	Status       AuctionStatus     `json:"status"`
+	Timestamp    time.Time         `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	ReserveMet   *bool             `json:"reserve_met,omitempty"`
+	WatcherCount *int64            `json:"watcher_count,omitempty"`
+
+	// Countdown and bid summary. TimeRemaining is in seconds as of ServerTime, zero once the
+	// auction ended, and HighBid is omitted while there are no bids
+	EndTime       time.Time   `json:"end_time"`
+	ServerTime    time.Time   `json:"server_time"`
+	TimeRemaining int64       `json:"time_remaining_seconds"`
+	HighBid       json.Number `json:"high_bid,omitempty"`
+	BidCount      int64       `json:"bid_count"`
+	BidderCount   int64       `json:"bidder_count"`
+}
+
+// AuctionPageInputDTO selects a page of an auction listing, the newest first by default
+type AuctionPageInputDTO struct {
+	Limit  int64  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=ending_soonest newest highest_price most_bids"`
+}
+
+// AuctionPageOutputDTO carries the cursor of the next page, omitted on the last one
+type AuctionPageOutputDTO struct {
+	Auctions   []AuctionOutputDTO `json:"auctions"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
+// WinningInfoOutputDTO carries the fees of the winning bid once the auction was settled
 type WinningInfoOutputDTO struct {
-	Auction AuctionOutputDTO          `json:"auction"`
-	Bid     *bid_usecase.BidOutputDTO `json:"bid,omitempty"`
+	Auction AuctionOutputDTO                          `json:"auction"`
+	Bid     *bid_usecase.BidOutputDTO                 `json:"bid,omitempty"`
+	Fees    *settlement_usecase.FeeBreakdownOutputDTO `json:"fees,omitempty"`
 }
 
 func NewAuctionUseCase(
 	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface,
-	bidRepositoryInterface bid_entity.BidEntityRepository) AuctionUseCaseInterface {
+	bidRepositoryInterface bid_entity.BidEntityRepository,
+	watchlistRepositoryInterface watchlist_entity.WatchlistRepositoryInterface,
+	exchangeRateRepositoryInterface exchange_rate_entity.ExchangeRateRepositoryInterface,
+	orderRepositoryInterface order_entity.OrderRepositoryInterface,
+	categoryRepositoryInterface category_entity.CategoryRepositoryInterface,
+	blobStorage image_entity.BlobStorageInterface) AuctionUseCaseInterface {
 	return &AuctionUseCase{
-		auctionRepositoryInterface: auctionRepositoryInterface,
-		bidRepositoryInterface:     bidRepositoryInterface,
+		auctionRepositoryInterface:      auctionRepositoryInterface,
+		bidRepositoryInterface:          bidRepositoryInterface,
+		watchlistRepositoryInterface:    watchlistRepositoryInterface,
+		exchangeRateRepositoryInterface: exchangeRateRepositoryInterface,
+		orderRepositoryInterface:        orderRepositoryInterface,
+		categoryRepositoryInterface:     categoryRepositoryInterface,
+		blobStorage:                     blobStorage,
 	}
 }
 
@@ -52,37 +106,110 @@ type AuctionUseCaseInterface interface {
 	FindAuctions(
 		ctx context.Context,
 		status AuctionStatus,
-		category, productName string) ([]AuctionOutputDTO, *internal_error.InternalError)
+		category, productName, currency string,
+		pageInput AuctionPageInputDTO) (*AuctionPageOutputDTO, *internal_error.InternalError)
+
+	SearchAuctions(
+		ctx context.Context,
+		searchInput AuctionSearchInputDTO) (*AuctionSearchOutputDTO, *internal_error.InternalError)
 
 	FindWinningBidByAuctionId(
 		ctx context.Context,
-		auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError)
+		auctionId, displayCurrency string) (*WinningInfoOutputDTO, *internal_error.InternalError)
+
+	AddAuctionImages(
+		ctx context.Context,
+		auctionId string,
+		imageInput ImageInputDTO,
+		uploads []ImageUpload) ([]ImageOutputDTO, *internal_error.InternalError)
+
+	RemoveAuctionImage(
+		ctx context.Context,
+		auctionId, imageId string,
+		imageInput ImageInputDTO) *internal_error.InternalError
+}
+
+// reserveMet is only reported for auctions with a reserve price
+func reserveMet(auction *auction_entity.Auction) *bool {
+	if auction.ReservePrice.IsZero() {
+		return nil
+	}
+
+	met := auction.ReserveMet()
+	return &met
 }
 
 type ProductCondition int64
 type AuctionStatus int64
 
 type AuctionUseCase struct {
-	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface
-	bidRepositoryInterface     bid_entity.BidEntityRepository
+	auctionRepositoryInterface      auction_entity.AuctionRepositoryInterface
+	bidRepositoryInterface          bid_entity.BidEntityRepository
+	watchlistRepositoryInterface    watchlist_entity.WatchlistRepositoryInterface
+	exchangeRateRepositoryInterface exchange_rate_entity.ExchangeRateRepositoryInterface
+	orderRepositoryInterface        order_entity.OrderRepositoryInterface
+	categoryRepositoryInterface     category_entity.CategoryRepositoryInterface
+	blobStorage                     image_entity.BlobStorageInterface
 }
 
 func (au *AuctionUseCase) CreateAuction(
 	ctx context.Context,
 	auctionInput AuctionInputDTO) *internal_error.InternalError {
+	category, err := au.findCategory(ctx, auctionInput.Category)
+	if err != nil {
+		return err
+	}
+
 	auction, err := auction_entity.CreateAuction(
 		auctionInput.ProductName,
-		auctionInput.Category,
+		category,
 		auctionInput.Description,
-		auction_entity.ProductCondition(auctionInput.Condition))
+		auction_entity.ProductCondition(auctionInput.Condition),
+		strings.ToUpper(strings.TrimSpace(auctionInput.Currency)),
+		auctionInput.SellerId,
+		auctionInput.Attributes)
 	if err != nil {
 		return err
 	}
 
+	if auctionInput.ReservePrice != "" {
+		if err := auction.SetReservePrice(auctionInput.ReservePrice.String()); err != nil {
+			return err
+		}
+	}
+
 	if err := au.auctionRepositoryInterface.CreateAuction(
 		ctx, auction); err != nil {
 		return err
 	}
 
+	// The category may have been deleted while the auction was created, before the deletion
+	// could see the new auction
+	category, err = au.findCategory(ctx, auction.Category)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		if err := au.auctionRepositoryInterface.DeleteAuction(ctx, auction.Id); err != nil {
+			return err
+		}
+		return internal_error.NewBadRequestError("category does not exist")
+	}
+
 	return nil
 }
+
+// findCategory returns the category with the attributes inherited from its ancestors, nil when
+// it is not in the category tree
+func (au *AuctionUseCase) findCategory(
+	ctx context.Context, id string) (*category_entity.Category, *internal_error.InternalError) {
+	path, err := au.categoryRepositoryInterface.FindCategoryPath(ctx, id)
+	if err != nil {
+		if err.Err == "not_found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return category_entity.Inherit(path), nil
+}
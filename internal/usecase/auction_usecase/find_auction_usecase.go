@@ -2,11 +2,17 @@ package auction_usecase
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/bid_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/exchange_rate_usecase"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/settlement_usecase"
 )
 
 func (au *AuctionUseCase) FindAuctionById(
@@ -16,61 +22,108 @@ func (au *AuctionUseCase) FindAuctionById(
 		return nil, err
 	}
 
-	return &AuctionOutputDTO{
-		Id:          auctionEntity.Id,
-		ProductName: auctionEntity.ProductName,
-		Category:    auctionEntity.Category,
-		Description: auctionEntity.Description,
-		Condition:   ProductCondition(auctionEntity.Condition),
-		Status:      AuctionStatus(auctionEntity.Status),
-		Timestamp:   auctionEntity.Timestamp,
-	}, nil
+	watcherCount, err := au.watchlistRepositoryInterface.CountWatchersByAuctionId(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	auctionOutput := au.toAuctionOutput(*auctionEntity, time.Now())
+	auctionOutput.ReserveMet = reserveMet(auctionEntity)
+	auctionOutput.WatcherCount = &watcherCount
+
+	return &auctionOutput, nil
 }
 
 func (au *AuctionUseCase) FindAuctions(
 	ctx context.Context,
 	status AuctionStatus,
-	category, productName string) ([]AuctionOutputDTO, *internal_error.InternalError) {
-	auctionEntities, err := au.auctionRepositoryInterface.FindAuctions(
-		ctx, auction_entity.AuctionStatus(status), category, productName)
+	category, productName, currency string,
+	pageInput AuctionPageInputDTO) (*AuctionPageOutputDTO, *internal_error.InternalError) {
+	sort := auction_entity.AuctionSort(pageInput.Sort)
+	if sort == "" {
+		sort = auction_entity.SortNewest
+	}
+
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if err := sort.ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+
+	categories, err := au.findDescendantIds(ctx, category)
 	if err != nil {
 		return nil, err
 	}
 
-	var auctionOutputs []AuctionOutputDTO
+	auctionEntities, nextCursor, err := au.auctionRepositoryInterface.FindAuctions(
+		ctx, auction_entity.AuctionStatus(status), categories, productName, currency,
+		sort, pagination_entity.NewPageRequest(pageInput.Limit, pageInput.Cursor))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuctionPageOutputDTO{
+		Auctions:   au.toAuctionOutputs(auctionEntities),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// toAuctionOutput converts the auction with its bid summary, and the time remaining as of now,
+// which is returned as the server time so clients can keep their countdowns in sync
+func (au *AuctionUseCase) toAuctionOutput(auction auction_entity.Auction, now time.Time) AuctionOutputDTO {
+	auctionOutput := AuctionOutputDTO{
+		Id:            auction.Id,
+		ProductName:   auction.ProductName,
+		Category:      auction.Category,
+		Description:   auction.Description,
+		Condition:     ProductCondition(auction.Condition),
+		Currency:      auction.Currency,
+		SellerId:      auction.SellerId,
+		Attributes:    auction.Attributes,
+		Images:        au.toImageOutputs(auction.Images),
+		Status:        AuctionStatus(auction.Status),
+		Timestamp:     auction.Timestamp,
+		EndTime:       auction.EndTime,
+		ServerTime:    now,
+		TimeRemaining: int64(auction.TimeRemaining(now) / time.Second),
+		BidCount:      auction.BidCount,
+		BidderCount:   auction.BidderCount,
+	}
+
+	if !auction.HighBidAmount.IsZero() {
+		auctionOutput.HighBid = json.Number(auction.HighBidAmount.String())
+	}
+
+	return auctionOutput
+}
+
+// toAuctionOutputs converts a page of auctions, which never carries their watchers
+func (au *AuctionUseCase) toAuctionOutputs(auctionEntities []auction_entity.Auction) []AuctionOutputDTO {
+	now := time.Now()
+	auctionOutputs := make([]AuctionOutputDTO, 0, len(auctionEntities))
 	for _, value := range auctionEntities {
-		auctionOutputs = append(auctionOutputs, AuctionOutputDTO{
-			Id:          value.Id,
-			ProductName: value.ProductName,
-			Category:    value.Category,
-			Description: value.Description,
-			Condition:   ProductCondition(value.Condition),
-			Status:      AuctionStatus(value.Status),
-			Timestamp:   value.Timestamp,
-		})
-	}
-
-	return auctionOutputs, nil
+		auctionOutputs = append(auctionOutputs, au.toAuctionOutput(value, now))
+	}
+
+	return auctionOutputs
 }
 
 func (au *AuctionUseCase) FindWinningBidByAuctionId(
 	ctx context.Context,
-	auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError) {
-	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	auctionId, displayCurrency string) (*WinningInfoOutputDTO, *internal_error.InternalError) {
+	displayRates, err := exchange_rate_usecase.NewDisplayRates(
+		ctx, au.exchangeRateRepositoryInterface, displayCurrency)
 	if err != nil {
 		return nil, err
 	}
 
-	auctionOutputDTO := AuctionOutputDTO{
-		Id:          auction.Id,
-		ProductName: auction.ProductName,
-		Category:    auction.Category,
-		Description: auction.Description,
-		Condition:   ProductCondition(auction.Condition),
-		Status:      AuctionStatus(auction.Status),
-		Timestamp:   auction.Timestamp,
+	auction, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
 	}
 
+	auctionOutputDTO := au.toAuctionOutput(*auction, time.Now())
+	auctionOutputDTO.ReserveMet = reserveMet(auction)
+
 	bidWinning, err := au.bidRepositoryInterface.FindWinningBidByAuctionId(ctx, auction.Id)
 	if err != nil {
 		logger.Error("", err)
@@ -80,16 +133,40 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		}, nil
 	}
 
-	bidOutputDTO := &bid_usecase.BidOutputDTO{
-		Id:        bidWinning.Id,
-		UserId:    bidWinning.UserId,
-		AuctionId: bidWinning.AuctionId,
-		Amount:    bidWinning.Amount,
-		Timestamp: bidWinning.Timestamp,
+	bidOutputDTO := bid_usecase.NewBidOutputDTO(*bidWinning, displayRates)
+
+	fees, err := au.findWinningBidFees(ctx, auction, bidWinning.Id)
+	if err != nil {
+		return nil, err
 	}
 
 	return &WinningInfoOutputDTO{
 		Auction: auctionOutputDTO,
 		Bid:     bidOutputDTO,
+		Fees:    fees,
 	}, nil
 }
+
+// findWinningBidFees returns the fees recorded on the order of the winning bid, which only
+// exists once the auction was closed and settled
+func (au *AuctionUseCase) findWinningBidFees(
+	ctx context.Context,
+	auction *auction_entity.Auction,
+	bidId string) (*settlement_usecase.FeeBreakdownOutputDTO, *internal_error.InternalError) {
+	if auction.Status != auction_entity.Completed {
+		return nil, nil
+	}
+
+	orders, err := au.orderRepositoryInterface.FindOrdersByAuctionId(ctx, auction.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		if order.BidId == bidId {
+			return settlement_usecase.NewFeeBreakdownOutputDTO(order), nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,171 @@
+package auction_usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// AuctionSearchInputDTO filters an auction search. Prices are in currency, the default one when
+// it is not set, and only auctions in that currency match a price range. Sorting by price
+// requires the currency
+type AuctionSearchInputDTO struct {
+	AuctionPageInputDTO
+	Text         string    `form:"q" binding:"omitempty,max=200"`
+	Status       *int      `form:"status" binding:"omitempty,oneof=0 1"`
+	Category     string    `form:"category"`
+	Condition    int       `form:"condition" binding:"omitempty,oneof=1 2 3"`
+	SellerId     string    `form:"seller_id" binding:"omitempty,uuid"`
+	MinPrice     string    `form:"min_price" binding:"omitempty,numeric"`
+	MaxPrice     string    `form:"max_price" binding:"omitempty,numeric"`
+	Currency     string    `form:"currency"`
+	EndingAfter  time.Time `form:"ending_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndingBefore time.Time `form:"ending_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	NoBids       bool      `form:"no_bids"`
+}
+
+type CategoryFacetOutputDTO struct {
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+}
+
+type ConditionFacetOutputDTO struct {
+	Condition ProductCondition `json:"condition"`
+	Count     int64            `json:"count"`
+}
+
+// AuctionFacetsOutputDTO counts every auction matching the search, not only the ones in the page
+type AuctionFacetsOutputDTO struct {
+	Categories []CategoryFacetOutputDTO  `json:"categories"`
+	Conditions []ConditionFacetOutputDTO `json:"conditions"`
+}
+
+type AuctionSearchOutputDTO struct {
+	Auctions   []AuctionOutputDTO     `json:"auctions"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	Facets     AuctionFacetsOutputDTO `json:"facets"`
+}
+
+func (au *AuctionUseCase) SearchAuctions(
+	ctx context.Context,
+	searchInput AuctionSearchInputDTO) (*AuctionSearchOutputDTO, *internal_error.InternalError) {
+	search, err := toAuctionSearch(searchInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if search.Categories, err = au.findDescendantIds(ctx, searchInput.Category); err != nil {
+		return nil, err
+	}
+
+	sort := auction_entity.AuctionSort(searchInput.Sort)
+	if sort == "" {
+		sort = auction_entity.SortNewest
+	}
+
+	auctionEntities, nextCursor, err := au.auctionRepositoryInterface.SearchAuctions(
+		ctx, *search, sort, pagination_entity.NewPageRequest(searchInput.Limit, searchInput.Cursor))
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := au.auctionRepositoryInterface.FindAuctionFacets(ctx, *search)
+	if err != nil {
+		return nil, err
+	}
+
+	facetsOutput := AuctionFacetsOutputDTO{
+		Categories: make([]CategoryFacetOutputDTO, 0, len(facets.Categories)),
+		Conditions: make([]ConditionFacetOutputDTO, 0, len(facets.Conditions)),
+	}
+	for _, category := range facets.Categories {
+		facetsOutput.Categories = append(facetsOutput.Categories, CategoryFacetOutputDTO{
+			Category: category.Category,
+			Count:    category.Count,
+		})
+	}
+	for _, condition := range facets.Conditions {
+		facetsOutput.Conditions = append(facetsOutput.Conditions, ConditionFacetOutputDTO{
+			Condition: ProductCondition(condition.Condition),
+			Count:     condition.Count,
+		})
+	}
+
+	return &AuctionSearchOutputDTO{
+		Auctions:   au.toAuctionOutputs(auctionEntities),
+		NextCursor: nextCursor,
+		Facets:     facetsOutput,
+	}, nil
+}
+
+// findDescendantIds returns the category and its subcategories, since a category matches its
+// subcategories too. Categories outside the tree are matched as is, and none when it is empty
+func (au *AuctionUseCase) findDescendantIds(
+	ctx context.Context, category string) ([]string, *internal_error.InternalError) {
+	if category == "" {
+		return nil, nil
+	}
+
+	categories, err := au.categoryRepositoryInterface.FindDescendantIds(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return []string{category}, nil
+	}
+
+	return categories, nil
+}
+
+// toAuctionSearch reads the prices of the search in its currency and validates the ranges
+func toAuctionSearch(searchInput AuctionSearchInputDTO) (*auction_entity.AuctionSearch, *internal_error.InternalError) {
+	search := &auction_entity.AuctionSearch{
+		Text:         strings.TrimSpace(searchInput.Text),
+		Condition:    auction_entity.ProductCondition(searchInput.Condition),
+		SellerId:     searchInput.SellerId,
+		EndingAfter:  searchInput.EndingAfter,
+		EndingBefore: searchInput.EndingBefore,
+		NoBids:       searchInput.NoBids,
+	}
+
+	if searchInput.Status != nil {
+		status := auction_entity.AuctionStatus(*searchInput.Status)
+		search.Status = &status
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(searchInput.Currency))
+	if err := auction_entity.AuctionSort(searchInput.Sort).ValidateCurrency(currency); err != nil {
+		return nil, err
+	}
+	if currency == "" {
+		currency = money_entity.DefaultCurrency()
+	}
+
+	var err *internal_error.InternalError
+	if searchInput.MinPrice != "" {
+		if search.MinPrice, err = money_entity.Parse(searchInput.MinPrice, currency); err != nil {
+			return nil, err
+		}
+	}
+	if searchInput.MaxPrice != "" {
+		if search.MaxPrice, err = money_entity.Parse(searchInput.MaxPrice, currency); err != nil {
+			return nil, err
+		}
+	}
+
+	if searchInput.MinPrice != "" || searchInput.MaxPrice != "" ||
+		auction_entity.AuctionSort(searchInput.Sort) == auction_entity.SortHighestPrice {
+		search.Currency = currency
+	}
+
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
@@ -0,0 +1,404 @@
+package bid_usecase
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+type BatchMetricsOutputDTO struct {
+	PendingBids         int        `json:"pending_bids"`
+	FlushCount          int64      `json:"flush_count"`
+	FailedFlushCount    int64      `json:"failed_flush_count"`
+	FlushedBids         int64      `json:"flushed_bids"`
+	LastFlushSize       int64      `json:"last_flush_size"`
+	LastFlushDurationMs int64      `json:"last_flush_duration_ms"`
+	LastFlushAt         *time.Time `json:"last_flush_at,omitempty"`
+	ReplayedBids        int64      `json:"replayed_bids"`
+	QueueCapacity       int        `json:"queue_capacity"`
+	RejectedBids        int64      `json:"rejected_bids"`
+	DeadLetteredBids    int64      `json:"dead_lettered_bids"`
+}
+
+// BidBatcherConfig sizes the batches and the queue in front of them. MaxAttempts is how many
+// times a bid is sent before it is given up, and RetryBackoff the wait before its first retry,
+// doubled on each one after that
+type BidBatcherConfig struct {
+	MaxBatchSize        int
+	BatchInsertInterval time.Duration
+	QueueCapacity       int
+	EnqueueTimeout      time.Duration
+	MaxAttempts         int
+	RetryBackoff        time.Duration
+}
+
+// maxRetryBackoff bounds the wait between attempts however many attempts are allowed
+const maxRetryBackoff = time.Hour
+
+// retryBid is a bid that failed to be persisted, waiting for its next attempt
+type retryBid struct {
+	bid      bid_entity.Bid
+	attempts int
+	retryAt  time.Time
+}
+
+// BidBatcher groups incoming bids and persists them when the batch is full or the insert
+// interval elapses. Each instance owns its batch, and Close drains what is still queued. Bids are
+// written to the pending bid log before they are acknowledged and removed once persisted. Bids
+// that fail are retried with the first batch after their backoff, and the ones still failing on
+// Close are replayed on the next start. Bids that fail MaxAttempts times are moved to the dead
+// letter status of the pending bid log and their holds are released. The queue is bounded: when
+// it stays full for the enqueue timeout new bids are rejected
+type BidBatcher struct {
+	bidRepository        bid_entity.BidEntityRepository
+	pendingBidRepository bid_entity.PendingBidRepositoryInterface
+	// walletRepository releases the holds of dead-lettered bids, ignored when nil
+	walletRepository    wallet_entity.WalletRepositoryInterface
+	maxBatchSize        int
+	batchInsertInterval time.Duration
+	enqueueTimeout      time.Duration
+	maxAttempts         int
+	retryBackoff        time.Duration
+	bidChannel          chan bid_entity.Bid
+	queueSlots          chan struct{}
+	done                chan struct{}
+
+	closeMutex sync.RWMutex
+	closed     bool
+
+	pendingBids         atomic.Int64
+	flushCount          atomic.Int64
+	failedFlushCount    atomic.Int64
+	flushedBids         atomic.Int64
+	lastFlushSize       atomic.Int64
+	lastFlushDurationMs atomic.Int64
+	lastFlushAt         atomic.Int64
+	replayedBids        atomic.Int64
+	rejectedBids        atomic.Int64
+	deadLetteredBids    atomic.Int64
+}
+
+// NewBidBatcher replays the bids left in the pending bid log by a previous run before it starts
+// accepting new ones
+func NewBidBatcher(
+	bidRepository bid_entity.BidEntityRepository,
+	pendingBidRepository bid_entity.PendingBidRepositoryInterface,
+	walletRepository wallet_entity.WalletRepositoryInterface,
+	config BidBatcherConfig) *BidBatcher {
+	// A batch holds at least one bid, and a queue smaller than a batch would only be flushed by
+	// the timer
+	maxBatchSize := max(config.MaxBatchSize, 1)
+	queueCapacity := max(config.QueueCapacity, maxBatchSize)
+
+	bidBatcher := &BidBatcher{
+		bidRepository:        bidRepository,
+		pendingBidRepository: pendingBidRepository,
+		walletRepository:     walletRepository,
+		maxBatchSize:         maxBatchSize,
+		batchInsertInterval:  config.BatchInsertInterval,
+		enqueueTimeout:       config.EnqueueTimeout,
+		maxAttempts:          max(config.MaxAttempts, 1),
+		retryBackoff:         config.RetryBackoff,
+		bidChannel:           make(chan bid_entity.Bid, queueCapacity),
+		queueSlots:           make(chan struct{}, queueCapacity),
+		done:                 make(chan struct{}),
+	}
+
+	retries := bidBatcher.replay()
+
+	go bidBatcher.run(retries)
+
+	return bidBatcher
+}
+
+// Enqueue records the bid in the pending bid log and hands it over to the batch. It fails
+// once the batcher is closing, when the queue stays full for the enqueue timeout or when the
+// bid could not be recorded
+func (bb *BidBatcher) Enqueue(ctx context.Context, bid bid_entity.Bid) *internal_error.InternalError {
+	bb.closeMutex.RLock()
+	defer bb.closeMutex.RUnlock()
+
+	if bb.closed {
+		return internal_error.NewServiceUnavailableError("bid pipeline is shutting down")
+	}
+
+	timer := time.NewTimer(bb.enqueueTimeout)
+	defer timer.Stop()
+
+	// The slot is taken before the bid is recorded, so a rejected bid never reaches the pending bid log
+	select {
+	case bb.queueSlots <- struct{}{}:
+	case <-timer.C:
+		bb.rejectedBids.Add(1)
+		logger.Info("Bid rejected, the bid queue is full",
+			zap.Int("queueCapacity", cap(bb.queueSlots)))
+		return internal_error.NewServiceUnavailableError("bid queue is full, try again later")
+	case <-ctx.Done():
+		bb.rejectedBids.Add(1)
+		return internal_error.NewServiceUnavailableError("bid queue is full, try again later")
+	}
+
+	if err := bb.pendingBidRepository.AppendPendingBid(ctx, bid); err != nil {
+		<-bb.queueSlots
+		return err
+	}
+
+	bb.pendingBids.Add(1)
+	bb.bidChannel <- bid
+
+	return nil
+}
+
+// Close stops accepting bids, persists everything still queued or batched and waits for it
+// to finish or for the context to be done
+func (bb *BidBatcher) Close(ctx context.Context) *internal_error.InternalError {
+	bb.closeMutex.Lock()
+	if !bb.closed {
+		bb.closed = true
+		close(bb.bidChannel)
+	}
+	bb.closeMutex.Unlock()
+
+	select {
+	case <-bb.done:
+		logger.Info("Bid batcher drained", zap.Int64("flushedBids", bb.flushedBids.Load()))
+		return nil
+	case <-ctx.Done():
+		logger.Error("Timeout while draining the bid batcher", ctx.Err(),
+			zap.Int64("pendingBids", bb.pendingBids.Load()))
+		return internal_error.NewInternalServerError("timeout while draining the bid batcher")
+	}
+}
+
+func (bb *BidBatcher) Metrics() BatchMetricsOutputDTO {
+	metrics := BatchMetricsOutputDTO{
+		PendingBids:         int(bb.pendingBids.Load()),
+		FlushCount:          bb.flushCount.Load(),
+		FailedFlushCount:    bb.failedFlushCount.Load(),
+		FlushedBids:         bb.flushedBids.Load(),
+		LastFlushSize:       bb.lastFlushSize.Load(),
+		LastFlushDurationMs: bb.lastFlushDurationMs.Load(),
+		ReplayedBids:        bb.replayedBids.Load(),
+		QueueCapacity:       cap(bb.queueSlots),
+		RejectedBids:        bb.rejectedBids.Load(),
+		DeadLetteredBids:    bb.deadLetteredBids.Load(),
+	}
+
+	if lastFlushAt := bb.lastFlushAt.Load(); lastFlushAt != 0 {
+		lastFlushTime := time.UnixMilli(lastFlushAt)
+		metrics.LastFlushAt = &lastFlushTime
+	}
+
+	return metrics
+}
+
+// replay persists the bids that were acknowledged but not stored before the process stopped.
+// It returns the bids that failed again, to be retried after their backoff
+func (bb *BidBatcher) replay() []retryBid {
+	pendingBids, err := bb.pendingBidRepository.FindUnprocessedPendingBids(context.Background())
+	if err != nil {
+		return nil
+	}
+
+	if len(pendingBids) > 0 {
+		logger.Info("Replaying pending bids", zap.Int("pendingBids", len(pendingBids)))
+	}
+
+	var failedBids []bid_entity.Bid
+	for start := 0; start < len(pendingBids); start += bb.maxBatchSize {
+		end := min(start+bb.maxBatchSize, len(pendingBids))
+
+		bb.pendingBids.Add(int64(end - start))
+		failedBids = append(failedBids, bb.flush(pendingBids[start:end])...)
+	}
+
+	bb.replayedBids.Store(int64(len(pendingBids)))
+
+	return bb.retryLater(failedBids, nil)
+}
+
+// run batches the queued bids. retries holds the bids that failed to be persisted, which are
+// sent again with the first batch after their backoff. They no longer take queue slots
+func (bb *BidBatcher) run(retries []retryBid) {
+	defer close(bb.done)
+
+	timer := time.NewTimer(bb.batchInsertInterval)
+	defer timer.Stop()
+
+	var bidBatch []bid_entity.Bid
+
+	for {
+		select {
+		case bidEntity, ok := <-bb.bidChannel:
+			if !ok {
+				// Closing retries every bid once more, whatever its backoff
+				bb.flushQueued(retries, bidBatch, true)
+				return
+			}
+
+			bidBatch = append(bidBatch, bidEntity)
+
+			if len(bidBatch) >= bb.maxBatchSize {
+				retries = bb.flushQueued(retries, bidBatch, false)
+
+				bidBatch = nil
+				timer.Reset(bb.batchInsertInterval)
+			}
+		case <-timer.C:
+			retries = bb.flushQueued(retries, bidBatch, false)
+			bidBatch = nil
+			timer.Reset(bb.batchInsertInterval)
+		}
+	}
+}
+
+// flushQueued persists a batch taken from the queue along with the retries that are due, or all
+// of them when draining, frees the queue slots of the batch and returns the retries left
+func (bb *BidBatcher) flushQueued(retries []retryBid, bidBatch []bid_entity.Bid, draining bool) []retryBid {
+	now := time.Now()
+	attempts := make(map[string]int, len(retries))
+
+	var dueBids []bid_entity.Bid
+	var waiting []retryBid
+	for _, retry := range retries {
+		if !draining && now.Before(retry.retryAt) {
+			waiting = append(waiting, retry)
+			continue
+		}
+
+		attempts[retry.bid.Id] = retry.attempts
+		dueBids = append(dueBids, retry.bid)
+	}
+
+	failedBids := bb.flush(append(dueBids, bidBatch...))
+
+	for range bidBatch {
+		<-bb.queueSlots
+	}
+
+	return append(waiting, bb.retryLater(failedBids, attempts)...)
+}
+
+// retryLater schedules the failed bids for another attempt, given how many attempts each one
+// already failed before this one. Bids out of attempts are dead-lettered instead
+func (bb *BidBatcher) retryLater(failedBids []bid_entity.Bid, attempts map[string]int) []retryBid {
+	var retries []retryBid
+	var exhaustedBids []bid_entity.Bid
+	for _, bid := range failedBids {
+		failedAttempts := attempts[bid.Id] + 1
+		if failedAttempts >= bb.maxAttempts {
+			exhaustedBids = append(exhaustedBids, bid)
+			continue
+		}
+
+		retries = append(retries, retryBid{
+			bid:      bid,
+			attempts: failedAttempts,
+			retryAt:  time.Now().Add(bb.backoff(failedAttempts)),
+		})
+	}
+
+	bb.deadLetter(exhaustedBids)
+
+	return retries
+}
+
+// backoff doubles the retry backoff for each failed attempt after the first, up to maxRetryBackoff
+func (bb *BidBatcher) backoff(failedAttempts int) time.Duration {
+	backoff := bb.retryBackoff
+	for attempt := 1; attempt < failedAttempts && backoff < maxRetryBackoff; attempt++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxRetryBackoff)
+}
+
+// deadLetter gives up on bids that ran out of attempts. They are marked in the pending bid log,
+// so they are no longer replayed, and their holds are released. When they can't be marked they
+// stay pending and are replayed on the next start
+func (bb *BidBatcher) deadLetter(bids []bid_entity.Bid) {
+	if len(bids) == 0 {
+		return
+	}
+
+	bidIds := make([]string, 0, len(bids))
+	for _, bid := range bids {
+		bidIds = append(bidIds, bid.Id)
+	}
+
+	if err := bb.pendingBidRepository.DeadLetterPendingBids(context.Background(), bidIds); err != nil {
+		return
+	}
+
+	logger.Info("Bids moved to dead letter after failing every attempt",
+		zap.Strings("bidIds", bidIds), zap.Int("maxAttempts", bb.maxAttempts))
+
+	if bb.walletRepository != nil {
+		for _, bid := range bids {
+			if err := bb.walletRepository.ReleaseHold(
+				context.Background(), bid.UserId, bid.AuctionId, bid.Id); err != nil {
+				logger.Error("Error trying to release bid hold", err, zap.String("bidId", bid.Id))
+			}
+		}
+	}
+
+	bb.pendingBids.Add(-int64(len(bids)))
+	bb.deadLetteredBids.Add(int64(len(bids)))
+}
+
+// flush persists the batch and returns the bids that failed, which stay in the pending bid log
+func (bb *BidBatcher) flush(bidBatch []bid_entity.Bid) []bid_entity.Bid {
+	if len(bidBatch) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+
+	// Batches are persisted even while shutting down, so they don't inherit a cancelled context
+	results, err := bb.bidRepository.CreateBid(context.Background(), bidBatch)
+	if err != nil {
+		logger.Error("error trying to process bid batch list", err)
+		bb.failedFlushCount.Add(1)
+	}
+
+	// Bids without a result count as failed
+	processed := make(map[string]bool, len(results))
+	bidIds := make([]string, 0, len(results))
+	var storedCount int64
+	for _, result := range results {
+		if result.Outcome == bid_entity.BidFailed {
+			continue
+		}
+
+		processed[result.BidId] = true
+		bidIds = append(bidIds, result.BidId)
+		if result.Outcome == bid_entity.BidInserted || result.Outcome == bid_entity.BidDuplicated {
+			storedCount++
+		}
+	}
+
+	var failedBids []bid_entity.Bid
+	for _, bid := range bidBatch {
+		if !processed[bid.Id] {
+			failedBids = append(failedBids, bid)
+		}
+	}
+
+	bb.pendingBidRepository.RemovePendingBids(context.Background(), bidIds)
+
+	bb.pendingBids.Add(-int64(len(bidIds)))
+	bb.flushCount.Add(1)
+	bb.flushedBids.Add(storedCount)
+	bb.lastFlushSize.Store(int64(len(bidBatch)))
+	bb.lastFlushDurationMs.Store(time.Since(start).Milliseconds())
+	bb.lastFlushAt.Store(time.Now().UnixMilli())
+
+	return failedBids
+}
@@ -0,0 +1,356 @@
+package bid_usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type fakeBidRepository struct {
+	mutex sync.Mutex
+	bids  []bid_entity.Bid
+	// failures is the number of calls to CreateBid that fail before bids are stored
+	failures int
+}
+
+func (fr *fakeBidRepository) CreateBid(
+	ctx context.Context, bidEntities []bid_entity.Bid) ([]bid_entity.BidResult, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	var results []bid_entity.BidResult
+	if fr.failures > 0 {
+		fr.failures--
+		for _, bidEntity := range bidEntities {
+			results = append(results, bid_entity.BidResult{BidId: bidEntity.Id, Outcome: bid_entity.BidFailed})
+		}
+		return results, internal_error.NewInternalServerError("falha simulada")
+	}
+
+	for _, bidEntity := range bidEntities {
+		fr.bids = append(fr.bids, bidEntity)
+		results = append(results, bid_entity.BidResult{BidId: bidEntity.Id, Outcome: bid_entity.BidInserted})
+	}
+	return results, nil
+}
+
+func (fr *fakeBidRepository) FindBidByAuctionId(
+	ctx context.Context,
+	auctionId string,
+	sort bid_entity.BidSort,
+	page pagination_entity.PageRequest) ([]bid_entity.Bid, string, *internal_error.InternalError) {
+	return nil, "", nil
+}
+
+func (fr *fakeBidRepository) FindRankedBidsByAuctionId(
+	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	return nil, nil
+}
+
+func (fr *fakeBidRepository) FindWinningBidByAuctionId(
+	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, nil
+}
+
+type fakePendingBidRepository struct {
+	mutex        sync.Mutex
+	bids         map[string]bid_entity.Bid
+	deadLettered map[string]bid_entity.Bid
+}
+
+func newFakePendingBidRepository() *fakePendingBidRepository {
+	return &fakePendingBidRepository{
+		bids:         make(map[string]bid_entity.Bid),
+		deadLettered: make(map[string]bid_entity.Bid),
+	}
+}
+
+func (fr *fakePendingBidRepository) AppendPendingBid(
+	ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	fr.bids[bidEntity.Id] = bidEntity
+	return nil
+}
+
+func (fr *fakePendingBidRepository) FindUnprocessedPendingBids(
+	ctx context.Context) ([]bid_entity.Bid, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	var bidEntities []bid_entity.Bid
+	for _, bidEntity := range fr.bids {
+		bidEntities = append(bidEntities, bidEntity)
+	}
+	return bidEntities, nil
+}
+
+func (fr *fakePendingBidRepository) RemovePendingBids(
+	ctx context.Context, bidIds []string) *internal_error.InternalError {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	for _, bidId := range bidIds {
+		delete(fr.bids, bidId)
+	}
+	return nil
+}
+
+func (fr *fakePendingBidRepository) DeadLetterPendingBids(
+	ctx context.Context, bidIds []string) *internal_error.InternalError {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	for _, bidId := range bidIds {
+		if bidEntity, ok := fr.bids[bidId]; ok {
+			fr.deadLettered[bidId] = bidEntity
+			delete(fr.bids, bidId)
+		}
+	}
+	return nil
+}
+
+// TestBidBatcherCloseDrainsPendingBids tests that closing the batcher persists bids that did not fill a batch
+func TestBidBatcherCloseDrainsPendingBids(t *testing.T) {
+	repository := &fakeBidRepository{}
+	pendingRepository := newFakePendingBidRepository()
+	batcher := NewBidBatcher(repository, pendingRepository, nil, BidBatcherConfig{
+		MaxBatchSize:        10,
+		BatchInsertInterval: time.Hour,
+		QueueCapacity:       10,
+		EnqueueTimeout:      time.Second,
+	})
+	userId, auctionId := uuid.New().String(), uuid.New().String()
+
+	for i := 0; i < 3; i++ {
+		bid, err := bid_entity.CreateBid(userId, auctionId, money_entity.New(int64(10000+i), "BRL"))
+		if err != nil {
+			t.Fatalf("Erro ao criar lance: %v", err)
+		}
+		if err := batcher.Enqueue(context.Background(), *bid); err != nil {
+			t.Fatalf("Erro ao enfileirar lance: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := batcher.Close(ctx); err != nil {
+		t.Fatalf("Erro ao encerrar o batcher: %v", err)
+	}
+
+	if len(repository.bids) != 3 {
+		t.Errorf("Lances gravados esperados: 3, recebidos: %d", len(repository.bids))
+	}
+
+	if len(pendingRepository.bids) != 0 {
+		t.Errorf("O log de lances pendentes deveria estar vazio, recebidos: %d", len(pendingRepository.bids))
+	}
+
+	metrics := batcher.Metrics()
+	if metrics.PendingBids != 0 || metrics.FlushedBids != 3 || metrics.FlushCount != 1 {
+		t.Errorf("Métricas inesperadas após o encerramento: %+v", metrics)
+	}
+
+	bid, _ := bid_entity.CreateBid(userId, auctionId, money_entity.New(20000, "BRL"))
+	if err := batcher.Enqueue(context.Background(), *bid); err == nil {
+		t.Error("O batcher encerrado não deveria aceitar novos lances")
+	}
+}
+
+// TestBidBatcherReplaysPendingBids tests that bids left in the pending log by a previous run are persisted on start
+func TestBidBatcherReplaysPendingBids(t *testing.T) {
+	repository := &fakeBidRepository{}
+	pendingRepository := newFakePendingBidRepository()
+	userId, auctionId := uuid.New().String(), uuid.New().String()
+
+	for i := 0; i < 5; i++ {
+		bid, _ := bid_entity.CreateBid(userId, auctionId, money_entity.New(int64(10000+i), "BRL"))
+		pendingRepository.AppendPendingBid(context.Background(), *bid)
+	}
+
+	batcher := NewBidBatcher(repository, pendingRepository, nil, BidBatcherConfig{
+		MaxBatchSize:        2,
+		BatchInsertInterval: time.Hour,
+		QueueCapacity:       2,
+		EnqueueTimeout:      time.Second,
+	})
+	defer batcher.Close(context.Background())
+
+	if len(repository.bids) != 5 {
+		t.Errorf("Lances reprocessados esperados: 5, recebidos: %d", len(repository.bids))
+	}
+
+	if len(pendingRepository.bids) != 0 {
+		t.Errorf("O log de lances pendentes deveria estar vazio, recebidos: %d", len(pendingRepository.bids))
+	}
+
+	metrics := batcher.Metrics()
+	if metrics.ReplayedBids != 5 || metrics.FlushCount != 3 || metrics.PendingBids != 0 {
+		t.Errorf("Métricas inesperadas após o reprocessamento: %+v", metrics)
+	}
+}
+
+// TestBidBatcherRejectsWhenQueueIsFull tests that a saturated queue rejects bids after the enqueue timeout
+func TestBidBatcherRejectsWhenQueueIsFull(t *testing.T) {
+	repository := &fakeBidRepository{}
+	pendingRepository := newFakePendingBidRepository()
+	batcher := NewBidBatcher(repository, pendingRepository, nil, BidBatcherConfig{
+		MaxBatchSize:        3,
+		BatchInsertInterval: time.Hour,
+		QueueCapacity:       3,
+		EnqueueTimeout:      50 * time.Millisecond,
+	})
+	userId, auctionId := uuid.New().String(), uuid.New().String()
+
+	// Holds the batch back by keeping the batch size out of reach
+	batcher.maxBatchSize = 10
+
+	for i := 0; i < 3; i++ {
+		bid, _ := bid_entity.CreateBid(userId, auctionId, money_entity.New(int64(10000+i), "BRL"))
+		if err := batcher.Enqueue(context.Background(), *bid); err != nil {
+			t.Fatalf("Erro ao enfileirar lance: %v", err)
+		}
+	}
+
+	bid, _ := bid_entity.CreateBid(userId, auctionId, money_entity.New(20000, "BRL"))
+	err := batcher.Enqueue(context.Background(), *bid)
+	if err == nil || err.Err != "service_unavailable" {
+		t.Fatalf("Erro service_unavailable esperado, recebido: %v", err)
+	}
+
+	if _, ok := pendingRepository.bids[bid.Id]; ok {
+		t.Error("O lance rejeitado não deveria estar no log de lances pendentes")
+	}
+
+	metrics := batcher.Metrics()
+	if metrics.RejectedBids != 1 || metrics.PendingBids != 3 || metrics.QueueCapacity != 3 {
+		t.Errorf("Métricas inesperadas com a fila cheia: %+v", metrics)
+	}
+
+	batcher.Close(context.Background())
+	if len(repository.bids) != 3 {
+		t.Errorf("Lances gravados esperados: 3, recebidos: %d", len(repository.bids))
+	}
+}
+
+// TestBidBatcherRetriesFailedBids tests that bids that fail to be persisted are retried with the
+// next batch, without waiting for a restart
+func TestBidBatcherRetriesFailedBids(t *testing.T) {
+	repository := &fakeBidRepository{failures: 1}
+	pendingRepository := newFakePendingBidRepository()
+	batcher := NewBidBatcher(repository, pendingRepository, nil, BidBatcherConfig{
+		MaxBatchSize:        1,
+		BatchInsertInterval: 10 * time.Millisecond,
+		QueueCapacity:       1,
+		EnqueueTimeout:      time.Second,
+		MaxAttempts:         3,
+		RetryBackoff:        time.Millisecond,
+	})
+	defer batcher.Close(context.Background())
+
+	bid, _ := bid_entity.CreateBid(uuid.New().String(), uuid.New().String(), money_entity.New(10000, "BRL"))
+	if err := batcher.Enqueue(context.Background(), *bid); err != nil {
+		t.Fatalf("Erro ao enfileirar lance: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for batcher.Metrics().FlushedBids == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	metrics := batcher.Metrics()
+	if metrics.FlushedBids != 1 || metrics.FailedFlushCount != 1 || metrics.PendingBids != 0 {
+		t.Errorf("Métricas inesperadas após a nova tentativa: %+v", metrics)
+	}
+
+	pendingRepository.mutex.Lock()
+	defer pendingRepository.mutex.Unlock()
+	if len(pendingRepository.bids) != 0 {
+		t.Errorf("O log de lances pendentes deveria estar vazio, recebidos: %d", len(pendingRepository.bids))
+	}
+}
+
+// TestBidBatcherDeadLettersFailingBids tests that a bid that keeps failing is retried with a
+// growing backoff until it runs out of attempts, then dead-lettered with its hold released
+func TestBidBatcherDeadLettersFailingBids(t *testing.T) {
+	repository := &fakeBidRepository{failures: 1000}
+	pendingRepository := newFakePendingBidRepository()
+	walletRepository := &fakeWalletRepository{}
+	batcher := NewBidBatcher(repository, pendingRepository, walletRepository, BidBatcherConfig{
+		MaxBatchSize:        1,
+		BatchInsertInterval: 5 * time.Millisecond,
+		QueueCapacity:       1,
+		EnqueueTimeout:      time.Second,
+		MaxAttempts:         3,
+		RetryBackoff:        20 * time.Millisecond,
+	})
+	defer batcher.Close(context.Background())
+
+	bid, _ := bid_entity.CreateBid(uuid.New().String(), uuid.New().String(), money_entity.New(10000, "BRL"))
+	if err := batcher.Enqueue(context.Background(), *bid); err != nil {
+		t.Fatalf("Erro ao enfileirar lance: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for batcher.Metrics().DeadLetteredBids == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	metrics := batcher.Metrics()
+	if metrics.DeadLetteredBids != 1 || metrics.PendingBids != 0 {
+		t.Fatalf("Métricas inesperadas após esgotar as tentativas: %+v", metrics)
+	}
+
+	// Empty batches don't reach the repository, so each failure is one attempt of the bid
+	repository.mutex.Lock()
+	attempts := 1000 - repository.failures
+	repository.mutex.Unlock()
+	if attempts != 3 {
+		t.Errorf("Tentativas esperadas: 3, recebidas: %d", attempts)
+	}
+
+	pendingRepository.mutex.Lock()
+	_, deadLettered := pendingRepository.deadLettered[bid.Id]
+	pendingCount := len(pendingRepository.bids)
+	pendingRepository.mutex.Unlock()
+	if !deadLettered || pendingCount != 0 {
+		t.Error("O lance deveria ir para o status dead_letter do log de lances pendentes")
+	}
+
+	if len(walletRepository.released) != 1 || walletRepository.released[0] != bid.Id {
+		t.Errorf("A reserva do lance deveria ser liberada, liberadas: %v", walletRepository.released)
+	}
+}
+
+// TestBidBatcherInvalidBatchSize tests that a batch size below one doesn't stall the replay
+func TestBidBatcherInvalidBatchSize(t *testing.T) {
+	repository := &fakeBidRepository{}
+	pendingRepository := newFakePendingBidRepository()
+	userId, auctionId := uuid.New().String(), uuid.New().String()
+
+	for i := 0; i < 2; i++ {
+		bid, _ := bid_entity.CreateBid(userId, auctionId, money_entity.New(int64(10000+i), "BRL"))
+		pendingRepository.AppendPendingBid(context.Background(), *bid)
+	}
+
+	batcher := NewBidBatcher(repository, pendingRepository, nil, BidBatcherConfig{
+		MaxBatchSize:        0,
+		BatchInsertInterval: time.Hour,
+		QueueCapacity:       1,
+		EnqueueTimeout:      time.Second,
+	})
+	defer batcher.Close(context.Background())
+
+	if len(repository.bids) != 2 {
+		t.Errorf("Lances reprocessados esperados: 2, recebidos: %d", len(repository.bids))
+	}
+}
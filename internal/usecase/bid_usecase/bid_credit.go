@@ -0,0 +1,45 @@
+package bid_usecase
+
+import (
+	"context"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+// enqueueHeldBid holds the funds of the bid before queueing it, so a bid the user can't cover
+// is rejected in the response instead of after it was acknowledged. The hold of the user on the
+// auction covers their highest bid, so a new bid only raises it by the difference. When the bid
+// can't be queued a new hold is given back; a raised one is kept until the auction closes, since
+// it can't be lowered back to the previous bid
+func (bu *BidUseCase) enqueueHeldBid(
+	ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	previousHold, err := bu.WalletRepository.FindHold(ctx, bidEntity.UserId, bidEntity.AuctionId)
+	if err != nil {
+		return err
+	}
+
+	if err := bu.WalletRepository.HoldFunds(ctx, wallet_entity.Hold{
+		UserId:    bidEntity.UserId,
+		AuctionId: bidEntity.AuctionId,
+		BidId:     bidEntity.Id,
+		Amount:    bidEntity.Amount,
+	}); err != nil {
+		return err
+	}
+
+	if err := bu.bidBatcher.Enqueue(ctx, bidEntity); err != nil {
+		if previousHold == nil {
+			if releaseErr := bu.WalletRepository.ReleaseHold(
+				ctx, bidEntity.UserId, bidEntity.AuctionId, bidEntity.Id); releaseErr != nil {
+				logger.Error("Error trying to release bid hold", releaseErr, zap.String("bidId", bidEntity.Id))
+			}
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package bid_usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// fakeWalletRepository holds funds against a fixed available credit
+type fakeWalletRepository struct {
+	wallet_entity.WalletRepositoryInterface
+	available money_entity.Money
+	hold      *wallet_entity.Hold
+	released  []string
+}
+
+func (fr *fakeWalletRepository) FindHold(
+	ctx context.Context, userId, auctionId string) (*wallet_entity.Hold, *internal_error.InternalError) {
+	return fr.hold, nil
+}
+
+func (fr *fakeWalletRepository) HoldFunds(
+	ctx context.Context, hold wallet_entity.Hold) *internal_error.InternalError {
+	covered := fr.available
+	if fr.hold != nil {
+		covered = covered.Add(fr.hold.Amount)
+	}
+	if hold.Amount.Compare(covered) > 0 {
+		return wallet_entity.NewInsufficientCreditError()
+	}
+
+	hold.Status = wallet_entity.HoldActive
+	fr.hold = &hold
+	return nil
+}
+
+func (fr *fakeWalletRepository) ReleaseHold(
+	ctx context.Context, userId, auctionId, bidId string) *internal_error.InternalError {
+	fr.released = append(fr.released, bidId)
+	fr.hold = nil
+	return nil
+}
+
+// TestEnqueueHeldBid tests that bids the user can't cover are rejected before being queued, and
+// that a new hold is given back when the bid can't be queued
+func TestEnqueueHeldBid(t *testing.T) {
+	userId := uuid.New().String()
+	auctionId := uuid.New().String()
+
+	repository := &fakeBidRepository{}
+	pendingRepository := newFakePendingBidRepository()
+	batcher := NewBidBatcher(repository, pendingRepository, nil, BidBatcherConfig{
+		MaxBatchSize:        10,
+		BatchInsertInterval: time.Hour,
+		QueueCapacity:       10,
+		EnqueueTimeout:      time.Second,
+	})
+
+	walletRepository := &fakeWalletRepository{available: money_entity.New(2000, "BRL")}
+	bidUseCase := &BidUseCase{WalletRepository: walletRepository, bidBatcher: batcher}
+
+	bid, _ := bid_entity.CreateBid(userId, auctionId, money_entity.New(3000, "BRL"))
+	if err := bidUseCase.enqueueHeldBid(context.Background(), *bid); err == nil || err.Err != "bad_request" {
+		t.Errorf("Lance acima do crédito disponível deveria ser rejeitado, recebido %v", err)
+	}
+	if _, ok := pendingRepository.bids[bid.Id]; ok {
+		t.Error("O lance rejeitado não deveria ser enfileirado")
+	}
+
+	walletRepository.hold = &wallet_entity.Hold{
+		UserId:    userId,
+		AuctionId: auctionId,
+		Amount:    money_entity.New(1500, "BRL"),
+		Status:    wallet_entity.HoldActive,
+	}
+	if err := bidUseCase.enqueueHeldBid(context.Background(), *bid); err != nil {
+		t.Errorf("Lance coberto pelo valor já reservado no leilão foi rejeitado: %v", err)
+	}
+	if walletRepository.hold.BidId != bid.Id {
+		t.Error("A reserva deveria passar para o novo lance")
+	}
+
+	batcher.Close(context.Background())
+
+	walletRepository.hold = nil
+	otherBid, _ := bid_entity.CreateBid(userId, auctionId, money_entity.New(1000, "BRL"))
+	if err := bidUseCase.enqueueHeldBid(context.Background(), *otherBid); err == nil {
+		t.Fatal("O batcher encerrado não deveria aceitar novos lances")
+	}
+	if len(walletRepository.released) != 1 || walletRepository.released[0] != otherBid.Id {
+		t.Errorf("A reserva do lance não enfileirado deveria ser devolvida, liberadas: %v", walletRepository.released)
+	}
+}
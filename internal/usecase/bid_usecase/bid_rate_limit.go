@@ -0,0 +1,114 @@
+package bid_usecase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/rate_limit_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/user_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+// BidRateLimits are the token buckets applied to every bid: one per user, sized by the user role,
+// and one per auction
+type BidRateLimits struct {
+	UserLimits   map[user_entity.UserRole]rate_limit_entity.Limit
+	AuctionLimit rate_limit_entity.Limit
+}
+
+// getBidRateLimits reads BID_USER_RATE_LIMITS, e.g. "regular=2:10,verified=10:50", and
+// BID_AUCTION_RATE_LIMIT, e.g. "50:100". Invalid entries fall back to the defaults
+func getBidRateLimits() BidRateLimits {
+	rateLimits := BidRateLimits{
+		UserLimits: map[user_entity.UserRole]rate_limit_entity.Limit{
+			user_entity.RegularRole:  {Rate: 2, Burst: 10},
+			user_entity.VerifiedRole: {Rate: 10, Burst: 50},
+		},
+		AuctionLimit: rate_limit_entity.Limit{Rate: 50, Burst: 100},
+	}
+
+	if userLimits := os.Getenv("BID_USER_RATE_LIMITS"); userLimits != "" {
+		for _, roleLimit := range strings.Split(userLimits, ",") {
+			role, value, ok := strings.Cut(roleLimit, "=")
+			if !ok {
+				logger.Info("Ignoring invalid user bid rate limit", zap.String("value", roleLimit))
+				continue
+			}
+
+			limit, err := rate_limit_entity.ParseLimit(value)
+			if err != nil {
+				logger.Info("Ignoring invalid user bid rate limit", zap.String("value", roleLimit))
+				continue
+			}
+
+			rateLimits.UserLimits[user_entity.UserRole(strings.TrimSpace(role))] = limit
+		}
+	}
+
+	if auctionLimit := os.Getenv("BID_AUCTION_RATE_LIMIT"); auctionLimit != "" {
+		limit, err := rate_limit_entity.ParseLimit(auctionLimit)
+		if err != nil {
+			logger.Info("Ignoring invalid auction bid rate limit", zap.String("value", auctionLimit))
+		} else {
+			rateLimits.AuctionLimit = limit
+		}
+	}
+
+	return rateLimits
+}
+
+// checkRateLimits takes a token from the bucket of the user and from the bucket of the auction.
+// Neither is taken when either bucket is empty
+func (bu *BidUseCase) checkRateLimits(
+	ctx context.Context, bidEntity bid_entity.Bid) *internal_error.InternalError {
+	role, err := bu.findUserRole(ctx, bidEntity.UserId)
+	if err != nil {
+		return err
+	}
+
+	userLimit, ok := bu.rateLimits.UserLimits[role]
+	if !ok {
+		userLimit = bu.rateLimits.UserLimits[user_entity.RegularRole]
+	}
+
+	limited, retryAfter, err := bu.RateLimiter.AllowAll(ctx, []rate_limit_entity.Bucket{
+		{Key: "bid:user:" + bidEntity.UserId, Limit: userLimit},
+		{Key: "bid:auction:" + bidEntity.AuctionId, Limit: bu.rateLimits.AuctionLimit},
+	})
+	if err != nil {
+		return err
+	}
+
+	switch limited {
+	case 0:
+		return internal_error.NewTooManyRequestsError(
+			fmt.Sprintf("Too many bids from this user, try again in %s", retryAfter.Round(time.Millisecond)),
+			retryAfter)
+	case 1:
+		return internal_error.NewTooManyRequestsError(
+			fmt.Sprintf("Too many bids on this auction, try again in %s", retryAfter.Round(time.Millisecond)),
+			retryAfter)
+	}
+
+	return nil
+}
+
+// findUserRole treats users that are not registered as regular users
+func (bu *BidUseCase) findUserRole(
+	ctx context.Context, userId string) (user_entity.UserRole, *internal_error.InternalError) {
+	userEntity, err := bu.UserRepository.FindUserById(ctx, userId)
+	if err != nil {
+		if err.Err == "not_found" {
+			return user_entity.RegularRole, nil
+		}
+		return "", err
+	}
+
+	return userEntity.Role, nil
+}
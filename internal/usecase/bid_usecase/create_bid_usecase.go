@@ -2,133 +2,227 @@ package bid_usecase
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/exchange_rate_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/rate_limit_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/user_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/exchange_rate_usecase"
 )
 
+// BidInputDTO takes the amount as a JSON number, read as an exact decimal, e.g. 10.50.
+// The currency defaults to the auction's currency, bids in any other currency are rejected
 type BidInputDTO struct {
-	UserId    string  `json:"user_id"`
-	AuctionId string  `json:"auction_id"`
-	Amount    float64 `json:"amount"`
+	UserId    string      `json:"user_id"`
+	AuctionId string      `json:"auction_id"`
+	Amount    json.Number `json:"amount"`
+	Currency  string      `json:"currency"`
 }
 
 type BidOutputDTO struct {
-	Id        string    `json:"id"`
-	UserId    string    `json:"user_id"`
-	AuctionId string    `json:"auction_id"`
-	Amount    float64   `json:"amount"`
-	Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	Id        string      `json:"id"`
+	UserId    string      `json:"user_id"`
+	AuctionId string      `json:"auction_id"`
+	Amount    json.Number `json:"amount"`
+	Currency  string      `json:"currency"`
+	Timestamp time.Time   `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+
+	Display *exchange_rate_usecase.DisplayAmountDTO `json:"display,omitempty"`
 }
 
-type BidUseCase struct {
-	BidRepository bid_entity.BidEntityRepository
-
-	timer               *time.Timer
-	maxBatchSize        int
-	batchInsertInterval time.Duration
-	bidChannel          chan bid_entity.Bid
+// NewBidOutputDTO writes the amount as an exact decimal in major units, along with its
+// indicative conversion when displayRates is set
+func NewBidOutputDTO(
+	bid bid_entity.Bid, displayRates *exchange_rate_usecase.DisplayRates) *BidOutputDTO {
+	return &BidOutputDTO{
+		Id:        bid.Id,
+		UserId:    bid.UserId,
+		AuctionId: bid.AuctionId,
+		Amount:    json.Number(bid.Amount.String()),
+		Currency:  bid.Amount.Currency,
+		Timestamp: bid.Timestamp,
+		Display:   displayRates.Display(bid.Amount),
+	}
 }
 
-func NewBidUseCase(bidRepository bid_entity.BidEntityRepository) BidUseCaseInterface {
-	maxSizeInterval := getMaxBatchSizeInterval()
-	maxBatchSize := getMaxBatchSize()
+type BidUseCase struct {
+	BidRepository          bid_entity.BidEntityRepository
+	PendingBidRepository   bid_entity.PendingBidRepositoryInterface
+	AuctionRepository      auction_entity.AuctionRepositoryInterface
+	UserRepository         user_entity.UserRepositoryInterface
+	ExchangeRateRepository exchange_rate_entity.ExchangeRateRepositoryInterface
+	WalletRepository       wallet_entity.WalletRepositoryInterface
+	RateLimiter            rate_limit_entity.RateLimiterInterface
+
+	bidBatcher *BidBatcher
+	rateLimits BidRateLimits
+}
 
-	bidUseCase := &BidUseCase{
-		BidRepository:       bidRepository,
-		maxBatchSize:        maxBatchSize,
-		batchInsertInterval: maxSizeInterval,
-		timer:               time.NewTimer(maxSizeInterval),
-		bidChannel:          make(chan bid_entity.Bid, maxBatchSize),
+func NewBidUseCase(
+	bidRepository bid_entity.BidEntityRepository,
+	pendingBidRepository bid_entity.PendingBidRepositoryInterface,
+	auctionRepository auction_entity.AuctionRepositoryInterface,
+	userRepository user_entity.UserRepositoryInterface,
+	exchangeRateRepository exchange_rate_entity.ExchangeRateRepositoryInterface,
+	walletRepository wallet_entity.WalletRepositoryInterface,
+	rateLimiter rate_limit_entity.RateLimiterInterface) BidUseCaseInterface {
+	config := BidBatcherConfig{
+		MaxBatchSize:        getMaxBatchSize(),
+		BatchInsertInterval: getMaxBatchSizeInterval(),
+		QueueCapacity:       getBidQueueCapacity(),
+		EnqueueTimeout:      getBidEnqueueTimeout(),
+		MaxAttempts:         getBidMaxAttempts(),
+		RetryBackoff:        getBidRetryBackoff(),
 	}
 
-	bidUseCase.triggerCreateRoutine(context.Background())
-
-	return bidUseCase
+	return &BidUseCase{
+		BidRepository:          bidRepository,
+		PendingBidRepository:   pendingBidRepository,
+		AuctionRepository:      auctionRepository,
+		UserRepository:         userRepository,
+		ExchangeRateRepository: exchangeRateRepository,
+		WalletRepository:       walletRepository,
+		RateLimiter:            rateLimiter,
+		bidBatcher:             NewBidBatcher(bidRepository, pendingBidRepository, walletRepository, config),
+		rateLimits:             getBidRateLimits(),
+	}
 }
 
-var bidBatch []bid_entity.Bid
-
 type BidUseCaseInterface interface {
 	CreateBid(
 		ctx context.Context,
 		bidInputDTO BidInputDTO) *internal_error.InternalError
 
 	FindWinningBidByAuctionId(
-		ctx context.Context, auctionId string) (*BidOutputDTO, *internal_error.InternalError)
+		ctx context.Context,
+		auctionId, displayCurrency string) (*BidOutputDTO, *internal_error.InternalError)
 
 	FindBidByAuctionId(
-		ctx context.Context, auctionId string) ([]BidOutputDTO, *internal_error.InternalError)
-}
+		ctx context.Context,
+		auctionId, displayCurrency string,
+		pageInput BidPageInputDTO) (*BidPageOutputDTO, *internal_error.InternalError)
 
-func (bu *BidUseCase) triggerCreateRoutine(ctx context.Context) {
-	go func() {
-		defer close(bu.bidChannel)
-
-		for {
-			select {
-			case bidEntity, ok := <-bu.bidChannel:
-				if !ok {
-					if len(bidBatch) > 0 {
-						if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-							logger.Error("error trying to process bid batch list", err)
-						}
-					}
-					return
-				}
-
-				bidBatch = append(bidBatch, bidEntity)
-
-				if len(bidBatch) >= bu.maxBatchSize {
-					if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-						logger.Error("error trying to process bid batch list", err)
-					}
-
-					bidBatch = nil
-					bu.timer.Reset(bu.batchInsertInterval)
-				}
-			case <-bu.timer.C:
-				if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-					logger.Error("error trying to process bid batch list", err)
-				}
-				bidBatch = nil
-				bu.timer.Reset(bu.batchInsertInterval)
-			}
-		}
-	}()
+	FindBatchMetrics(ctx context.Context) BatchMetricsOutputDTO
+
+	Close(ctx context.Context) *internal_error.InternalError
 }
 
 func (bu *BidUseCase) CreateBid(
 	ctx context.Context,
 	bidInputDTO BidInputDTO) *internal_error.InternalError {
 
-	bidEntity, err := bid_entity.CreateBid(bidInputDTO.UserId, bidInputDTO.AuctionId, bidInputDTO.Amount)
+	if err := uuid.Validate(bidInputDTO.AuctionId); err != nil {
+		return internal_error.NewBadRequestError("AuctionId is not a valid id")
+	}
+
+	auctionState, err := bu.AuctionRepository.FindAuctionState(ctx, bidInputDTO.AuctionId)
 	if err != nil {
 		return err
 	}
 
-	bu.bidChannel <- *bidEntity
+	// Auctions created before multi-currency support are in the default currency
+	auctionCurrency := auctionState.Currency
+	if auctionCurrency == "" {
+		auctionCurrency = money_entity.DefaultCurrency()
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(bidInputDTO.Currency))
+	if currency == "" {
+		currency = auctionCurrency
+	}
+	if currency != auctionCurrency {
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("Bids on this auction must be placed in %s", auctionCurrency))
+	}
+
+	amount, err := money_entity.Parse(bidInputDTO.Amount.String(), currency)
+	if err != nil {
+		return err
+	}
+
+	bidEntity, err := bid_entity.CreateBid(bidInputDTO.UserId, bidInputDTO.AuctionId, amount)
+	if err != nil {
+		return err
+	}
+
+	if err := bu.checkRateLimits(ctx, *bidEntity); err != nil {
+		return err
+	}
+
+	return bu.enqueueHeldBid(ctx, *bidEntity)
+}
 
-	return nil
+func (bu *BidUseCase) FindBatchMetrics(ctx context.Context) BatchMetricsOutputDTO {
+	return bu.bidBatcher.Metrics()
+}
+
+// Close drains the bid batch, persisting every bid that was already accepted
+func (bu *BidUseCase) Close(ctx context.Context) *internal_error.InternalError {
+	return bu.bidBatcher.Close(ctx)
 }
 
 func getMaxBatchSizeInterval() time.Duration {
 	batchInsertInterval := os.Getenv("BATCH_INSERT_INTERVAL")
 	duration, err := time.ParseDuration(batchInsertInterval)
-	if err != nil {
+	if err != nil || duration <= 0 {
 		return 3 * time.Minute
 	}
 
 	return duration
 }
 
+func getBidQueueCapacity() int {
+	value, err := strconv.Atoi(os.Getenv("BID_QUEUE_CAPACITY"))
+	if err != nil || value <= 0 {
+		return 1000
+	}
+
+	return value
+}
+
+func getBidEnqueueTimeout() time.Duration {
+	enqueueTimeout := os.Getenv("BID_ENQUEUE_TIMEOUT")
+	duration, err := time.ParseDuration(enqueueTimeout)
+	if err != nil || duration <= 0 {
+		return 2 * time.Second
+	}
+
+	return duration
+}
+
+func getBidMaxAttempts() int {
+	value, err := strconv.Atoi(os.Getenv("BID_MAX_ATTEMPTS"))
+	if err != nil || value <= 0 {
+		return 5
+	}
+
+	return value
+}
+
+func getBidRetryBackoff() time.Duration {
+	retryBackoff := os.Getenv("BID_RETRY_BACKOFF")
+	duration, err := time.ParseDuration(retryBackoff)
+	if err != nil || duration <= 0 {
+		return 30 * time.Second
+	}
+
+	return duration
+}
+
 func getMaxBatchSize() int {
 	value, err := strconv.Atoi(os.Getenv("MAX_BATCH_SIZE"))
-	if err != nil {
+	if err != nil || value <= 0 {
 		return 5
 	}
 
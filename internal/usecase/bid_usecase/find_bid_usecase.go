@@ -3,44 +3,68 @@ package bid_usecase
 import (
 	"context"
 
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/pagination_entity"
 	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/usecase/exchange_rate_usecase"
 )
 
+// BidPageInputDTO selects a page of the bids of an auction, the newest first by default
+type BidPageInputDTO struct {
+	Limit  int64  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=newest highest_amount"`
+}
+
+// BidPageOutputDTO carries the cursor of the next page, omitted on the last one
+type BidPageOutputDTO struct {
+	Bids       []BidOutputDTO `json:"bids"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
 func (bu *BidUseCase) FindBidByAuctionId(
-	ctx context.Context, auctionId string) ([]BidOutputDTO, *internal_error.InternalError) {
-	bidList, err := bu.BidRepository.FindBidByAuctionId(ctx, auctionId)
+	ctx context.Context,
+	auctionId, displayCurrency string,
+	pageInput BidPageInputDTO) (*BidPageOutputDTO, *internal_error.InternalError) {
+	displayRates, err := exchange_rate_usecase.NewDisplayRates(ctx, bu.ExchangeRateRepository, displayCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := bid_entity.BidSort(pageInput.Sort)
+	if sort == "" {
+		sort = bid_entity.SortNewest
+	}
+
+	bidList, nextCursor, err := bu.BidRepository.FindBidByAuctionId(
+		ctx, auctionId, sort, pagination_entity.NewPageRequest(pageInput.Limit, pageInput.Cursor))
 	if err != nil {
 		return nil, err
 	}
 
-	var bidOutputList []BidOutputDTO
+	bidOutputList := make([]BidOutputDTO, 0, len(bidList))
 	for _, bid := range bidList {
-		bidOutputList = append(bidOutputList, BidOutputDTO{
-			Id:        bid.Id,
-			UserId:    bid.UserId,
-			AuctionId: bid.AuctionId,
-			Amount:    bid.Amount,
-			Timestamp: bid.Timestamp,
-		})
+		bidOutputList = append(bidOutputList, *NewBidOutputDTO(bid, displayRates))
 	}
 
-	return bidOutputList, nil
+	return &BidPageOutputDTO{
+		Bids:       bidOutputList,
+		NextCursor: nextCursor,
+	}, nil
 }
 
 func (bu *BidUseCase) FindWinningBidByAuctionId(
-	ctx context.Context, auctionId string) (*BidOutputDTO, *internal_error.InternalError) {
-	bidEntity, err := bu.BidRepository.FindWinningBidByAuctionId(ctx, auctionId)
+	ctx context.Context,
+	auctionId, displayCurrency string) (*BidOutputDTO, *internal_error.InternalError) {
+	displayRates, err := exchange_rate_usecase.NewDisplayRates(ctx, bu.ExchangeRateRepository, displayCurrency)
 	if err != nil {
 		return nil, err
 	}
 
-	bidOutput := &BidOutputDTO{
-		Id:        bidEntity.Id,
-		UserId:    bidEntity.UserId,
-		AuctionId: bidEntity.AuctionId,
-		Amount:    bidEntity.Amount,
-		Timestamp: bidEntity.Timestamp,
+	bidEntity, err := bu.BidRepository.FindWinningBidByAuctionId(ctx, auctionId)
+	if err != nil {
+		return nil, err
 	}
 
-	return bidOutput, nil
+	return NewBidOutputDTO(*bidEntity, displayRates), nil
 }
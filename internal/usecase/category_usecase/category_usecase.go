@@ -0,0 +1,263 @@
+package category_usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/category_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// AttributeDTO defines an item attribute. Type is text by default, and enum attributes list the
+// values they accept
+type AttributeDTO struct {
+	Name     string   `json:"name" binding:"required"`
+	Type     string   `json:"type" binding:"omitempty,oneof=text number boolean enum"`
+	Values   []string `json:"values,omitempty" binding:"dive,required"`
+	Required bool     `json:"required"`
+}
+
+type CategoryInputDTO struct {
+	Id         string         `json:"id" binding:"required"`
+	Name       string         `json:"name" binding:"required"`
+	ParentId   string         `json:"parent_id"`
+	Attributes []AttributeDTO `json:"attributes" binding:"dive"`
+}
+
+// CategoryUpdateInputDTO replaces the name and attributes of a category. Categories can't be
+// moved in the tree
+type CategoryUpdateInputDTO struct {
+	Name       string         `json:"name" binding:"required"`
+	Attributes []AttributeDTO `json:"attributes" binding:"dive"`
+}
+
+// CategoryOutputDTO lists the attributes auctions in the category can supply, including the
+// ones inherited from its ancestors when a single category is returned
+type CategoryOutputDTO struct {
+	Id         string              `json:"id"`
+	Name       string              `json:"name"`
+	ParentId   string              `json:"parent_id,omitempty"`
+	Ancestors  []string            `json:"ancestors"`
+	Attributes []AttributeDTO      `json:"attributes"`
+	Children   []CategoryOutputDTO `json:"children,omitempty"`
+}
+
+type CategoryUseCase struct {
+	categoryRepository category_entity.CategoryRepositoryInterface
+	auctionRepository  auction_entity.AuctionRepositoryInterface
+}
+
+func NewCategoryUseCase(
+	categoryRepository category_entity.CategoryRepositoryInterface,
+	auctionRepository auction_entity.AuctionRepositoryInterface) CategoryUseCaseInterface {
+	return &CategoryUseCase{
+		categoryRepository: categoryRepository,
+		auctionRepository:  auctionRepository,
+	}
+}
+
+type CategoryUseCaseInterface interface {
+	CreateCategory(
+		ctx context.Context,
+		categoryInput CategoryInputDTO) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	UpdateCategory(
+		ctx context.Context,
+		id string,
+		categoryInput CategoryUpdateInputDTO) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	DeleteCategory(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	FindCategoryById(
+		ctx context.Context, id string) (*CategoryOutputDTO, *internal_error.InternalError)
+
+	FindCategoryTree(
+		ctx context.Context) ([]CategoryOutputDTO, *internal_error.InternalError)
+}
+
+func (cu *CategoryUseCase) CreateCategory(
+	ctx context.Context,
+	categoryInput CategoryInputDTO) (*CategoryOutputDTO, *internal_error.InternalError) {
+	var parent *category_entity.Category
+	if categoryInput.ParentId != "" {
+		var err *internal_error.InternalError
+		if parent, err = cu.categoryRepository.FindCategoryById(ctx, categoryInput.ParentId); err != nil {
+			return nil, err
+		}
+	}
+
+	category, err := category_entity.CreateCategory(
+		categoryInput.Id, categoryInput.Name, parent, toAttributes(categoryInput.Attributes))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cu.categoryRepository.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	// The parent may have been deleted while the category was created, before the deletion
+	// could see the new subcategory
+	if parent != nil {
+		if _, err := cu.categoryRepository.FindCategoryById(ctx, parent.Id); err != nil {
+			if deleteErr := cu.categoryRepository.DeleteCategory(ctx, category.Id); deleteErr != nil {
+				return nil, deleteErr
+			}
+			return nil, err
+		}
+	}
+
+	return cu.FindCategoryById(ctx, category.Id)
+}
+
+func (cu *CategoryUseCase) UpdateCategory(
+	ctx context.Context,
+	id string,
+	categoryInput CategoryUpdateInputDTO) (*CategoryOutputDTO, *internal_error.InternalError) {
+	category, err := cu.categoryRepository.FindCategoryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	category.Name = categoryInput.Name
+	category.Attributes = toAttributes(categoryInput.Attributes)
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := cu.categoryRepository.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	return cu.FindCategoryById(ctx, id)
+}
+
+// DeleteCategory only deletes categories without subcategories and auctions, so no auction is
+// left in a category that no longer exists
+// DeleteCategory deletes a category without subcategories and auctions. They are counted again
+// once the category is deleted, since one may have been created in the meantime, and the
+// category is restored for it
+func (cu *CategoryUseCase) DeleteCategory(
+	ctx context.Context, id string) *internal_error.InternalError {
+	category, err := cu.categoryRepository.FindCategoryById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := cu.checkUnused(ctx, id); err != nil {
+		return err
+	}
+
+	if err := cu.categoryRepository.DeleteCategory(ctx, id); err != nil {
+		return err
+	}
+
+	if err := cu.checkUnused(ctx, id); err != nil {
+		if restoreErr := cu.categoryRepository.CreateCategory(ctx, category); restoreErr != nil {
+			return restoreErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkUnused rejects categories with subcategories or auctions
+func (cu *CategoryUseCase) checkUnused(
+	ctx context.Context, id string) *internal_error.InternalError {
+	children, err := cu.categoryRepository.CountChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("Category %s has subcategories and can't be deleted", id))
+	}
+
+	auctions, err := cu.auctionRepository.CountAuctionsByCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	if auctions > 0 {
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("Category %s has auctions and can't be deleted", id))
+	}
+
+	return nil
+}
+
+func (cu *CategoryUseCase) FindCategoryById(
+	ctx context.Context, id string) (*CategoryOutputDTO, *internal_error.InternalError) {
+	path, err := cu.categoryRepository.FindCategoryPath(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryOutput := toCategoryOutput(*category_entity.Inherit(path))
+	return &categoryOutput, nil
+}
+
+// FindCategoryTree returns the root categories with their subcategories nested in children.
+// Each category lists only its own attributes
+func (cu *CategoryUseCase) FindCategoryTree(
+	ctx context.Context) ([]CategoryOutputDTO, *internal_error.InternalError) {
+	categories, err := cu.categoryRepository.FindCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	childrenByParent := make(map[string][]category_entity.Category)
+	for _, category := range categories {
+		childrenByParent[category.ParentId] = append(childrenByParent[category.ParentId], category)
+	}
+
+	var buildTree func(parentId string) []CategoryOutputDTO
+	buildTree = func(parentId string) []CategoryOutputDTO {
+		nodes := make([]CategoryOutputDTO, 0, len(childrenByParent[parentId]))
+		for _, category := range childrenByParent[parentId] {
+			node := toCategoryOutput(category)
+			node.Children = buildTree(category.Id)
+			nodes = append(nodes, node)
+		}
+
+		return nodes
+	}
+
+	return buildTree(""), nil
+}
+
+func toAttributes(attributesInput []AttributeDTO) []category_entity.Attribute {
+	attributes := make([]category_entity.Attribute, 0, len(attributesInput))
+	for _, attribute := range attributesInput {
+		attributes = append(attributes, category_entity.Attribute{
+			Name:     attribute.Name,
+			Type:     category_entity.AttributeType(attribute.Type),
+			Values:   attribute.Values,
+			Required: attribute.Required,
+		})
+	}
+
+	return attributes
+}
+
+func toCategoryOutput(category category_entity.Category) CategoryOutputDTO {
+	attributes := make([]AttributeDTO, 0, len(category.Attributes))
+	for _, attribute := range category.Attributes {
+		attributes = append(attributes, AttributeDTO{
+			Name:     attribute.Name,
+			Type:     string(attribute.Type),
+			Values:   attribute.Values,
+			Required: attribute.Required,
+		})
+	}
+
+	return CategoryOutputDTO{
+		Id:         category.Id,
+		Name:       category.Name,
+		ParentId:   category.ParentId,
+		Ancestors:  category.Ancestors,
+		Attributes: attributes,
+	}
+}
@@ -0,0 +1,89 @@
+package exchange_rate_usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/exchange_rate_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+const displayAmountNotice = "Indicative conversion only, the native amount is authoritative"
+
+// DisplayAmountDTO is a native amount converted into the currency the client asked for
+type DisplayAmountDTO struct {
+	Amount         json.Number `json:"amount"`
+	Currency       string      `json:"currency"`
+	Rate           json.Number `json:"rate"`
+	RatesUpdatedAt time.Time   `json:"rates_updated_at" time_format:"2006-01-02 15:04:05"`
+	Indicative     bool        `json:"indicative"`
+	Notice         string      `json:"notice"`
+}
+
+// DisplayRates converts native amounts for display. A nil DisplayRates converts nothing
+type DisplayRates struct {
+	rateTable *exchange_rate_entity.RateTable
+	currency  string
+}
+
+// NewDisplayRates loads the rates to display amounts in the given currency. An empty currency
+// means no conversion was asked for
+func NewDisplayRates(
+	ctx context.Context,
+	exchangeRateRepository exchange_rate_entity.ExchangeRateRepositoryInterface,
+	currency string) (*DisplayRates, *internal_error.InternalError) {
+	currency = normalizeCurrency(currency)
+	if currency == "" {
+		return nil, nil
+	}
+
+	if _, ok := money_entity.Exponent(currency); !ok {
+		return nil, internal_error.NewBadRequestError(fmt.Sprintf("Currency %s is not supported", currency))
+	}
+
+	rateTable, err := exchangeRateRepository.FindRateTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := rateTable.Rates[currency]; !ok {
+		return nil, internal_error.NewBadRequestError(fmt.Sprintf("There is no exchange rate for %s", currency))
+	}
+
+	return &DisplayRates{
+		rateTable: rateTable,
+		currency:  currency,
+	}, nil
+}
+
+// Display returns nil when no conversion was asked for or the native currency has no rate
+func (dr *DisplayRates) Display(amount money_entity.Money) *DisplayAmountDTO {
+	if dr == nil {
+		return nil
+	}
+
+	rate, err := dr.rateTable.Rate(amount.Currency, dr.currency)
+	if err != nil {
+		logger.Error("Error trying to convert amount for display", err)
+		return nil
+	}
+
+	converted, err := dr.rateTable.Convert(amount, dr.currency)
+	if err != nil {
+		logger.Error("Error trying to convert amount for display", err)
+		return nil
+	}
+
+	return &DisplayAmountDTO{
+		Amount:         json.Number(converted.String()),
+		Currency:       converted.Currency,
+		Rate:           json.Number(formatRate(rate)),
+		RatesUpdatedAt: dr.rateTable.UpdatedAt,
+		Indicative:     true,
+		Notice:         displayAmountNotice,
+	}
+}
@@ -0,0 +1,131 @@
+package exchange_rate_usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/exchange_rate_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// RateTableInputDTO gives how many units of each currency one unit of the base currency buys,
+// e.g. {"base": "USD", "rates": {"BRL": 5.10, "EUR": 0.92}}. It is also the format of
+// EXCHANGE_RATES_FILE
+type RateTableInputDTO struct {
+	Base  string                 `json:"base"`
+	Rates map[string]json.Number `json:"rates"`
+}
+
+type RateTableOutputDTO struct {
+	Base      string                 `json:"base"`
+	Rates     map[string]json.Number `json:"rates"`
+	UpdatedAt time.Time              `json:"updated_at" time_format:"2006-01-02 15:04:05"`
+}
+
+type ExchangeRateUseCase struct {
+	ExchangeRateRepository exchange_rate_entity.ExchangeRateRepositoryInterface
+}
+
+func NewExchangeRateUseCase(
+	exchangeRateRepository exchange_rate_entity.ExchangeRateRepositoryInterface) ExchangeRateUseCaseInterface {
+	return &ExchangeRateUseCase{
+		ExchangeRateRepository: exchangeRateRepository,
+	}
+}
+
+type ExchangeRateUseCaseInterface interface {
+	UpdateRateTable(
+		ctx context.Context, rateTableInput RateTableInputDTO) *internal_error.InternalError
+
+	FindRateTable(
+		ctx context.Context) (*RateTableOutputDTO, *internal_error.InternalError)
+
+	LoadRateTableFile(
+		ctx context.Context, path string) *internal_error.InternalError
+}
+
+func (eu *ExchangeRateUseCase) UpdateRateTable(
+	ctx context.Context, rateTableInput RateTableInputDTO) *internal_error.InternalError {
+	rates := make(map[string]*big.Rat, len(rateTableInput.Rates))
+	for currency, value := range rateTableInput.Rates {
+		rate, ok := new(big.Rat).SetString(value.String())
+		if !ok {
+			return internal_error.NewBadRequestError(fmt.Sprintf("Rate of %s is not a valid number", currency))
+		}
+		rates[normalizeCurrency(currency)] = rate
+	}
+
+	rateTable, err := exchange_rate_entity.CreateRateTable(normalizeCurrency(rateTableInput.Base), rates)
+	if err != nil {
+		return err
+	}
+
+	return eu.ExchangeRateRepository.UpdateRateTable(ctx, rateTable)
+}
+
+func (eu *ExchangeRateUseCase) FindRateTable(
+	ctx context.Context) (*RateTableOutputDTO, *internal_error.InternalError) {
+	rateTable, err := eu.ExchangeRateRepository.FindRateTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[string]json.Number, len(rateTable.Rates))
+	for currency, rate := range rateTable.Rates {
+		rates[currency] = json.Number(formatRate(rate))
+	}
+
+	return &RateTableOutputDTO{
+		Base:      rateTable.Base,
+		Rates:     rates,
+		UpdatedAt: rateTable.UpdatedAt,
+	}, nil
+}
+
+// LoadRateTableFile replaces the stored rates with the ones in the file, so a deploy can ship
+// its own rate table. An empty path keeps the stored rates
+func (eu *ExchangeRateUseCase) LoadRateTableFile(
+	ctx context.Context, path string) *internal_error.InternalError {
+	if path == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error("Error trying to read exchange rates file", err)
+		return internal_error.NewInternalServerError("Error trying to read exchange rates file")
+	}
+
+	var rateTableInput RateTableInputDTO
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&rateTableInput); err != nil {
+		logger.Error("Error trying to decode exchange rates file", err)
+		return internal_error.NewInternalServerError("Error trying to decode exchange rates file")
+	}
+
+	return eu.UpdateRateTable(ctx, rateTableInput)
+}
+
+// GetExchangeRatesFile returns the rate table loaded on startup
+func GetExchangeRatesFile() string {
+	return os.Getenv("EXCHANGE_RATES_FILE")
+}
+
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// formatRate writes the rate as a decimal without trailing zeros
+func formatRate(rate *big.Rat) string {
+	value := rate.FloatString(10)
+	value = strings.TrimRight(value, "0")
+	return strings.TrimSuffix(value, ".")
+}
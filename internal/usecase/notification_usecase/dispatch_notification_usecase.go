@@ -0,0 +1,135 @@
+package notification_usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+	"go.uber.org/zap"
+)
+
+// NotifyOutbid warns the user that held the highest bid that a higher one was accepted
+func (nu *NotificationUseCase) NotifyOutbid(
+	ctx context.Context, outbidBid, newBid bid_entity.Bid) {
+	if outbidBid.UserId == newBid.UserId {
+		return
+	}
+
+	nu.dispatch(ctx, notification_entity.CreateNotification(
+		outbidBid.UserId,
+		outbidBid.AuctionId,
+		notification_entity.Outbid,
+		"You have been outbid",
+		fmt.Sprintf("Your bid of %s on auction %s was outbid by a bid of %s",
+			outbidBid.Amount.Display(), newBid.AuctionId, newBid.Amount.Display())))
+}
+
+// NotifyAuctionClosed tells the winner of a completed auction that they won it and
+// every other bidder and watcher that it has closed
+func (nu *NotificationUseCase) NotifyAuctionClosed(
+	ctx context.Context, auction auction_entity.Auction) {
+	winnerId := ""
+	winningBid, err := nu.BidRepository.FindWinningBidByAuctionId(ctx, auction.Id)
+	if err != nil {
+		logger.Info("Auction closed without a winner to notify", zap.String("auctionId", auction.Id))
+	} else {
+		winnerId = winningBid.UserId
+
+		nu.dispatch(ctx, notification_entity.CreateNotification(
+			winningBid.UserId,
+			auction.Id,
+			notification_entity.AuctionWon,
+			fmt.Sprintf("You won the auction for %s", auction.ProductName),
+			fmt.Sprintf("Your bid of %s was the highest on auction %s",
+				winningBid.Amount.Display(), auction.Id)))
+	}
+
+	for _, userId := range nu.findAuctionAudience(ctx, auction.Id) {
+		if userId == winnerId {
+			continue
+		}
+
+		nu.dispatch(ctx, notification_entity.CreateNotification(
+			userId,
+			auction.Id,
+			notification_entity.AuctionClosed,
+			fmt.Sprintf("The auction for %s has closed", auction.ProductName),
+			fmt.Sprintf("Auction %s is no longer accepting bids", auction.Id)))
+	}
+}
+
+// NotifyAuctionEndingSoon reminds every bidder and watcher that the auction is about to end
+func (nu *NotificationUseCase) NotifyAuctionEndingSoon(
+	ctx context.Context, auction auction_entity.Auction, window time.Duration) {
+	for _, userId := range nu.findAuctionAudience(ctx, auction.Id) {
+		nu.dispatch(ctx, notification_entity.CreateNotification(
+			userId,
+			auction.Id,
+			notification_entity.EndingSoon,
+			fmt.Sprintf("The auction for %s ends in less than %s", auction.ProductName, window),
+			fmt.Sprintf("Auction %s ends at %s", auction.Id, auction.EndTime.Format(time.RFC1123))))
+	}
+}
+
+// findAuctionAudience returns the distinct ids of the users that bid on or watch the auction
+func (nu *NotificationUseCase) findAuctionAudience(ctx context.Context, auctionId string) []string {
+	seen := make(map[string]bool)
+	var userIds []string
+
+	addUser := func(userId string) {
+		if !seen[userId] {
+			seen[userId] = true
+			userIds = append(userIds, userId)
+		}
+	}
+
+	bids, err := nu.BidRepository.FindRankedBidsByAuctionId(ctx, auctionId)
+	if err != nil {
+		logger.Error("Error trying to find the bidders of the auction", err)
+	}
+	for _, bid := range bids {
+		addUser(bid.UserId)
+	}
+
+	if nu.WatchlistRepository != nil {
+		watcherIds, err := nu.WatchlistRepository.FindWatcherIdsByAuctionId(ctx, auctionId)
+		if err != nil {
+			logger.Error("Error trying to find the watchers of the auction", err)
+		}
+		for _, watcherId := range watcherIds {
+			addUser(watcherId)
+		}
+	}
+
+	return userIds
+}
+
+func (nu *NotificationUseCase) dispatch(
+	ctx context.Context, notification notification_entity.Notification) {
+	preference, err := nu.findPreference(ctx, notification.UserId)
+	if err != nil {
+		logger.Error("Error trying to load notification preferences", err)
+		return
+	}
+
+	if !preference.Allows(notification.Kind) {
+		return
+	}
+
+	for _, channel := range preference.Channels {
+		notifier, ok := nu.notifiers[channel]
+		if !ok {
+			logger.Info("Notification channel not configured", zap.String("channel", string(channel)))
+			continue
+		}
+
+		if err := notifier.Notify(ctx, *preference, notification); err != nil {
+			logger.Error("Error trying to deliver notification", err,
+				zap.String("channel", string(channel)), zap.String("userId", notification.UserId))
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package notification_usecase
+
+import (
+	"context"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/notification_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/watchlist_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type PreferenceInputDTO struct {
+	Email         string   `json:"email"`
+	Channels      []string `json:"channels" binding:"dive,oneof=email log"`
+	OutbidAlerts  bool     `json:"outbid_alerts"`
+	WinnerAlerts  bool     `json:"winner_alerts"`
+	ClosingAlerts bool     `json:"closing_alerts"`
+}
+
+type PreferenceOutputDTO struct {
+	UserId        string   `json:"user_id"`
+	Email         string   `json:"email"`
+	Channels      []string `json:"channels"`
+	OutbidAlerts  bool     `json:"outbid_alerts"`
+	WinnerAlerts  bool     `json:"winner_alerts"`
+	ClosingAlerts bool     `json:"closing_alerts"`
+}
+
+type NotificationUseCase struct {
+	PreferenceRepository notification_entity.PreferenceRepositoryInterface
+	BidRepository        bid_entity.BidEntityRepository
+	WatchlistRepository  watchlist_entity.WatchlistRepositoryInterface
+
+	notifiers map[notification_entity.Channel]notification_entity.NotifierInterface
+}
+
+func NewNotificationUseCase(
+	preferenceRepository notification_entity.PreferenceRepositoryInterface,
+	bidRepository bid_entity.BidEntityRepository,
+	watchlistRepository watchlist_entity.WatchlistRepositoryInterface,
+	notifiers map[notification_entity.Channel]notification_entity.NotifierInterface) *NotificationUseCase {
+	return &NotificationUseCase{
+		PreferenceRepository: preferenceRepository,
+		BidRepository:        bidRepository,
+		WatchlistRepository:  watchlistRepository,
+		notifiers:            notifiers,
+	}
+}
+
+type NotificationUseCaseInterface interface {
+	FindPreferenceByUserId(
+		ctx context.Context, userId string) (*PreferenceOutputDTO, *internal_error.InternalError)
+
+	UpdatePreference(
+		ctx context.Context,
+		userId string,
+		preferenceInput PreferenceInputDTO) *internal_error.InternalError
+}
+
+func (nu *NotificationUseCase) FindPreferenceByUserId(
+	ctx context.Context, userId string) (*PreferenceOutputDTO, *internal_error.InternalError) {
+	preference, err := nu.findPreference(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]string, 0, len(preference.Channels))
+	for _, channel := range preference.Channels {
+		channels = append(channels, string(channel))
+	}
+
+	return &PreferenceOutputDTO{
+		UserId:        preference.UserId,
+		Email:         preference.Email,
+		Channels:      channels,
+		OutbidAlerts:  preference.OutbidAlerts,
+		WinnerAlerts:  preference.WinnerAlerts,
+		ClosingAlerts: preference.ClosingAlerts,
+	}, nil
+}
+
+func (nu *NotificationUseCase) UpdatePreference(
+	ctx context.Context,
+	userId string,
+	preferenceInput PreferenceInputDTO) *internal_error.InternalError {
+	channels := make([]notification_entity.Channel, 0, len(preferenceInput.Channels))
+	for _, channel := range preferenceInput.Channels {
+		channels = append(channels, notification_entity.Channel(channel))
+	}
+
+	preference := &notification_entity.Preference{
+		UserId:        userId,
+		Email:         preferenceInput.Email,
+		Channels:      channels,
+		OutbidAlerts:  preferenceInput.OutbidAlerts,
+		WinnerAlerts:  preferenceInput.WinnerAlerts,
+		ClosingAlerts: preferenceInput.ClosingAlerts,
+	}
+
+	if err := preference.Validate(); err != nil {
+		return err
+	}
+
+	return nu.PreferenceRepository.UpsertPreference(ctx, preference)
+}
+
+// findPreference returns the stored preferences of the user, falling back to the defaults
+func (nu *NotificationUseCase) findPreference(
+	ctx context.Context, userId string) (*notification_entity.Preference, *internal_error.InternalError) {
+	preference, err := nu.PreferenceRepository.FindPreferenceByUserId(ctx, userId)
+	if err != nil {
+		if err.Err == "not_found" {
+			return notification_entity.DefaultPreference(userId), nil
+		}
+
+		return nil, err
+	}
+
+	return preference, nil
+}
@@ -0,0 +1,30 @@
+package settlement_usecase
+
+import (
+	"os"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/fee_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// LoadFeeSchedule reads the fees charged on settled auctions. Without a file no fees are
+// charged
+func LoadFeeSchedule(path string) (*fee_entity.Schedule, *internal_error.InternalError) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error("Error trying to read fee schedule file", err)
+		return nil, internal_error.NewInternalServerError("Error trying to read fee schedule file")
+	}
+
+	return fee_entity.ParseSchedule(content)
+}
+
+// GetFeeScheduleFile returns the fee schedule loaded on startup
+func GetFeeScheduleFile() string {
+	return os.Getenv("FEE_SCHEDULE_FILE")
+}
@@ -0,0 +1,105 @@
+package settlement_usecase
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+	"go.uber.org/zap"
+)
+
+// StartPaymentDeadlineWorker marks unpaid orders as overdue once their due date passes and
+// defaults them after ORDER_DEFAULT_GRACE, offering the item to the next bidder. It also
+// expires the second-chance offers left unanswered and retries the settlements that failed
+func (su *SettlementUseCase) StartPaymentDeadlineWorker(ctx context.Context) {
+	logger.Info("Starting payment deadline worker")
+
+	checkInterval := 1 * time.Minute
+	if interval := os.Getenv("WORKER_CHECK_INTERVAL"); interval != "" {
+		if parsedInterval, err := time.ParseDuration(interval); err == nil {
+			checkInterval = parsedInterval
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Payment deadline worker stopped")
+			return
+		default:
+			su.checkPaymentDeadlines(ctx, time.Now())
+			su.checkExpiredOffers(ctx, time.Now())
+			su.settleUnsettledAuctions(ctx, time.Now().Add(-checkInterval))
+
+			time.Sleep(checkInterval)
+		}
+	}
+}
+
+// settleUnsettledAuctions settles again the auctions closed before the given time whose
+// settlement failed. Recently closed auctions are left to the instance closing them
+func (su *SettlementUseCase) settleUnsettledAuctions(ctx context.Context, closedBefore time.Time) {
+	auctions, err := su.AuctionRepository.FindUnsettledAuctions(ctx, closedBefore)
+	if err != nil {
+		return
+	}
+
+	for _, auction := range auctions {
+		if err := su.SettleAuction(ctx, auction.Id); err != nil {
+			logger.Error("Error retrying auction settlement", err, zap.String("auctionId", auction.Id))
+		}
+	}
+}
+
+func (su *SettlementUseCase) checkPaymentDeadlines(ctx context.Context, now time.Time) {
+	pendingOrders, err := su.OrderRepository.FindOrdersDueBefore(ctx, order_entity.Pending, now)
+	if err != nil {
+		return
+	}
+
+	for _, order := range pendingOrders {
+		if _, err := su.OrderRepository.UpdateOrderStatus(
+			ctx, order.Id, order_entity.Pending, order_entity.Overdue, now); err != nil {
+			logger.Error("Error marking order as overdue", err, zap.String("orderId", order.Id))
+		}
+	}
+
+	overdueOrders, err := su.OrderRepository.FindOrdersDueBefore(ctx, order_entity.Overdue, now.Add(-su.defaultGrace))
+	if err != nil {
+		return
+	}
+
+	for _, order := range overdueOrders {
+		defaulted, err := su.OrderRepository.UpdateOrderStatus(
+			ctx, order.Id, order_entity.Overdue, order_entity.Defaulted, now)
+		if err != nil {
+			logger.Error("Error defaulting order", err, zap.String("orderId", order.Id))
+			continue
+		}
+
+		// Only the instance that defaulted the order offers the item again
+		if !defaulted {
+			continue
+		}
+
+		logger.Info("Order defaulted", zap.String("orderId", order.Id))
+
+		su.refundPrepaid(ctx, order)
+		su.passOfferOn(ctx, order.AuctionId)
+	}
+}
+
+// refundPrepaid gives the buyer of a defaulted order back the amount settled from their wallet
+// when the auction closed, since they won't get the item. Nobody waits on the result, so
+// failures are only logged
+func (su *SettlementUseCase) refundPrepaid(ctx context.Context, order order_entity.Order) {
+	if !order.Prepaid.IsPositive() {
+		return
+	}
+
+	if _, err := su.WalletRepository.RefundSettledHold(ctx, order.BuyerId, order.AuctionId); err != nil {
+		logger.Error("Error refunding defaulted order", err, zap.String("orderId", order.Id))
+	}
+}
@@ -0,0 +1,296 @@
+package settlement_usecase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/offer_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+type SecondChanceOfferInputDTO struct {
+	SellerId string `json:"seller_id" binding:"required,uuid"`
+}
+
+type OfferResponseInputDTO struct {
+	UserId string `json:"user_id" binding:"required,uuid"`
+}
+
+type OfferOutputDTO struct {
+	Id          string                   `json:"id"`
+	AuctionId   string                   `json:"auction_id"`
+	BidId       string                   `json:"bid_id"`
+	UserId      string                   `json:"user_id"`
+	Amount      json.Number              `json:"amount"`
+	Currency    string                   `json:"currency"`
+	Status      offer_entity.OfferStatus `json:"status"`
+	ExpiresAt   time.Time                `json:"expires_at" time_format:"2006-01-02 15:04:05"`
+	CreatedAt   time.Time                `json:"created_at" time_format:"2006-01-02 15:04:05"`
+	RespondedAt *time.Time               `json:"responded_at,omitempty" time_format:"2006-01-02 15:04:05"`
+}
+
+func NewOfferOutputDTO(offer offer_entity.Offer) *OfferOutputDTO {
+	offerOutputDTO := &OfferOutputDTO{
+		Id:        offer.Id,
+		AuctionId: offer.AuctionId,
+		BidId:     offer.BidId,
+		UserId:    offer.UserId,
+		Amount:    json.Number(offer.Amount.String()),
+		Currency:  offer.Amount.Currency,
+		Status:    offer.Status,
+		ExpiresAt: offer.ExpiresAt,
+		CreatedAt: offer.CreatedAt,
+	}
+	if !offer.RespondedAt.IsZero() {
+		offerOutputDTO.RespondedAt = &offer.RespondedAt
+	}
+
+	return offerOutputDTO
+}
+
+// CreateSecondChanceOffer lets the seller offer the item to the best runner-up bidder when the
+// reserve price was not met or every buyer so far defaulted
+func (su *SettlementUseCase) CreateSecondChanceOffer(
+	ctx context.Context, auctionId, sellerId string) (*OfferOutputDTO, *internal_error.InternalError) {
+	auction, err := su.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction.Status != auction_entity.Completed {
+		return nil, internal_error.NewBadRequestError("Only closed auctions can have second-chance offers")
+	}
+
+	if auction.SellerId == "" || auction.SellerId != sellerId {
+		return nil, internal_error.NewBadRequestError("Only the seller of the auction can make second-chance offers")
+	}
+
+	orders, err := su.OrderRepository.FindOrdersByAuctionId(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		if order.Status != order_entity.Defaulted {
+			return nil, internal_error.NewBadRequestError("The item was already sold")
+		}
+	}
+
+	// The winner's order is still being created
+	if auction.WinnerUserId != "" && len(orders) == 0 {
+		return nil, internal_error.NewBadRequestError("The item was already sold")
+	}
+
+	offer, err := su.offerToNextBidder(ctx, *auction)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer == nil {
+		return nil, internal_error.NewNotFoundError("No other bidder to offer the item to")
+	}
+
+	return NewOfferOutputDTO(*offer), nil
+}
+
+// AcceptOffer sells the item to the user of the offer at the offered price
+func (su *SettlementUseCase) AcceptOffer(
+	ctx context.Context, offerId, userId string) (*OrderOutputDTO, *internal_error.InternalError) {
+	offer, err := su.respondToOffer(ctx, offerId, userId, offer_entity.Accepted)
+	if err != nil {
+		return nil, err
+	}
+
+	auction, err := su.AuctionRepository.FindAuctionById(ctx, offer.AuctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	offeredBid := bid_entity.Bid{
+		Id:        offer.BidId,
+		UserId:    offer.UserId,
+		AuctionId: offer.AuctionId,
+		Amount:    offer.Amount,
+	}
+
+	order, err := su.createOrder(ctx, *auction, offeredBid, money_entity.New(0, offer.Amount.Currency))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOrderOutputDTO(*order), nil
+}
+
+// DeclineOffer turns the offer down and passes it on to the next bidder
+func (su *SettlementUseCase) DeclineOffer(
+	ctx context.Context, offerId, userId string) (*OfferOutputDTO, *internal_error.InternalError) {
+	offer, err := su.respondToOffer(ctx, offerId, userId, offer_entity.Declined)
+	if err != nil {
+		return nil, err
+	}
+
+	su.passOfferOn(ctx, offer.AuctionId)
+
+	return NewOfferOutputDTO(*offer), nil
+}
+
+func (su *SettlementUseCase) FindOffersByUserId(
+	ctx context.Context, userId string) ([]OfferOutputDTO, *internal_error.InternalError) {
+	offers, err := su.OfferRepository.FindOffersByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	offerOutputs := make([]OfferOutputDTO, 0, len(offers))
+	for _, offer := range offers {
+		offerOutputs = append(offerOutputs, *NewOfferOutputDTO(offer))
+	}
+
+	return offerOutputs, nil
+}
+
+// respondToOffer moves an open offer of the user to the given status
+func (su *SettlementUseCase) respondToOffer(
+	ctx context.Context,
+	offerId, userId string,
+	status offer_entity.OfferStatus) (*offer_entity.Offer, *internal_error.InternalError) {
+	offer, err := su.OfferRepository.FindOfferById(ctx, offerId)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.UserId != userId {
+		return nil, internal_error.NewBadRequestError("Offer was made to another user")
+	}
+
+	now := time.Now()
+	if !offer.IsOpen(now) {
+		return nil, internal_error.NewBadRequestError("Offer is no longer open")
+	}
+
+	updated, err := su.OfferRepository.UpdateOfferStatus(ctx, offer.Id, offer_entity.Pending, status, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The offer was answered or expired in the meantime
+	if !updated {
+		return nil, internal_error.NewBadRequestError("Offer is no longer open")
+	}
+
+	offer.Status = status
+	offer.RespondedAt = now
+
+	return offer, nil
+}
+
+// checkExpiredOffers expires the offers nobody answered in time and passes them on
+func (su *SettlementUseCase) checkExpiredOffers(ctx context.Context, now time.Time) {
+	expiredOffers, err := su.OfferRepository.FindPendingOffersExpiredBefore(ctx, now)
+	if err != nil {
+		return
+	}
+
+	for _, offer := range expiredOffers {
+		expired, err := su.OfferRepository.UpdateOfferStatus(
+			ctx, offer.Id, offer_entity.Pending, offer_entity.Expired, now)
+		if err != nil {
+			logger.Error("Error expiring offer", err, zap.String("offerId", offer.Id))
+			continue
+		}
+
+		// Only the instance that expired the offer passes it on
+		if !expired {
+			continue
+		}
+
+		logger.Info("Offer expired", zap.String("offerId", offer.Id))
+
+		su.passOfferOn(ctx, offer.AuctionId)
+	}
+}
+
+// passOfferOn offers the item to the next bidder once a buyer defaulted or an offer was turned
+// down. Nobody waits on the result, so failures are only logged
+func (su *SettlementUseCase) passOfferOn(ctx context.Context, auctionId string) {
+	auction, err := su.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err == nil {
+		_, err = su.offerToNextBidder(ctx, *auction)
+	}
+
+	if err != nil {
+		logger.Error("Error offering item to the next bidder", err, zap.String("auctionId", auctionId))
+	}
+}
+
+// offerToNextBidder offers the item at their own bid price to the best ranked bidder that
+// didn't buy it nor get an offer yet. It returns nil when no bidder is left. Only one offer of
+// an auction is open at a time: the repository rejects a second pending offer, so concurrent
+// calls can't both open one
+func (su *SettlementUseCase) offerToNextBidder(
+	ctx context.Context, auction auction_entity.Auction) (*offer_entity.Offer, *internal_error.InternalError) {
+	orders, err := su.OrderRepository.FindOrdersByAuctionId(ctx, auction.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := su.OfferRepository.FindOffersByAuctionId(ctx, auction.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousUsers := make(map[string]bool, len(orders)+len(offers))
+	for _, order := range orders {
+		previousUsers[order.BuyerId] = true
+	}
+	for _, offer := range offers {
+		previousUsers[offer.UserId] = true
+	}
+
+	rankedBids, err := su.BidRepository.FindRankedBidsByAuctionId(ctx, auction.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bid := range rankedBids {
+		if previousUsers[bid.UserId] {
+			continue
+		}
+
+		offer, err := offer_entity.CreateOffer(bid, su.offerExpiry)
+		if err != nil {
+			return nil, err
+		}
+
+		offer, err = su.OfferRepository.CreateOffer(ctx, offer)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("Second-chance offer created",
+			zap.String("offerId", offer.Id), zap.String("auctionId", auction.Id))
+
+		return offer, nil
+	}
+
+	logger.Info("No other bidder to offer the item to", zap.String("auctionId", auction.Id))
+	return nil, nil
+}
+
+func getSecondChanceOfferExpiry() time.Duration {
+	offerExpiry := os.Getenv("SECOND_CHANCE_OFFER_EXPIRY")
+	duration, err := time.ParseDuration(offerExpiry)
+	if err != nil || duration <= 0 {
+		return 48 * time.Hour
+	}
+
+	return duration
+}
@@ -0,0 +1,368 @@
+package settlement_usecase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/configuration/logger"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/category_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/fee_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/offer_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+	"go.uber.org/zap"
+)
+
+// FeeBreakdownOutputDTO is what the seller receives and the buyer pays for the final price
+type FeeBreakdownOutputDTO struct {
+	FinalPrice   json.Number `json:"final_price"`
+	SellerFee    json.Number `json:"seller_fee"`
+	SellerPayout json.Number `json:"seller_payout"`
+	BuyerPremium json.Number `json:"buyer_premium"`
+	BuyerTotal   json.Number `json:"buyer_total"`
+	Currency     string      `json:"currency"`
+}
+
+func NewFeeBreakdownOutputDTO(order order_entity.Order) *FeeBreakdownOutputDTO {
+	return &FeeBreakdownOutputDTO{
+		FinalPrice:   json.Number(order.FinalPrice.String()),
+		SellerFee:    json.Number(order.SellerFee.String()),
+		SellerPayout: json.Number(order.SellerPayout().String()),
+		BuyerPremium: json.Number(order.BuyerPremium.String()),
+		BuyerTotal:   json.Number(order.Total().String()),
+		Currency:     order.FinalPrice.Currency,
+	}
+}
+
+type OrderOutputDTO struct {
+	Id               string                   `json:"id"`
+	AuctionId        string                   `json:"auction_id"`
+	BidId            string                   `json:"bid_id"`
+	BuyerId          string                   `json:"buyer_id"`
+	SellerId         string                   `json:"seller_id,omitempty"`
+	ProductName      string                   `json:"product_name"`
+	FinalPrice       json.Number              `json:"final_price"`
+	Fees             *FeeBreakdownOutputDTO   `json:"fees"`
+	Total            json.Number              `json:"total"`
+	Prepaid          json.Number              `json:"prepaid"`
+	AmountDue        json.Number              `json:"amount_due"`
+	Currency         string                   `json:"currency"`
+	Status           order_entity.OrderStatus `json:"status"`
+	PaymentReference string                   `json:"payment_reference,omitempty"`
+	DueAt            time.Time                `json:"due_at" time_format:"2006-01-02 15:04:05"`
+	CreatedAt        time.Time                `json:"created_at" time_format:"2006-01-02 15:04:05"`
+	PaidAt           *time.Time               `json:"paid_at,omitempty" time_format:"2006-01-02 15:04:05"`
+}
+
+func NewOrderOutputDTO(order order_entity.Order) *OrderOutputDTO {
+	orderOutputDTO := &OrderOutputDTO{
+		Id:               order.Id,
+		AuctionId:        order.AuctionId,
+		BidId:            order.BidId,
+		BuyerId:          order.BuyerId,
+		SellerId:         order.SellerId,
+		ProductName:      order.ProductName,
+		FinalPrice:       json.Number(order.FinalPrice.String()),
+		Fees:             NewFeeBreakdownOutputDTO(order),
+		Total:            json.Number(order.Total().String()),
+		Prepaid:          json.Number(order.Prepaid.String()),
+		AmountDue:        json.Number(order.AmountDue().String()),
+		Currency:         order.FinalPrice.Currency,
+		Status:           order.Status,
+		PaymentReference: order.PaymentReference,
+		DueAt:            order.DueAt,
+		CreatedAt:        order.CreatedAt,
+	}
+	if !order.PaidAt.IsZero() {
+		orderOutputDTO.PaidAt = &order.PaidAt
+	}
+
+	return orderOutputDTO
+}
+
+type SettlementUseCase struct {
+	OrderRepository   order_entity.OrderRepositoryInterface
+	AuctionRepository auction_entity.AuctionRepositoryInterface
+	BidRepository     bid_entity.BidEntityRepository
+	WalletRepository  wallet_entity.WalletRepositoryInterface
+	OfferRepository   offer_entity.OfferRepositoryInterface
+	PaymentProvider   order_entity.PaymentProviderInterface
+	// CategoryRepository resolves the ancestors of the auction category, whose fee rules apply
+	// to it. Only the category itself is looked up when nil
+	CategoryRepository category_entity.CategoryRepositoryInterface
+
+	paymentWindow time.Duration
+	defaultGrace  time.Duration
+	offerExpiry   time.Duration
+	feeSchedule   *fee_entity.Schedule
+}
+
+func NewSettlementUseCase(
+	orderRepository order_entity.OrderRepositoryInterface,
+	auctionRepository auction_entity.AuctionRepositoryInterface,
+	bidRepository bid_entity.BidEntityRepository,
+	walletRepository wallet_entity.WalletRepositoryInterface,
+	offerRepository offer_entity.OfferRepositoryInterface,
+	paymentProvider order_entity.PaymentProviderInterface,
+	categoryRepository category_entity.CategoryRepositoryInterface,
+	feeSchedule *fee_entity.Schedule) *SettlementUseCase {
+	return &SettlementUseCase{
+		OrderRepository:    orderRepository,
+		AuctionRepository:  auctionRepository,
+		BidRepository:      bidRepository,
+		WalletRepository:   walletRepository,
+		OfferRepository:    offerRepository,
+		PaymentProvider:    paymentProvider,
+		CategoryRepository: categoryRepository,
+		paymentWindow:      getOrderPaymentWindow(),
+		defaultGrace:       getOrderDefaultGrace(),
+		offerExpiry:        getSecondChanceOfferExpiry(),
+		feeSchedule:        feeSchedule,
+	}
+}
+
+type SettlementUseCaseInterface interface {
+	SettleAuction(
+		ctx context.Context, auctionId string) *internal_error.InternalError
+
+	FindOrderById(
+		ctx context.Context, orderId string) (*OrderOutputDTO, *internal_error.InternalError)
+
+	FindOrdersByBuyerId(
+		ctx context.Context, buyerId string) ([]OrderOutputDTO, *internal_error.InternalError)
+
+	ConfirmPayment(
+		ctx context.Context, orderId string) (*OrderOutputDTO, *internal_error.InternalError)
+
+	CreateSecondChanceOffer(
+		ctx context.Context, auctionId, sellerId string) (*OfferOutputDTO, *internal_error.InternalError)
+
+	AcceptOffer(
+		ctx context.Context, offerId, userId string) (*OrderOutputDTO, *internal_error.InternalError)
+
+	DeclineOffer(
+		ctx context.Context, offerId, userId string) (*OfferOutputDTO, *internal_error.InternalError)
+
+	FindOffersByUserId(
+		ctx context.Context, userId string) ([]OfferOutputDTO, *internal_error.InternalError)
+
+	StartPaymentDeadlineWorker(ctx context.Context)
+}
+
+// SettleAuction converts the funds held for the winner into the prepaid part of the winner's
+// order and releases the holds left by everyone else. The order is created before the hold is
+// settled, so settling the auction again after a failure finds either the hold still active or
+// the order already created with its prepaid amount. The auction is marked as settled last,
+// and the deadline worker retries the auctions left unsettled
+func (su *SettlementUseCase) SettleAuction(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	auction, err := su.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	if auction.Status != auction_entity.Completed {
+		return internal_error.NewBadRequestError("Only closed auctions can be settled")
+	}
+
+	if auction.WinnerUserId == "" {
+		if err := su.WalletRepository.ReleaseAuctionHolds(ctx, auctionId); err != nil {
+			return err
+		}
+
+		return su.AuctionRepository.MarkAuctionSettled(ctx, auctionId)
+	}
+
+	prepaid := money_entity.New(0, auction.FinalPrice.Currency)
+	hold, err := su.WalletRepository.FindHold(ctx, auction.WinnerUserId, auctionId)
+	if err != nil {
+		return err
+	}
+	if hold != nil {
+		prepaid = hold.Amount
+	}
+
+	winningBid := bid_entity.Bid{
+		Id:        auction.WinningBidId,
+		UserId:    auction.WinnerUserId,
+		AuctionId: auction.Id,
+		Amount:    auction.FinalPrice,
+	}
+
+	if _, err := su.createOrder(ctx, *auction, winningBid, prepaid); err != nil {
+		return err
+	}
+
+	if _, err := su.WalletRepository.SettleHold(ctx, auction.WinnerUserId, auctionId); err != nil {
+		return err
+	}
+
+	if err := su.WalletRepository.ReleaseAuctionHolds(ctx, auctionId); err != nil {
+		return err
+	}
+
+	return su.AuctionRepository.MarkAuctionSettled(ctx, auctionId)
+}
+
+// createOrder stores the order of the bid, with the fees of the auction category at the bid
+// amount, and registers its payment with the provider
+func (su *SettlementUseCase) createOrder(
+	ctx context.Context,
+	auction auction_entity.Auction,
+	bid bid_entity.Bid,
+	prepaid money_entity.Money) (*order_entity.Order, *internal_error.InternalError) {
+	categoryPath, err := su.findCategoryPath(ctx, auction.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	fees := su.feeSchedule.Calculate(categoryPath, bid.Amount)
+	order, err := order_entity.CreateOrder(auction, bid, fees, prepaid, su.paymentWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err = su.OrderRepository.CreateOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != order_entity.Pending || order.PaymentReference != "" {
+		return order, nil
+	}
+
+	paymentReference, err := su.PaymentProvider.CreatePayment(ctx, *order)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := su.OrderRepository.UpdatePaymentReference(ctx, order.Id, paymentReference); err != nil {
+		return nil, err
+	}
+	order.PaymentReference = paymentReference
+
+	logger.Info("Order created", zap.String("orderId", order.Id), zap.String("auctionId", auction.Id))
+
+	return order, nil
+}
+
+// findCategoryPath returns the category followed by its ancestors, nearest first. Categories
+// outside the category tree are returned on their own
+func (su *SettlementUseCase) findCategoryPath(
+	ctx context.Context, category string) ([]string, *internal_error.InternalError) {
+	if su.CategoryRepository == nil {
+		return []string{category}, nil
+	}
+
+	path, err := su.CategoryRepository.FindCategoryPath(ctx, category)
+	if err != nil {
+		if err.Err == "not_found" {
+			return []string{category}, nil
+		}
+		return nil, err
+	}
+
+	categoryPath := make([]string, 0, len(path))
+	for i := len(path) - 1; i >= 0; i-- {
+		categoryPath = append(categoryPath, path[i].Id)
+	}
+
+	return categoryPath, nil
+}
+
+func (su *SettlementUseCase) FindOrderById(
+	ctx context.Context, orderId string) (*OrderOutputDTO, *internal_error.InternalError) {
+	order, err := su.OrderRepository.FindOrderById(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOrderOutputDTO(*order), nil
+}
+
+func (su *SettlementUseCase) FindOrdersByBuyerId(
+	ctx context.Context, buyerId string) ([]OrderOutputDTO, *internal_error.InternalError) {
+	orders, err := su.OrderRepository.FindOrdersByBuyerId(ctx, buyerId)
+	if err != nil {
+		return nil, err
+	}
+
+	orderOutputs := make([]OrderOutputDTO, 0, len(orders))
+	for _, order := range orders {
+		orderOutputs = append(orderOutputs, *NewOrderOutputDTO(order))
+	}
+
+	return orderOutputs, nil
+}
+
+// ConfirmPayment asks the payment provider whether the order was paid and records it. Orders
+// whose payment is still pending are returned unchanged
+func (su *SettlementUseCase) ConfirmPayment(
+	ctx context.Context, orderId string) (*OrderOutputDTO, *internal_error.InternalError) {
+	order, err := su.OrderRepository.FindOrderById(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status == order_entity.Paid {
+		return NewOrderOutputDTO(*order), nil
+	}
+
+	if !order.Status.CanTransitionTo(order_entity.Paid) {
+		return nil, internal_error.NewBadRequestError("Order can no longer be paid")
+	}
+
+	paymentStatus, err := su.PaymentProvider.ConfirmPayment(ctx, order.PaymentReference)
+	if err != nil {
+		return nil, err
+	}
+
+	switch paymentStatus {
+	case order_entity.PaymentFailed:
+		return nil, internal_error.NewBadRequestError("Payment was declined")
+	case order_entity.PaymentPending:
+		return NewOrderOutputDTO(*order), nil
+	}
+
+	paidAt := time.Now()
+	updated, err := su.OrderRepository.UpdateOrderStatus(ctx, order.Id, order.Status, order_entity.Paid, paidAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// The deadline worker defaulted the order in the meantime
+	if !updated {
+		return nil, internal_error.NewBadRequestError("Order can no longer be paid")
+	}
+
+	order.Status = order_entity.Paid
+	order.PaidAt = paidAt
+
+	return NewOrderOutputDTO(*order), nil
+}
+
+func getOrderPaymentWindow() time.Duration {
+	paymentWindow := os.Getenv("ORDER_PAYMENT_WINDOW")
+	duration, err := time.ParseDuration(paymentWindow)
+	if err != nil || duration <= 0 {
+		return 72 * time.Hour
+	}
+
+	return duration
+}
+
+func getOrderDefaultGrace() time.Duration {
+	defaultGrace := os.Getenv("ORDER_DEFAULT_GRACE")
+	duration, err := time.ParseDuration(defaultGrace)
+	if err != nil || duration < 0 {
+		return 24 * time.Hour
+	}
+
+	return duration
+}
@@ -0,0 +1,592 @@
+package settlement_usecase
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/bid_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/fee_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/offer_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/order_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/infra/payment"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type fakeOrderRepository struct {
+	mutex  sync.Mutex
+	orders map[string]order_entity.Order
+}
+
+func (fr *fakeOrderRepository) CreateOrder(
+	ctx context.Context, order *order_entity.Order) (*order_entity.Order, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	for _, stored := range fr.orders {
+		if stored.BidId == order.BidId {
+			return &stored, nil
+		}
+	}
+	fr.orders[order.Id] = *order
+	return order, nil
+}
+
+func (fr *fakeOrderRepository) FindOrderById(
+	ctx context.Context, id string) (*order_entity.Order, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	order, ok := fr.orders[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Order not found")
+	}
+	return &order, nil
+}
+
+func (fr *fakeOrderRepository) FindOrdersByBuyerId(
+	ctx context.Context, buyerId string) ([]order_entity.Order, *internal_error.InternalError) {
+	return fr.filter(func(order order_entity.Order) bool { return order.BuyerId == buyerId }), nil
+}
+
+func (fr *fakeOrderRepository) FindOrdersByAuctionId(
+	ctx context.Context, auctionId string) ([]order_entity.Order, *internal_error.InternalError) {
+	return fr.filter(func(order order_entity.Order) bool { return order.AuctionId == auctionId }), nil
+}
+
+func (fr *fakeOrderRepository) FindOrdersDueBefore(
+	ctx context.Context,
+	status order_entity.OrderStatus,
+	before time.Time) ([]order_entity.Order, *internal_error.InternalError) {
+	return fr.filter(func(order order_entity.Order) bool {
+		return order.Status == status && order.DueAt.Before(before)
+	}), nil
+}
+
+func (fr *fakeOrderRepository) UpdateOrderStatus(
+	ctx context.Context,
+	id string,
+	from, to order_entity.OrderStatus,
+	at time.Time) (bool, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	order, ok := fr.orders[id]
+	if !ok || order.Status != from {
+		return false, nil
+	}
+	order.Status = to
+	if to == order_entity.Paid {
+		order.PaidAt = at
+	}
+	fr.orders[id] = order
+	return true, nil
+}
+
+func (fr *fakeOrderRepository) UpdatePaymentReference(
+	ctx context.Context, id, paymentReference string) *internal_error.InternalError {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	order := fr.orders[id]
+	order.PaymentReference = paymentReference
+	fr.orders[id] = order
+	return nil
+}
+
+func (fr *fakeOrderRepository) filter(match func(order_entity.Order) bool) []order_entity.Order {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	var orders []order_entity.Order
+	for _, order := range fr.orders {
+		if match(order) {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+type fakeAuctionRepository struct {
+	auction_entity.AuctionRepositoryInterface
+	auction auction_entity.Auction
+	settled bool
+}
+
+func (fr *fakeAuctionRepository) FindAuctionById(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	auction := fr.auction
+	return &auction, nil
+}
+
+func (fr *fakeAuctionRepository) FindUnsettledAuctions(
+	ctx context.Context, closedBefore time.Time) ([]auction_entity.Auction, *internal_error.InternalError) {
+	if fr.settled || fr.auction.Status != auction_entity.Completed {
+		return nil, nil
+	}
+	return []auction_entity.Auction{fr.auction}, nil
+}
+
+func (fr *fakeAuctionRepository) MarkAuctionSettled(
+	ctx context.Context, id string) *internal_error.InternalError {
+	fr.settled = true
+	return nil
+}
+
+type fakeBidRepository struct {
+	bid_entity.BidEntityRepository
+	bids []bid_entity.Bid
+}
+
+func (fr *fakeBidRepository) FindRankedBidsByAuctionId(
+	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	bids := append([]bid_entity.Bid(nil), fr.bids...)
+	sort.Slice(bids, func(i, j int) bool {
+		return bids[i].Outranks(bids[j])
+	})
+
+	rankedUsers := make(map[string]bool)
+	var rankedBids []bid_entity.Bid
+	for _, bid := range bids {
+		if !rankedUsers[bid.UserId] {
+			rankedUsers[bid.UserId] = true
+			rankedBids = append(rankedBids, bid)
+		}
+	}
+	return rankedBids, nil
+}
+
+type fakeOfferRepository struct {
+	mutex  sync.Mutex
+	offers map[string]offer_entity.Offer
+}
+
+func (fr *fakeOfferRepository) CreateOffer(
+	ctx context.Context, offer *offer_entity.Offer) (*offer_entity.Offer, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	for _, stored := range fr.offers {
+		if stored.BidId == offer.BidId {
+			return &stored, nil
+		}
+	}
+	for _, stored := range fr.offers {
+		if stored.AuctionId == offer.AuctionId && stored.Status == offer_entity.Pending {
+			return nil, internal_error.NewBadRequestError("The item is already offered to another bidder")
+		}
+	}
+	fr.offers[offer.Id] = *offer
+	return offer, nil
+}
+
+func (fr *fakeOfferRepository) FindOfferById(
+	ctx context.Context, id string) (*offer_entity.Offer, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	offer, ok := fr.offers[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Offer not found")
+	}
+	return &offer, nil
+}
+
+func (fr *fakeOfferRepository) FindOffersByAuctionId(
+	ctx context.Context, auctionId string) ([]offer_entity.Offer, *internal_error.InternalError) {
+	return fr.filter(func(offer offer_entity.Offer) bool { return offer.AuctionId == auctionId }), nil
+}
+
+func (fr *fakeOfferRepository) FindOffersByUserId(
+	ctx context.Context, userId string) ([]offer_entity.Offer, *internal_error.InternalError) {
+	return fr.filter(func(offer offer_entity.Offer) bool { return offer.UserId == userId }), nil
+}
+
+func (fr *fakeOfferRepository) FindPendingOffersExpiredBefore(
+	ctx context.Context, before time.Time) ([]offer_entity.Offer, *internal_error.InternalError) {
+	return fr.filter(func(offer offer_entity.Offer) bool {
+		return offer.Status == offer_entity.Pending && offer.ExpiresAt.Before(before)
+	}), nil
+}
+
+func (fr *fakeOfferRepository) UpdateOfferStatus(
+	ctx context.Context,
+	id string,
+	from, to offer_entity.OfferStatus,
+	at time.Time) (bool, *internal_error.InternalError) {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	offer, ok := fr.offers[id]
+	if !ok || offer.Status != from {
+		return false, nil
+	}
+	offer.Status = to
+	offer.RespondedAt = at
+	fr.offers[id] = offer
+	return true, nil
+}
+
+func (fr *fakeOfferRepository) filter(match func(offer_entity.Offer) bool) []offer_entity.Offer {
+	fr.mutex.Lock()
+	defer fr.mutex.Unlock()
+
+	var offers []offer_entity.Offer
+	for _, offer := range fr.offers {
+		if match(offer) {
+			offers = append(offers, offer)
+		}
+	}
+	return offers
+}
+
+type fakeWalletRepository struct {
+	wallet_entity.WalletRepositoryInterface
+	hold *wallet_entity.Hold
+	// settled is the hold left settled by SettleHold until it is refunded
+	settled *wallet_entity.Hold
+	// refunds are the amounts given back by RefundSettledHold
+	refunds []money_entity.Money
+	// settleFailures is how many times SettleHold fails before it succeeds
+	settleFailures int
+}
+
+func (fr *fakeWalletRepository) FindHold(
+	ctx context.Context, userId, auctionId string) (*wallet_entity.Hold, *internal_error.InternalError) {
+	return fr.hold, nil
+}
+
+func (fr *fakeWalletRepository) SettleHold(
+	ctx context.Context, userId, auctionId string) (*wallet_entity.Hold, *internal_error.InternalError) {
+	if fr.settleFailures > 0 {
+		fr.settleFailures--
+		return nil, internal_error.NewInternalServerError("falha simulada")
+	}
+
+	hold := fr.hold
+	fr.hold = nil
+	fr.settled = hold
+	return hold, nil
+}
+
+func (fr *fakeWalletRepository) RefundSettledHold(
+	ctx context.Context, userId, auctionId string) (*wallet_entity.Hold, *internal_error.InternalError) {
+	hold := fr.settled
+	if hold == nil || hold.UserId != userId || hold.AuctionId != auctionId {
+		return nil, nil
+	}
+
+	fr.settled = nil
+	fr.refunds = append(fr.refunds, hold.Amount)
+	return hold, nil
+}
+
+func (fr *fakeWalletRepository) ReleaseAuctionHolds(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	return nil
+}
+
+func newClosedAuction(bids ...bid_entity.Bid) auction_entity.Auction {
+	return auction_entity.Auction{
+		Id:           bids[0].AuctionId,
+		ProductName:  "Produto Teste",
+		Currency:     "BRL",
+		SellerId:     uuid.New().String(),
+		Status:       auction_entity.Completed,
+		WinningBidId: bids[0].Id,
+		WinnerUserId: bids[0].UserId,
+		FinalPrice:   bids[0].Amount,
+	}
+}
+
+func newBid(auctionId string, amount int64) bid_entity.Bid {
+	return bid_entity.Bid{
+		Id:        uuid.New().String(),
+		UserId:    uuid.New().String(),
+		AuctionId: auctionId,
+		Amount:    money_entity.New(amount, "BRL"),
+		Timestamp: time.Now(),
+	}
+}
+
+func newTestFeeSchedule(t *testing.T) *fee_entity.Schedule {
+	feeSchedule, err := fee_entity.ParseSchedule(
+		[]byte(`{"seller_fee": [{"percent": 10}], "buyer_premium": [{"percent": 5}]}`))
+	if err != nil {
+		t.Fatalf("Erro ao ler tabela de taxas: %v", err)
+	}
+
+	return feeSchedule
+}
+
+func newTestSettlementUseCase(
+	t *testing.T,
+	auction auction_entity.Auction,
+	bids []bid_entity.Bid,
+	hold *wallet_entity.Hold,
+	paymentProvider order_entity.PaymentProviderInterface) (*SettlementUseCase, *fakeOrderRepository) {
+	orderRepository := &fakeOrderRepository{orders: make(map[string]order_entity.Order)}
+
+	return &SettlementUseCase{
+		OrderRepository:   orderRepository,
+		AuctionRepository: &fakeAuctionRepository{auction: auction},
+		BidRepository:     &fakeBidRepository{bids: bids},
+		WalletRepository:  &fakeWalletRepository{hold: hold},
+		OfferRepository:   &fakeOfferRepository{offers: make(map[string]offer_entity.Offer)},
+		PaymentProvider:   paymentProvider,
+		paymentWindow:     time.Hour,
+		defaultGrace:      time.Hour,
+		offerExpiry:       time.Hour,
+		feeSchedule:       newTestFeeSchedule(t),
+	}, orderRepository
+}
+
+// TestSettleAuctionCreatesOrder tests that the winner gets a single order, with the funds held
+// for the winning bid as prepaid, the buyer premium left to pay and the seller fee deducted
+func TestSettleAuctionCreatesOrder(t *testing.T) {
+	auctionId := uuid.New().String()
+	winningBid := newBid(auctionId, 10000)
+	auction := newClosedAuction(winningBid)
+	hold := &wallet_entity.Hold{UserId: winningBid.UserId, AuctionId: auctionId, Amount: winningBid.Amount}
+
+	settlementUseCase, orderRepository := newTestSettlementUseCase(
+		t, auction, []bid_entity.Bid{winningBid}, hold, payment.NewFakePaymentProvider(order_entity.PaymentPending))
+
+	for i := 0; i < 2; i++ {
+		if err := settlementUseCase.SettleAuction(context.Background(), auctionId); err != nil {
+			t.Fatalf("Erro ao liquidar leilão: %v", err)
+		}
+	}
+
+	orders, _ := orderRepository.FindOrdersByAuctionId(context.Background(), auctionId)
+	if len(orders) != 1 {
+		t.Fatalf("Esperado 1 pedido, encontrado %d", len(orders))
+	}
+
+	order := orders[0]
+	if order.BuyerId != winningBid.UserId || order.SellerId != auction.SellerId {
+		t.Error("Pedido deveria ser do vencedor para o vendedor do leilão")
+	}
+	if order.BuyerPremium != money_entity.New(500, "BRL") || order.AmountDue() != money_entity.New(500, "BRL") {
+		t.Errorf("Esperado prêmio do comprador e valor a pagar de 5.00 BRL, recebido %s e %s",
+			order.BuyerPremium.Display(), order.AmountDue().Display())
+	}
+	if order.SellerFee != money_entity.New(1000, "BRL") || order.SellerPayout() != money_entity.New(9000, "BRL") {
+		t.Errorf("Esperado taxa do vendedor de 10.00 BRL e repasse de 90.00 BRL, recebido %s e %s",
+			order.SellerFee.Display(), order.SellerPayout().Display())
+	}
+	if order.Status != order_entity.Pending || order.PaymentReference == "" {
+		t.Errorf("Esperado pedido pendente com pagamento registrado, recebido %s", order.Status)
+	}
+}
+
+// TestSettleAuctionRetriesFailedSettlement tests that a settlement that failed after the order
+// was created is retried by the deadline worker without losing the prepaid amount
+func TestSettleAuctionRetriesFailedSettlement(t *testing.T) {
+	auctionId := uuid.New().String()
+	winningBid := newBid(auctionId, 10000)
+	hold := &wallet_entity.Hold{UserId: winningBid.UserId, AuctionId: auctionId, Amount: winningBid.Amount}
+
+	settlementUseCase, orderRepository := newTestSettlementUseCase(
+		t, newClosedAuction(winningBid), []bid_entity.Bid{winningBid}, hold,
+		payment.NewFakePaymentProvider(order_entity.PaymentPending))
+	walletRepository := settlementUseCase.WalletRepository.(*fakeWalletRepository)
+	auctionRepository := settlementUseCase.AuctionRepository.(*fakeAuctionRepository)
+	walletRepository.settleFailures = 1
+
+	if err := settlementUseCase.SettleAuction(context.Background(), auctionId); err == nil {
+		t.Fatal("Liquidação deveria falhar ao liquidar a reserva")
+	}
+	if auctionRepository.settled {
+		t.Fatal("Leilão não deveria ser marcado como liquidado após a falha")
+	}
+
+	settlementUseCase.settleUnsettledAuctions(context.Background(), time.Now())
+
+	if !auctionRepository.settled || walletRepository.hold != nil {
+		t.Fatal("A nova tentativa deveria liquidar a reserva e marcar o leilão como liquidado")
+	}
+
+	orders, _ := orderRepository.FindOrdersByAuctionId(context.Background(), auctionId)
+	if len(orders) != 1 || orders[0].Prepaid != winningBid.Amount {
+		t.Fatalf("Esperado 1 pedido com o valor reservado como pré-pago, encontrados %d", len(orders))
+	}
+}
+
+// TestConfirmPayment tests that only payments confirmed by the provider mark the order as paid
+func TestConfirmPayment(t *testing.T) {
+	auctionId := uuid.New().String()
+	winningBid := newBid(auctionId, 10000)
+	paymentProvider := payment.NewFakePaymentProvider(order_entity.PaymentPending)
+
+	settlementUseCase, orderRepository := newTestSettlementUseCase(
+		t, newClosedAuction(winningBid), []bid_entity.Bid{winningBid}, nil, paymentProvider)
+	if err := settlementUseCase.SettleAuction(context.Background(), auctionId); err != nil {
+		t.Fatalf("Erro ao liquidar leilão: %v", err)
+	}
+	orders, _ := orderRepository.FindOrdersByAuctionId(context.Background(), auctionId)
+	order := orders[0]
+
+	orderOutput, err := settlementUseCase.ConfirmPayment(context.Background(), order.Id)
+	if err != nil || orderOutput.Status != order_entity.Pending {
+		t.Fatalf("Pagamento pendente não deveria alterar o pedido: %v", err)
+	}
+
+	paymentProvider.SetPaymentStatus(order.PaymentReference, order_entity.PaymentFailed)
+	if _, err := settlementUseCase.ConfirmPayment(context.Background(), order.Id); err == nil {
+		t.Error("Pagamento recusado deveria retornar erro")
+	}
+
+	paymentProvider.SetPaymentStatus(order.PaymentReference, order_entity.PaymentConfirmed)
+	orderOutput, err = settlementUseCase.ConfirmPayment(context.Background(), order.Id)
+	if err != nil || orderOutput.Status != order_entity.Paid || orderOutput.PaidAt == nil {
+		t.Errorf("Pagamento confirmado deveria marcar o pedido como pago: %v", err)
+	}
+}
+
+// TestDefaultOffersItemToNextBidder tests that an unpaid order becomes overdue, then defaulted,
+// and that the second-highest bidder is then offered the item at their own bid price
+func TestDefaultOffersItemToNextBidder(t *testing.T) {
+	auctionId := uuid.New().String()
+	winningBid := newBid(auctionId, 10000)
+	secondBid := newBid(auctionId, 9000)
+	lowestBid := newBid(auctionId, 8000)
+	// The winner also placed a lower bid, which must not get the item again
+	winnerLowerBid := newBid(auctionId, 9500)
+	winnerLowerBid.UserId = winningBid.UserId
+
+	settlementUseCase, orderRepository := newTestSettlementUseCase(
+		t, newClosedAuction(winningBid),
+		[]bid_entity.Bid{lowestBid, winnerLowerBid, winningBid, secondBid},
+		nil,
+		payment.NewFakePaymentProvider(order_entity.PaymentPending))
+	if err := settlementUseCase.SettleAuction(context.Background(), auctionId); err != nil {
+		t.Fatalf("Erro ao liquidar leilão: %v", err)
+	}
+
+	now := time.Now()
+	settlementUseCase.checkPaymentDeadlines(context.Background(), now.Add(90*time.Minute))
+	orders, _ := orderRepository.FindOrdersByAuctionId(context.Background(), auctionId)
+	if len(orders) != 1 || orders[0].Status != order_entity.Overdue {
+		t.Fatalf("Esperado pedido vencido após o prazo de pagamento")
+	}
+
+	settlementUseCase.checkPaymentDeadlines(context.Background(), now.Add(3*time.Hour))
+	orders, _ = orderRepository.FindOrdersByAuctionId(context.Background(), auctionId)
+	if len(orders) != 1 || orders[0].Status != order_entity.Defaulted {
+		t.Fatalf("Esperado pedido do vencedor inadimplente")
+	}
+
+	offers, _ := settlementUseCase.FindOffersByUserId(context.Background(), secondBid.UserId)
+	if len(offers) != 1 || offers[0].Status != offer_entity.Pending || offers[0].Amount != "90.00" {
+		t.Fatalf("Esperado oferta pendente de 90.00 para o segundo colocado, recebido %+v", offers)
+	}
+
+	if _, err := settlementUseCase.AcceptOffer(context.Background(), offers[0].Id, lowestBid.UserId); err == nil {
+		t.Error("Oferta não deveria ser aceita por outro usuário")
+	}
+
+	orderOutput, err := settlementUseCase.AcceptOffer(context.Background(), offers[0].Id, secondBid.UserId)
+	if err != nil {
+		t.Fatalf("Erro ao aceitar oferta: %v", err)
+	}
+	if orderOutput.BuyerId != secondBid.UserId || orderOutput.Status != order_entity.Pending ||
+		orderOutput.FinalPrice != "90.00" {
+		t.Errorf("Esperado pedido pendente de 90.00 para o segundo colocado, recebido %s de %s",
+			orderOutput.Status, orderOutput.FinalPrice)
+	}
+}
+
+// TestDefaultRefundsPrepaidAmount tests that the amount settled from the winner's wallet is
+// given back once when their order defaults
+func TestDefaultRefundsPrepaidAmount(t *testing.T) {
+	auctionId := uuid.New().String()
+	winningBid := newBid(auctionId, 10000)
+	hold := &wallet_entity.Hold{UserId: winningBid.UserId, AuctionId: auctionId, Amount: winningBid.Amount}
+
+	settlementUseCase, orderRepository := newTestSettlementUseCase(
+		t, newClosedAuction(winningBid), []bid_entity.Bid{winningBid}, hold,
+		payment.NewFakePaymentProvider(order_entity.PaymentPending))
+	walletRepository := settlementUseCase.WalletRepository.(*fakeWalletRepository)
+	if err := settlementUseCase.SettleAuction(context.Background(), auctionId); err != nil {
+		t.Fatalf("Erro ao liquidar leilão: %v", err)
+	}
+
+	now := time.Now()
+	settlementUseCase.checkPaymentDeadlines(context.Background(), now.Add(90*time.Minute))
+	if len(walletRepository.refunds) != 0 {
+		t.Fatal("Pedido vencido não deveria devolver o valor pré-pago")
+	}
+
+	settlementUseCase.checkPaymentDeadlines(context.Background(), now.Add(3*time.Hour))
+	settlementUseCase.checkPaymentDeadlines(context.Background(), now.Add(4*time.Hour))
+	orders, _ := orderRepository.FindOrdersByAuctionId(context.Background(), auctionId)
+	if len(orders) != 1 || orders[0].Status != order_entity.Defaulted {
+		t.Fatalf("Esperado pedido do vencedor inadimplente")
+	}
+
+	if len(walletRepository.refunds) != 1 || walletRepository.refunds[0] != winningBid.Amount {
+		t.Errorf("Esperada uma devolução de 100.00 BRL ao inadimplente, recebido %v", walletRepository.refunds)
+	}
+}
+
+// TestSecondChanceOfferWhenReserveNotMet tests that the seller can offer the item to the best
+// bidder when the reserve price was not met, and that declined or expired offers move on to the
+// next bidder
+func TestSecondChanceOfferWhenReserveNotMet(t *testing.T) {
+	auctionId := uuid.New().String()
+	highestBid := newBid(auctionId, 9000)
+	secondBid := newBid(auctionId, 8000)
+	thirdBid := newBid(auctionId, 7000)
+
+	auction := newClosedAuction(highestBid)
+	auction.ReservePrice = money_entity.New(10000, "BRL")
+	auction.HighBidAmount = highestBid.Amount
+	auction.WinningBidId, auction.WinnerUserId, auction.FinalPrice = "", "", money_entity.Money{}
+
+	settlementUseCase, _ := newTestSettlementUseCase(
+		t, auction, []bid_entity.Bid{thirdBid, highestBid, secondBid}, nil,
+		payment.NewFakePaymentProvider(order_entity.PaymentPending))
+
+	if _, err := settlementUseCase.CreateSecondChanceOffer(
+		context.Background(), auctionId, uuid.New().String()); err == nil {
+		t.Error("Somente o vendedor deveria poder fazer ofertas")
+	}
+
+	offer, err := settlementUseCase.CreateSecondChanceOffer(context.Background(), auctionId, auction.SellerId)
+	if err != nil || offer.UserId != highestBid.UserId {
+		t.Fatalf("Esperado oferta para o maior lance: %v", err)
+	}
+
+	if _, err := settlementUseCase.CreateSecondChanceOffer(
+		context.Background(), auctionId, auction.SellerId); err == nil {
+		t.Error("Somente uma oferta deveria ficar aberta por vez")
+	}
+
+	if _, err := settlementUseCase.DeclineOffer(context.Background(), offer.Id, highestBid.UserId); err != nil {
+		t.Fatalf("Erro ao recusar oferta: %v", err)
+	}
+
+	offers, _ := settlementUseCase.FindOffersByUserId(context.Background(), secondBid.UserId)
+	if len(offers) != 1 || offers[0].Status != offer_entity.Pending {
+		t.Fatalf("Oferta recusada deveria passar ao segundo colocado")
+	}
+
+	settlementUseCase.checkExpiredOffers(context.Background(), time.Now().Add(2*time.Hour))
+
+	offers, _ = settlementUseCase.FindOffersByUserId(context.Background(), secondBid.UserId)
+	if len(offers) != 1 || offers[0].Status != offer_entity.Expired {
+		t.Errorf("Oferta sem resposta deveria expirar")
+	}
+
+	offers, _ = settlementUseCase.FindOffersByUserId(context.Background(), thirdBid.UserId)
+	if len(offers) != 1 || offers[0].Status != offer_entity.Pending {
+		t.Errorf("Oferta expirada deveria passar ao terceiro colocado")
+	}
+}
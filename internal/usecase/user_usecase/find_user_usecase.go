@@ -18,8 +18,9 @@ type UserUseCase struct {
 }
 
 type UserOutputDTO struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id   string               `json:"id"`
+	Name string               `json:"name"`
+	Role user_entity.UserRole `json:"role"`
 }
 
 type UserUseCaseInterface interface {
@@ -38,5 +39,6 @@ func (u *UserUseCase) FindUserById(
 	return &UserOutputDTO{
 		Id:   userEntity.Id,
 		Name: userEntity.Name,
+		Role: userEntity.Role,
 	}, nil
 }
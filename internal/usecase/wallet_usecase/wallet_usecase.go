@@ -0,0 +1,131 @@
+package wallet_usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/money_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/user_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/wallet_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+// AmountInputDTO takes the amount as a JSON number, read as an exact decimal, e.g. 100.00
+type AmountInputDTO struct {
+	Amount   json.Number `json:"amount" binding:"required"`
+	Currency string      `json:"currency" binding:"required"`
+}
+
+type WalletOutputDTO struct {
+	UserId      string      `json:"user_id"`
+	Currency    string      `json:"currency"`
+	Balance     json.Number `json:"balance"`
+	CreditLimit json.Number `json:"credit_limit"`
+	Held        json.Number `json:"held"`
+	Available   json.Number `json:"available"`
+	UpdatedAt   time.Time   `json:"updated_at" time_format:"2006-01-02 15:04:05"`
+}
+
+type WalletUseCase struct {
+	walletRepository wallet_entity.WalletRepositoryInterface
+	userRepository   user_entity.UserRepositoryInterface
+}
+
+func NewWalletUseCase(
+	walletRepository wallet_entity.WalletRepositoryInterface,
+	userRepository user_entity.UserRepositoryInterface) WalletUseCaseInterface {
+	return &WalletUseCase{
+		walletRepository: walletRepository,
+		userRepository:   userRepository,
+	}
+}
+
+type WalletUseCaseInterface interface {
+	Deposit(
+		ctx context.Context,
+		userId string,
+		depositInput AmountInputDTO) *internal_error.InternalError
+
+	UpdateCreditLimit(
+		ctx context.Context,
+		userId string,
+		creditLimitInput AmountInputDTO) *internal_error.InternalError
+
+	FindWallets(
+		ctx context.Context, userId string) ([]WalletOutputDTO, *internal_error.InternalError)
+}
+
+func (wu *WalletUseCase) Deposit(
+	ctx context.Context,
+	userId string,
+	depositInput AmountInputDTO) *internal_error.InternalError {
+	amount, err := parseAmount(depositInput)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := wallet_entity.CreateDeposit(userId, amount)
+	if err != nil {
+		return err
+	}
+
+	if _, err := wu.userRepository.FindUserById(ctx, userId); err != nil {
+		return err
+	}
+
+	return wu.walletRepository.Deposit(ctx, *transaction)
+}
+
+func (wu *WalletUseCase) UpdateCreditLimit(
+	ctx context.Context,
+	userId string,
+	creditLimitInput AmountInputDTO) *internal_error.InternalError {
+	creditLimit, err := parseAmount(creditLimitInput)
+	if err != nil {
+		return err
+	}
+
+	if creditLimit.Amount < 0 {
+		return internal_error.NewBadRequestError("Credit limit can't be negative")
+	}
+
+	if _, err := wu.userRepository.FindUserById(ctx, userId); err != nil {
+		return err
+	}
+
+	return wu.walletRepository.UpdateCreditLimit(ctx, userId, creditLimit)
+}
+
+func (wu *WalletUseCase) FindWallets(
+	ctx context.Context, userId string) ([]WalletOutputDTO, *internal_error.InternalError) {
+	wallets, err := wu.walletRepository.FindWallets(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	walletOutputs := make([]WalletOutputDTO, 0, len(wallets))
+	for _, wallet := range wallets {
+		walletOutputs = append(walletOutputs, WalletOutputDTO{
+			UserId:      wallet.UserId,
+			Currency:    wallet.Currency,
+			Balance:     json.Number(wallet.Balance.String()),
+			CreditLimit: json.Number(wallet.CreditLimit.String()),
+			Held:        json.Number(wallet.Held.String()),
+			Available:   json.Number(wallet.Available().String()),
+			UpdatedAt:   wallet.UpdatedAt,
+		})
+	}
+
+	return walletOutputs, nil
+}
+
+func parseAmount(amountInput AmountInputDTO) (money_entity.Money, *internal_error.InternalError) {
+	currency := strings.ToUpper(strings.TrimSpace(amountInput.Currency))
+	if _, ok := money_entity.Exponent(currency); !ok {
+		return money_entity.Money{}, internal_error.NewBadRequestError("Currency is not supported")
+	}
+
+	return money_entity.Parse(amountInput.Amount.String(), currency)
+}
@@ -0,0 +1,87 @@
+package watchlist_usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/auction_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/entity/watchlist_entity"
+	"github.com/m4rcelotoledo/Auction-in-Go/internal/internal_error"
+)
+
+type WatchlistInputDTO struct {
+	AuctionId string `json:"auction_id" binding:"required,uuid"`
+}
+
+type WatchlistOutputDTO struct {
+	UserId    string    `json:"user_id"`
+	AuctionId string    `json:"auction_id"`
+	Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+}
+
+type WatchlistUseCase struct {
+	watchlistRepository watchlist_entity.WatchlistRepositoryInterface
+	auctionRepository   auction_entity.AuctionRepositoryInterface
+}
+
+func NewWatchlistUseCase(
+	watchlistRepository watchlist_entity.WatchlistRepositoryInterface,
+	auctionRepository auction_entity.AuctionRepositoryInterface) WatchlistUseCaseInterface {
+	return &WatchlistUseCase{
+		watchlistRepository: watchlistRepository,
+		auctionRepository:   auctionRepository,
+	}
+}
+
+type WatchlistUseCaseInterface interface {
+	AddWatchedAuction(
+		ctx context.Context,
+		userId string,
+		watchlistInput WatchlistInputDTO) *internal_error.InternalError
+
+	RemoveWatchedAuction(
+		ctx context.Context, userId, auctionId string) *internal_error.InternalError
+
+	FindWatchedAuctions(
+		ctx context.Context, userId string) ([]WatchlistOutputDTO, *internal_error.InternalError)
+}
+
+func (wu *WatchlistUseCase) AddWatchedAuction(
+	ctx context.Context,
+	userId string,
+	watchlistInput WatchlistInputDTO) *internal_error.InternalError {
+	item, err := watchlist_entity.CreateWatchlistItem(userId, watchlistInput.AuctionId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := wu.auctionRepository.FindAuctionById(ctx, item.AuctionId); err != nil {
+		return err
+	}
+
+	return wu.watchlistRepository.AddWatchedAuction(ctx, item)
+}
+
+func (wu *WatchlistUseCase) RemoveWatchedAuction(
+	ctx context.Context, userId, auctionId string) *internal_error.InternalError {
+	return wu.watchlistRepository.RemoveWatchedAuction(ctx, userId, auctionId)
+}
+
+func (wu *WatchlistUseCase) FindWatchedAuctions(
+	ctx context.Context, userId string) ([]WatchlistOutputDTO, *internal_error.InternalError) {
+	items, err := wu.watchlistRepository.FindWatchedAuctionsByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var watchlistOutputs []WatchlistOutputDTO
+	for _, item := range items {
+		watchlistOutputs = append(watchlistOutputs, WatchlistOutputDTO{
+			UserId:    item.UserId,
+			AuctionId: item.AuctionId,
+			Timestamp: item.Timestamp,
+		})
+	}
+
+	return watchlistOutputs, nil
+}